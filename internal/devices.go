@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+	"golang.org/x/crypto/ssh"
+)
+
+// Device is a Fabric Engine switch managed by the provider.
+type Device struct {
+	// Name is the key of the device in the provider's devices map, or empty
+	// for the default target described by the top-level attributes.
+	Name string
+	// SSH runs CLI commands on the device. Resources must use it instead of
+	// dialing the device themselves.
+	SSH *transport.Client
+	// System reads and changes the settings that have a structured
+	// representation, over the transport selected in the provider block.
+	System transport.System
+}
+
+// transportSettings holds the top-level attributes selecting the transport
+// behind Device.System.
+type transportSettings struct {
+	kind        string
+	restPort    int32
+	caBundle    []byte
+	insecure    bool
+	netconfPort int32
+}
+
+// ExtrmFabricEngineDeviceModel describes an entry of the provider's devices
+// map. Unset attributes inherit the value of the top-level attribute, except
+// host_key which only pins the key of the top-level host.
+type ExtrmFabricEngineDeviceModel struct {
+	Host                 types.String `tfsdk:"host"`
+	Port                 types.Int32  `tfsdk:"port"`
+	Username             types.String `tfsdk:"username"`
+	Password             types.String `tfsdk:"password"`
+	PrivateKey           types.String `tfsdk:"private_key"`
+	PrivateKeyFile       types.String `tfsdk:"private_key_file"`
+	PrivateKeyPassphrase types.String `tfsdk:"private_key_passphrase"`
+	HostKey              types.String `tfsdk:"host_key"`
+}
+
+// deviceNameRe restricts device names to characters that are safe to use in
+// resource IDs.
+var deviceNameRe = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// devicesAttribute returns the schema of the provider's devices map.
+func devicesAttribute() schema.MapNestedAttribute {
+	return schema.MapNestedAttribute{
+		MarkdownDescription: "Additional Fabric Engine devices, keyed by name. Resources select one with their `device` attribute. " +
+			"Unset attributes inherit the top-level value, except `host_key`: the top-level one only applies to the top-level `host`.",
+		Optional: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"host": schema.StringAttribute{
+					MarkdownDescription: "Host of Fabric Engine device.",
+					Required:            true,
+				},
+				"port": schema.Int32Attribute{
+					MarkdownDescription: "Port for the Fabric Engine device. Defaults to the top-level port, or 22.",
+					Optional:            true,
+				},
+				"username": schema.StringAttribute{
+					MarkdownDescription: "Username for the Fabric Engine device.",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"password": schema.StringAttribute{
+					MarkdownDescription: "Password for the Fabric Engine device.",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"private_key": schema.StringAttribute{
+					MarkdownDescription: "PEM encoded private key used for public key authentication.",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"private_key_file": schema.StringAttribute{
+					MarkdownDescription: "Path of a PEM encoded private key used for public key authentication.",
+					Optional:            true,
+				},
+				"private_key_passphrase": schema.StringAttribute{
+					MarkdownDescription: "Passphrase protecting `private_key` or `private_key_file`.",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"host_key": schema.StringAttribute{
+					MarkdownDescription: "Expected host key of the device, either as a public key in authorized_keys format or as a `SHA256:` fingerprint. " +
+						"Without it, the device is only verified with the top-level `known_hosts_file` and `trust_on_first_use` settings.",
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+// deviceAttribute returns the schema of the device attribute shared by every
+// resource. Moving a resource to another device replaces it.
+func deviceAttribute() resourceschema.StringAttribute {
+	return resourceschema.StringAttribute{
+		MarkdownDescription: "Name of the device, from the provider's `devices` map, to manage. " +
+			"Defaults to the device configured by the top-level provider attributes.",
+		Optional: true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+}
+
+// dataSourceDeviceAttribute returns the schema of the device attribute shared
+// by every data source.
+func dataSourceDeviceAttribute() datasourceschema.StringAttribute {
+	return datasourceschema.StringAttribute{
+		MarkdownDescription: "Name of the device, from the provider's `devices` map, to read. " +
+			"Defaults to the device configured by the top-level provider attributes.",
+		Optional: true,
+	}
+}
+
+// Device returns the device called name, or the default target when name is
+// empty.
+func (c *ExtrmFabricEngineClient) Device(name string) (*Device, error) {
+	if d, ok := c.devices[name]; ok {
+		return d, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no default device is configured; set host in the provider block or select one of %s with the device attribute", c.deviceNames())
+	}
+	return nil, fmt.Errorf("device %q is not defined in the provider's devices map; known devices are %s", name, c.deviceNames())
+}
+
+// requireDevice looks up the device called name and reports an error on the
+// device attribute when it does not exist.
+func (c *ExtrmFabricEngineClient) requireDevice(name types.String, diags *diag.Diagnostics) *Device {
+	d, err := c.Device(name.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("device"), "Unknown device", err.Error())
+		return nil
+	}
+	return d
+}
+
+func (c *ExtrmFabricEngineClient) deviceNames() string {
+	var names []string
+	for name := range c.devices {
+		if name != "" {
+			names = append(names, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// addDevice creates the transport clients for a device and registers it.
+func (c *ExtrmFabricEngineClient) addDevice(name string, cfg transport.Config, ts transportSettings) error {
+	d := &Device{Name: name, SSH: transport.NewClient(cfg)}
+	d.System = d.SSH
+
+	switch ts.kind {
+	case "netconf":
+		netconfCfg := cfg
+		netconfCfg.Port = ts.netconfPort
+		if netconfCfg.Port == 0 {
+			netconfCfg.Port = transport.DefaultNETCONFPort
+		}
+		netconf := transport.NewNETCONFClient(netconfCfg)
+		trackClient(netconf)
+		d.System = netconf
+	case "rest":
+		if cfg.Password == "" {
+			return errors.New("the rest transport requires a password")
+		}
+		rest, err := transport.NewRESTClient(transport.RESTConfig{
+			Host:     cfg.Host,
+			Port:     ts.restPort,
+			Username: cfg.Username,
+			Password: cfg.Password,
+			CABundle: ts.caBundle,
+			Insecure: ts.insecure,
+		})
+		if err != nil {
+			return err
+		}
+		trackClient(rest)
+		d.System = rest
+	}
+
+	trackClient(d.SSH)
+	c.devices[name] = d
+	return nil
+}
+
+// deviceConfig merges the settings of the device called name over base, the
+// configuration derived from the top-level attributes.
+func deviceConfig(
+	name string, device ExtrmFabricEngineDeviceModel, base transport.Config,
+	hostKey transport.HostKeyConfig, diags *diag.Diagnostics) (transport.Config, bool) {
+
+	attr := path.Root("devices").AtMapKey(name)
+	if !deviceNameRe.MatchString(name) || name == importid.Default {
+		diags.AddAttributeError(attr, "Invalid device name",
+			fmt.Sprintf("Device names may only contain letters, digits, '_', '.' and '-', and %q is reserved for the default device", importid.Default))
+		return base, false
+	}
+
+	if device.Host.IsUnknown() || device.Port.IsUnknown() || device.Username.IsUnknown() ||
+		device.Password.IsUnknown() || device.PrivateKey.IsUnknown() || device.PrivateKeyFile.IsUnknown() ||
+		device.PrivateKeyPassphrase.IsUnknown() || device.HostKey.IsUnknown() {
+		diags.AddAttributeWarning(attr, "Unable to create client", "Cannot use unknown values in device settings")
+		return base, false
+	}
+
+	cfg := base
+	cfg.Host = device.Host.ValueString()
+	if cfg.Host == "" {
+		diags.AddAttributeError(attr.AtName("host"), "Unable to find host", "Host cannot be an empty string")
+		return cfg, false
+	}
+
+	if !device.Port.IsNull() {
+		cfg.Port = device.Port.ValueInt32()
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 22
+	}
+
+	if !device.Username.IsNull() {
+		cfg.Username = device.Username.ValueString()
+	}
+	if cfg.Username == "" {
+		diags.AddAttributeError(attr.AtName("username"), "Unable to find username", "Username cannot be an empty string")
+		return cfg, false
+	}
+
+	if !device.Password.IsNull() {
+		cfg.Password = device.Password.ValueString()
+	}
+
+	if !device.PrivateKey.IsNull() || !device.PrivateKeyFile.IsNull() {
+		signer, err := loadPrivateKey(
+			device.PrivateKey.ValueString(), device.PrivateKeyFile.ValueString(), device.PrivateKeyPassphrase.ValueString())
+		if err != nil {
+			diags.AddAttributeError(attr, "Invalid private key", err.Error())
+			return cfg, false
+		}
+		cfg.Signers = []ssh.Signer{signer}
+	}
+
+	if !cfg.HasAuth() {
+		diags.AddAttributeError(attr, "Unable to find credentials",
+			"One of password, private_key, private_key_file or ssh_agent must be set for the device or the provider")
+		return cfg, false
+	}
+
+	// The top-level host_key pins the key of the top-level host only, every
+	// other device has its own key.
+	hostKey.PinnedKey = device.HostKey.ValueString()
+	cb, err := hostKey.Callback()
+	if err != nil {
+		diags.AddAttributeError(attr.AtName("host_key"), "Invalid host key verification settings", err.Error())
+		return cfg, false
+	}
+	if hostKey.Insecure() {
+		diags.AddAttributeWarning(attr, "Host key verification disabled",
+			"Neither known_hosts_file nor the host_key of the device is set, the identity of the device will not be verified.")
+	}
+	cfg.HostKeyCallback = cb
+	return cfg, true
+}
+
+// configureDevices reads the provider's devices map and adds each device to
+// client.
+func configureDevices(
+	ctx context.Context, devices types.Map, base transport.Config, hostKey transport.HostKeyConfig,
+	ts transportSettings, client *ExtrmFabricEngineClient, diags *diag.Diagnostics) {
+
+	var models map[string]ExtrmFabricEngineDeviceModel
+	diags.Append(devices.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	for name, model := range models {
+		cfg, ok := deviceConfig(name, model, base, hostKey, diags)
+		if !ok {
+			continue
+		}
+		if err := client.addDevice(name, cfg, ts); err != nil {
+			diags.AddAttributeError(path.Root("devices").AtMapKey(name), "Unable to create client", err.Error())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+// addCommandError appends an error diagnostic for err. When the device
+// rejected a command, the detail names the command and quotes the response;
+// when its host key was rejected, the detail shows the presented fingerprint;
+// when the REST API refused a request, the detail quotes the response body;
+// NETCONF errors are reported with their error-tag and message.
+func addCommandError(diags *diag.Diagnostics, summary string, err error) {
+	var cmdErr *transport.CommandError
+	if errors.As(err, &cmdErr) {
+		diags.AddError(summary, fmt.Sprintf(
+			"The device rejected the command %q:\n\n%s", cmdErr.Command, cmdErr.Output))
+		return
+	}
+
+	var keyErr *transport.HostKeyError
+	if errors.As(err, &keyErr) {
+		diags.AddError(summary, fmt.Sprintf(
+			"The host key presented by %s does not match the expected one: %s.\n\n"+
+				"Presented key fingerprint: %s\n\n"+
+				"If the device was legitimately re-keyed, update known_hosts_file or host_key.",
+			keyErr.Host, keyErr.Reason, keyErr.Fingerprint))
+		return
+	}
+
+	var httpErr *transport.HTTPError
+	if errors.As(err, &httpErr) {
+		diags.AddError(summary, fmt.Sprintf(
+			"The device rejected the request %s %s with status %d:\n\n%s",
+			httpErr.Method, httpErr.Path, httpErr.StatusCode, httpErr.Body))
+		return
+	}
+
+	var rpcErr *transport.RPCError
+	if errors.As(err, &rpcErr) {
+		diags.AddError(summary, fmt.Sprintf(
+			"The device returned a NETCONF error, no change was committed:\n\n%s", rpcErr))
+		return
+	}
+
+	diags.AddError(summary, err.Error())
+}
@@ -0,0 +1,403 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineAutoSenseResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineAutoSenseResource{}
+
+// FabricEngineAutoSenseResource implements resource.Resource.
+type FabricEngineAutoSenseResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineAutoSenseResource returns a new instance of the resource.
+func NewFabricEngineAutoSenseResource() resource.Resource {
+	return &FabricEngineAutoSenseResource{}
+}
+
+// FabricEngineAutoSenseModel describes the resource model used in Terraform state.
+type FabricEngineAutoSenseModel struct {
+	ID             types.String `tfsdk:"id"`
+	Device         types.String `tfsdk:"device"`
+	OnboardingISID types.Int32  `tfsdk:"onboarding_i_sid"`
+	VoiceISID      types.Int32  `tfsdk:"voice_i_sid"`
+	VoiceCVID      types.Int32  `tfsdk:"voice_c_vid"`
+	Data           types.Set    `tfsdk:"data"`
+	OnDestroy      types.String `tfsdk:"on_destroy"`
+}
+
+// FabricEngineAutoSenseServiceModel describes an auto-sense data service.
+type FabricEngineAutoSenseServiceModel struct {
+	ISID types.Int32 `tfsdk:"i_sid"`
+	CVID types.Int32 `tfsdk:"c_vid"`
+}
+
+// autoSenseServiceType is the object type of an element of the data
+// attribute.
+var autoSenseServiceType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"i_sid": types.Int32Type,
+	"c_vid": types.Int32Type,
+}}
+
+func (r *FabricEngineAutoSenseResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_auto_sense"
+}
+
+func (r *FabricEngineAutoSenseResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the global I-SIDs of the auto-sense ports of a device: the onboarding I-SID, " +
+			"the voice I-SID and the data I-SIDs. Auto-sense is enabled on the ports themselves, see `auto_sense` of the port resource.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"onboarding_i_sid": schema.Int32Attribute{
+				MarkdownDescription: "I-SID unauthenticated devices are placed in (`auto-sense onboarding i-sid`). Defaults to 15999999.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(transport.DefaultOnboardingISID),
+				Validators:          []validator.Int32{int32Between(1, 15999999)},
+			},
+			"voice_i_sid": schema.Int32Attribute{
+				MarkdownDescription: "I-SID of the IP phones (`auto-sense voice i-sid`). Requires `voice_c_vid`.",
+				Optional:            true,
+				Validators:          []validator.Int32{int32Between(1, 15999999)},
+			},
+			"voice_c_vid": schema.Int32Attribute{
+				MarkdownDescription: "C-VID the voice traffic is tagged with on the auto-sense ports.",
+				Optional:            true,
+				Validators:          []validator.Int32{int32Between(1, 4094)},
+			},
+			"data": schema.SetNestedAttribute{
+				MarkdownDescription: "Data I-SIDs (`auto-sense data i-sid`). Only the I-SIDs added, removed or remapped are configured on update.",
+				Optional:            true,
+				Computed:            true,
+				Default:             setdefault.StaticValue(types.SetValueMust(autoSenseServiceType, []attr.Value{})),
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"i_sid": schema.Int32Attribute{
+							MarkdownDescription: "I-SID of the data service, from 1 to 15999999.",
+							Required:            true,
+							Validators:          []validator.Int32{int32Between(1, 15999999)},
+						},
+						"c_vid": schema.Int32Attribute{
+							MarkdownDescription: "C-VID the data traffic is tagged with on the auto-sense ports.",
+							Required:            true,
+							Validators:          []validator.Int32{int32Between(1, 4094)},
+						},
+					},
+				},
+			},
+			"on_destroy": onDestroyAttribute(),
+		},
+	}
+}
+
+// ValidateConfig checks that the voice service is complete and that the
+// services use distinct I-SIDs and C-VIDs.
+func (r *FabricEngineAutoSenseResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineAutoSenseModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.VoiceISID.IsNull() != config.VoiceCVID.IsNull() && !config.VoiceISID.IsUnknown() && !config.VoiceCVID.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(path.Root("voice_i_sid"), "Invalid attribute combination",
+			"voice_i_sid and voice_c_vid must be set together.")
+	}
+	if config.Data.IsUnknown() || config.OnboardingISID.IsUnknown() || config.VoiceISID.IsUnknown() || config.VoiceCVID.IsUnknown() {
+		return
+	}
+
+	var data []FabricEngineAutoSenseServiceModel
+	if !config.Data.IsNull() {
+		resp.Diagnostics.Append(config.Data.ElementsAs(ctx, &data, false)...)
+	}
+	type service struct {
+		attr       string
+		isid, cvid types.Int32
+	}
+	var services []service
+	if !config.VoiceISID.IsNull() {
+		services = append(services, service{"voice_i_sid", config.VoiceISID, config.VoiceCVID})
+	}
+	for _, s := range data {
+		services = append(services, service{"data", s.ISID, s.CVID})
+	}
+
+	onboarding := int32(transport.DefaultOnboardingISID)
+	if !config.OnboardingISID.IsNull() {
+		onboarding = config.OnboardingISID.ValueInt32()
+	}
+	isids := map[int32]bool{onboarding: true}
+	cvids := map[int32]bool{}
+	for _, s := range services {
+		if s.isid.IsUnknown() || s.cvid.IsUnknown() {
+			continue
+		}
+		if isids[s.isid.ValueInt32()] {
+			resp.Diagnostics.AddAttributeError(path.Root(s.attr), "Invalid attribute value",
+				fmt.Sprintf("I-SID %d is used by several auto-sense services.", s.isid.ValueInt32()))
+		}
+		if cvids[s.cvid.ValueInt32()] {
+			resp.Diagnostics.AddAttributeError(path.Root(s.attr), "Invalid attribute value",
+				fmt.Sprintf("C-VID %d is used by several auto-sense services.", s.cvid.ValueInt32()))
+		}
+		isids[s.isid.ValueInt32()], cvids[s.cvid.ValueInt32()] = true, true
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineAutoSenseResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create records the current configuration for on_destroy = "restore_original" and applies the planned one.
+func (r *FabricEngineAutoSenseResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineAutoSenseModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	current, err := device.SSH.AutoSense(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read auto-sense configuration", err)
+		return
+	}
+	resp.Diagnostics.Append(saveOriginal(ctx, resp.Private, current)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.apply(ctx, device, current, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the configuration from "show auto-sense".
+func (r *FabricEngineAutoSenseResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineAutoSenseModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	current, err := device.SSH.AutoSense(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read auto-sense configuration", err)
+		return
+	}
+	// An imported configuration has not been changed by Terraform yet, so it is the original one.
+	var original transport.AutoSense
+	if ok, diags := loadOriginal(ctx, req.Private, &original); !ok && !diags.HasError() {
+		resp.Diagnostics.Append(saveOriginal(ctx, resp.Private, current)...)
+	}
+
+	state.fromAutoSense(ctx, current, &resp.Diagnostics)
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update applies the planned configuration.
+func (r *FabricEngineAutoSenseResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineAutoSenseModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	// Diff against the device rather than the state, so services changed outside of Terraform are removed first.
+	current, err := device.SSH.AutoSense(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read auto-sense configuration", err)
+		return
+	}
+
+	r.apply(ctx, device, current, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete applies the on_destroy policy and removes the resource from state.
+func (r *FabricEngineAutoSenseResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineAutoSenseModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var target transport.AutoSense
+	switch state.OnDestroy.ValueString() {
+	case onDestroyResetToDefault:
+		target = transport.DefaultAutoSense
+	case onDestroyRestoreOriginal:
+		ok, diags := loadOriginal(ctx, req.Private, &target)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !ok {
+			resp.Diagnostics.AddWarning("Original auto-sense configuration unknown",
+				"No configuration was recorded before Terraform managed the device, the current configuration is retained.")
+			resp.State.RemoveResource(ctx)
+			return
+		}
+	default:
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	current, err := device.SSH.AutoSense(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read auto-sense configuration", err)
+		return
+	}
+	if err := device.SSH.ApplyAutoSense(ctx, *current, target); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to reset auto-sense configuration", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the configuration of the device named by the import ID, "default" for the default device.
+func (r *FabricEngineAutoSenseResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	device, err := importid.ParseSingleton(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), importid.Singleton(device))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("on_destroy"), onDestroyRetain)...)
+	if device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), device)...)
+	}
+}
+
+// apply changes the device configuration from current to the one planned in
+// m, then reads it back into m.
+func (r *FabricEngineAutoSenseResource) apply(
+	ctx context.Context, device *Device, current *transport.AutoSense, m *FabricEngineAutoSenseModel, diags *diag.Diagnostics) {
+
+	a := transport.AutoSense{OnboardingISID: m.OnboardingISID.ValueInt32()}
+	if !m.VoiceISID.IsNull() {
+		a.Voice = &transport.AutoSenseService{ISID: m.VoiceISID.ValueInt32(), CVID: m.VoiceCVID.ValueInt32()}
+	}
+	var data []FabricEngineAutoSenseServiceModel
+	diags.Append(m.Data.ElementsAs(ctx, &data, false)...)
+	if diags.HasError() {
+		return
+	}
+	for _, s := range data {
+		a.Data = append(a.Data, transport.AutoSenseService{ISID: s.ISID.ValueInt32(), CVID: s.CVID.ValueInt32()})
+	}
+	sort.Slice(a.Data, func(i, j int) bool { return a.Data[i].ISID < a.Data[j].ISID })
+
+	if err := device.SSH.ApplyAutoSense(ctx, *current, a); err != nil {
+		addCommandError(diags, "Unable to configure auto-sense", err)
+		return
+	}
+
+	applied, err := device.SSH.AutoSense(ctx)
+	if err != nil {
+		addCommandError(diags, "Unable to read auto-sense configuration", err)
+		return
+	}
+	m.fromAutoSense(ctx, applied, diags)
+}
+
+// fromAutoSense sets the attributes of m from the configuration read from
+// the device.
+func (m *FabricEngineAutoSenseModel) fromAutoSense(ctx context.Context, a *transport.AutoSense, diags *diag.Diagnostics) {
+	m.ID = types.StringValue(importid.Singleton(m.Device.ValueString()))
+	m.OnboardingISID = types.Int32Value(a.OnboardingISID)
+	m.VoiceISID = types.Int32Null()
+	m.VoiceCVID = types.Int32Null()
+	if a.Voice != nil {
+		m.VoiceISID = types.Int32Value(a.Voice.ISID)
+		m.VoiceCVID = types.Int32Value(a.Voice.CVID)
+	}
+
+	data := make([]FabricEngineAutoSenseServiceModel, len(a.Data))
+	for i, s := range a.Data {
+		data[i] = FabricEngineAutoSenseServiceModel{ISID: types.Int32Value(s.ISID), CVID: types.Int32Value(s.CVID)}
+	}
+	set, d := types.SetValueFrom(ctx, autoSenseServiceType, data)
+	diags.Append(d...)
+	m.Data = set
+}
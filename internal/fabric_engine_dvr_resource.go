@@ -0,0 +1,443 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineDVRResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineDVRResource{}
+var _ resource.ResourceWithModifyPlan = &FabricEngineDVRResource{}
+
+// FabricEngineDVRResource implements resource.Resource.
+type FabricEngineDVRResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineDVRResource returns a new instance of the resource.
+func NewFabricEngineDVRResource() resource.Resource {
+	return &FabricEngineDVRResource{}
+}
+
+// FabricEngineDVRModel describes the resource model used in Terraform state.
+type FabricEngineDVRModel struct {
+	ID                 types.String `tfsdk:"id"`
+	Device             types.String `tfsdk:"device"`
+	Role               types.String `tfsdk:"role"`
+	DomainID           types.Int32  `tfsdk:"domain_id"`
+	Injection          types.Bool   `tfsdk:"injection"`
+	InjectDefaultRoute types.Bool   `tfsdk:"inject_default_route"`
+	Interfaces         types.Set    `tfsdk:"interfaces"`
+}
+
+// FabricEngineDVRInterfaceModel describes the DVR settings of a VLAN
+// interface.
+type FabricEngineDVRInterfaceModel struct {
+	VLANID      types.Int32  `tfsdk:"vlan_id"`
+	GatewayIPv4 types.String `tfsdk:"gateway_ipv4"`
+	Enabled     types.Bool   `tfsdk:"enabled"`
+}
+
+// dvrInterfaceType is the object type of an element of the interfaces
+// attribute.
+var dvrInterfaceType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"vlan_id":      types.Int32Type,
+	"gateway_ipv4": types.StringType,
+	"enabled":      types.BoolType,
+}}
+
+func (r *FabricEngineDVRResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_dvr"
+}
+
+func (r *FabricEngineDVRResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the Distributed Virtual Routing role of a device (`dvr controller` or `dvr leaf`) " +
+			"and, on a controller, the route injection settings and the DVR gateways of its VLAN interfaces.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"role": schema.StringAttribute{
+				MarkdownDescription: "DVR role of the device, `controller` or `leaf`.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{stringOneOf(transport.DVRRoleController, transport.DVRRoleLeaf)},
+			},
+			"domain_id": schema.Int32Attribute{
+				MarkdownDescription: "DVR domain of the device, from 1 to 255.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 255)},
+			},
+			"injection": schema.BoolAttribute{
+				MarkdownDescription: "Whether the controller injects the routes of its domain into the fabric (`dvr injection`). " +
+					"Controller only, defaults to true.",
+				Optional: true,
+				Computed: true,
+			},
+			"inject_default_route": schema.BoolAttribute{
+				MarkdownDescription: "Whether the controller injects a default route into its domain (`dvr inject-default-route`). " +
+					"Controller only, defaults to true.",
+				Optional: true,
+				Computed: true,
+			},
+			"interfaces": schema.SetNestedAttribute{
+				MarkdownDescription: "DVR settings of the VLAN interfaces of a controller. Each VLAN needs an IPv4 address, " +
+					"and an I-SID to enable DVR. VLAN interfaces not listed have no DVR gateway. Controller only.",
+				Optional: true,
+				Computed: true,
+				Default:  setdefault.StaticValue(types.SetValueMust(dvrInterfaceType, []attr.Value{})),
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"vlan_id": schema.Int32Attribute{
+							MarkdownDescription: "VLAN of the IP interface, from 2 to 4059.",
+							Required:            true,
+							Validators:          []validator.Int32{int32Between(2, 4059)},
+						},
+						"gateway_ipv4": schema.StringAttribute{
+							MarkdownDescription: "DVR gateway of the VLAN (`dvr gw-ipv4`), an address of the subnet of the interface " +
+								"shared by every controller of the domain.",
+							Required: true,
+						},
+						"enabled": schema.BoolAttribute{
+							MarkdownDescription: "Whether DVR is enabled on the interface (`dvr enable`). Defaults to true.",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(true),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that the controller settings are not set on a leaf
+// and that every VLAN has a single, valid gateway.
+func (r *FabricEngineDVRResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineDVRModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var ifs []FabricEngineDVRInterfaceModel
+	if !config.Interfaces.IsNull() && !config.Interfaces.IsUnknown() {
+		resp.Diagnostics.Append(config.Interfaces.ElementsAs(ctx, &ifs, false)...)
+	}
+
+	if config.Role.ValueString() == transport.DVRRoleLeaf {
+		for _, a := range []struct {
+			name string
+			set  bool
+		}{
+			{"injection", !config.Injection.IsNull()},
+			{"inject_default_route", !config.InjectDefaultRoute.IsNull()},
+			{"interfaces", len(ifs) > 0},
+		} {
+			if a.set {
+				resp.Diagnostics.AddAttributeError(path.Root(a.name), "Invalid attribute combination",
+					fmt.Sprintf("%s is only supported when role is %q.", a.name, transport.DVRRoleController))
+			}
+		}
+	}
+
+	vlans := map[int32]bool{}
+	for _, i := range ifs {
+		if !i.VLANID.IsUnknown() && !i.VLANID.IsNull() {
+			if vlans[i.VLANID.ValueInt32()] {
+				resp.Diagnostics.AddAttributeError(path.Root("interfaces"), "Invalid attribute value",
+					fmt.Sprintf("VLAN %d has several DVR gateways.", i.VLANID.ValueInt32()))
+			}
+			vlans[i.VLANID.ValueInt32()] = true
+		}
+		if i.GatewayIPv4.IsUnknown() || i.GatewayIPv4.IsNull() {
+			continue
+		}
+		s := i.GatewayIPv4.ValueString()
+		addr, err := netip.ParseAddr(s)
+		switch {
+		case err != nil || !addr.Is4():
+			resp.Diagnostics.AddAttributeError(path.Root("interfaces"), "Invalid address",
+				fmt.Sprintf("%q is not an IPv4 address such as \"10.0.10.1\"", s))
+		case addr.String() != s:
+			resp.Diagnostics.AddAttributeError(path.Root("interfaces"), "Invalid address",
+				fmt.Sprintf("%q must be written %q, the way the device shows it", s, addr.String()))
+		}
+	}
+}
+
+// ModifyPlan defaults the injection settings to true on a controller and to
+// null on a leaf, which does not have them.
+func (r *FabricEngineDVRResource) ModifyPlan(
+	ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var config FabricEngineDVRModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || config.Role.IsUnknown() {
+		return
+	}
+
+	value := types.BoolNull()
+	if config.Role.ValueString() == transport.DVRRoleController {
+		value = types.BoolValue(true)
+	}
+	for name, v := range map[string]types.Bool{
+		"injection":            config.Injection,
+		"inject_default_route": config.InjectDefaultRoute,
+	} {
+		if v.IsNull() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), value)...)
+		}
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineDVRResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create sets the DVR role of the device, then its controller settings.
+func (r *FabricEngineDVRResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineDVRModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	r.apply(ctx, device, nil, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the DVR configuration from "show dvr" and
+// "show dvr interfaces".
+func (r *FabricEngineDVRResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineDVRModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// DVR was removed outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes the injection settings and the DVR settings of the VLAN
+// interfaces that changed.
+func (r *FabricEngineDVRResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineDVRModel
+	var state FabricEngineDVRModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	old := state.dvr(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, device, &old, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes DVR from the VLAN interfaces, then the DVR role of the
+// device.
+func (r *FabricEngineDVRResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineDVRModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	dvr := state.dvr(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.DeleteDVR(ctx, dvr); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete DVR", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the DVR configuration of the device named by the import ID, "default" for the default device.
+func (r *FabricEngineDVRResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	device, err := importid.ParseSingleton(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), importid.Singleton(device))...)
+	if device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), device)...)
+	}
+}
+
+// apply changes the DVR configuration from old, nil to create it, to the one
+// planned in m and reads it back into m.
+func (r *FabricEngineDVRResource) apply(
+	ctx context.Context, device *Device, old *transport.DVR, m *FabricEngineDVRModel, diags *diag.Diagnostics) {
+
+	dvr := m.dvr(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	if err := device.SSH.ApplyDVR(ctx, old, dvr); err != nil {
+		addCommandError(diags, "Unable to configure DVR", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, m, diags) {
+		diags.AddError("DVR not found", "DVR is not configured on the device after being configured.")
+	}
+}
+
+// refresh reads the DVR configuration from the device into m. It returns
+// false if the device is neither a controller nor a leaf.
+func (r *FabricEngineDVRResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineDVRModel, diags *diag.Diagnostics) bool {
+
+	dvr, err := device.SSH.DVR(ctx)
+	if err != nil {
+		addCommandError(diags, "Unable to read DVR configuration", err)
+		return true
+	}
+	if dvr == nil {
+		return false
+	}
+
+	m.ID = types.StringValue(importid.Singleton(m.Device.ValueString()))
+	m.Role = types.StringValue(dvr.Role)
+	m.DomainID = types.Int32Value(dvr.DomainID)
+	m.Injection = types.BoolNull()
+	m.InjectDefaultRoute = types.BoolNull()
+	if dvr.Role == transport.DVRRoleController {
+		m.Injection = types.BoolValue(dvr.Injection)
+		m.InjectDefaultRoute = types.BoolValue(dvr.InjectDefaultRoute)
+	}
+
+	ifs := make([]FabricEngineDVRInterfaceModel, len(dvr.Interfaces))
+	for n, i := range dvr.Interfaces {
+		ifs[n] = FabricEngineDVRInterfaceModel{
+			VLANID:      types.Int32Value(i.VLAN),
+			GatewayIPv4: types.StringValue(i.GatewayIPv4),
+			Enabled:     types.BoolValue(i.Enabled),
+		}
+	}
+	set, d := types.SetValueFrom(ctx, dvrInterfaceType, ifs)
+	diags.Append(d...)
+	m.Interfaces = set
+	return true
+}
+
+// dvr returns the DVR configuration described by m.
+func (m *FabricEngineDVRModel) dvr(ctx context.Context, diags *diag.Diagnostics) transport.DVR {
+	d := transport.DVR{
+		Role:               m.Role.ValueString(),
+		DomainID:           m.DomainID.ValueInt32(),
+		Injection:          m.Injection.ValueBool(),
+		InjectDefaultRoute: m.InjectDefaultRoute.ValueBool(),
+	}
+	var ifs []FabricEngineDVRInterfaceModel
+	diags.Append(m.Interfaces.ElementsAs(ctx, &ifs, false)...)
+	for _, i := range ifs {
+		d.Interfaces = append(d.Interfaces, transport.DVRInterface{
+			VLAN:        i.VLANID.ValueInt32(),
+			GatewayIPv4: i.GatewayIPv4.ValueString(),
+			Enabled:     i.Enabled.ValueBool(),
+		})
+	}
+	sort.Slice(d.Interfaces, func(i, j int) bool { return d.Interfaces[i].VLAN < d.Interfaces[j].VLAN })
+	return d
+}
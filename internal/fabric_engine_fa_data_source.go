@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+)
+
+var _ datasource.DataSource = &FabricEngineFADataSource{}
+
+// FabricEngineFADataSource implements datasource.DataSource.
+type FabricEngineFADataSource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineFADataSource returns a new instance of the data source.
+func NewFabricEngineFADataSource() datasource.DataSource {
+	return &FabricEngineFADataSource{}
+}
+
+// FabricEngineFADataSourceModel describes the data source model used in Terraform state.
+type FabricEngineFADataSourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Device      types.String `tfsdk:"device"`
+	Elements    types.List   `tfsdk:"elements"`
+	Assignments types.List   `tfsdk:"assignments"`
+}
+
+// FabricEngineFAElementModel describes a discovered Fabric Attach element.
+type FabricEngineFAElementModel struct {
+	Port     types.String `tfsdk:"port"`
+	Type     types.String `tfsdk:"type"`
+	VLANID   types.Int32  `tfsdk:"vlan_id"`
+	SystemID types.String `tfsdk:"system_id"`
+	State    types.String `tfsdk:"state"`
+}
+
+// FabricEngineFAAssignmentModel describes an I-SID/VLAN assignment requested
+// by a Fabric Attach element.
+type FabricEngineFAAssignmentModel struct {
+	Port   types.String `tfsdk:"port"`
+	ISID   types.Int32  `tfsdk:"i_sid"`
+	VLANID types.Int32  `tfsdk:"vlan_id"`
+	State  types.String `tfsdk:"state"`
+}
+
+var (
+	// faElementType is the object type of an element of the elements
+	// attribute.
+	faElementType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"port":      types.StringType,
+		"type":      types.StringType,
+		"vlan_id":   types.Int32Type,
+		"system_id": types.StringType,
+		"state":     types.StringType,
+	}}
+	// faAssignmentType is the object type of an element of the assignments
+	// attribute.
+	faAssignmentType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"port":    types.StringType,
+		"i_sid":   types.Int32Type,
+		"vlan_id": types.Int32Type,
+		"state":   types.StringType,
+	}}
+)
+
+func (d *FabricEngineFADataSource) Metadata(
+	ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_fa"
+}
+
+func (d *FabricEngineFADataSource) Schema(
+	ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the Fabric Attach clients and proxies, such as access points, connected to a device " +
+			"and the I-SID/VLAN assignments they requested, from `show fa elements` and `show fa assignment`.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": dataSourceDeviceAttribute(),
+			"elements": schema.ListNestedAttribute{
+				MarkdownDescription: "Discovered Fabric Attach elements, by port.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"port": schema.StringAttribute{
+							MarkdownDescription: "Port the element is connected to, e.g. `1/5`.",
+							Computed:            true,
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Element type advertised by the element, e.g. `proxy` or `clientWapType1`.",
+							Computed:            true,
+						},
+						"vlan_id": schema.Int32Attribute{
+							MarkdownDescription: "Management VLAN of the element.",
+							Computed:            true,
+						},
+						"system_id": schema.StringAttribute{
+							MarkdownDescription: "Fabric Attach system ID of the element.",
+							Computed:            true,
+						},
+						"state": schema.StringAttribute{
+							MarkdownDescription: "`trusted` if the element passed message authentication, else `untrusted`.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"assignments": schema.ListNestedAttribute{
+				MarkdownDescription: "I-SID/VLAN assignments requested by the elements, by port.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"port": schema.StringAttribute{
+							MarkdownDescription: "Port of the element that requested the assignment.",
+							Computed:            true,
+						},
+						"i_sid": schema.Int32Attribute{
+							MarkdownDescription: "Requested I-SID.",
+							Computed:            true,
+						},
+						"vlan_id": schema.Int32Attribute{
+							MarkdownDescription: "VLAN of the element mapped to the I-SID.",
+							Computed:            true,
+						},
+						"state": schema.StringAttribute{
+							MarkdownDescription: "State of the assignment: `active`, `pending` or `rejected`.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the data source.
+func (d *FabricEngineFADataSource) Configure(
+	ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	d.client = c
+}
+
+// Read lists the elements and assignments from "show fa elements" and "show fa assignment".
+func (d *FabricEngineFADataSource) Read(
+	ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+
+	var config FabricEngineFADataSourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := d.client.requireDevice(config.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	discovery, err := device.SSH.FADiscovery(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read Fabric Attach elements", err)
+		return
+	}
+
+	elements := make([]FabricEngineFAElementModel, len(discovery.Elements))
+	for i, e := range discovery.Elements {
+		elements[i] = FabricEngineFAElementModel{
+			Port:     types.StringValue(e.Port),
+			Type:     types.StringValue(e.Type),
+			VLANID:   types.Int32Value(e.VLAN),
+			SystemID: types.StringValue(e.SystemID),
+			State:    types.StringValue(e.State),
+		}
+	}
+	assignments := make([]FabricEngineFAAssignmentModel, len(discovery.Assignments))
+	for i, a := range discovery.Assignments {
+		assignments[i] = FabricEngineFAAssignmentModel{
+			Port:   types.StringValue(a.Port),
+			ISID:   types.Int32Value(a.ISID),
+			VLANID: types.Int32Value(a.VLAN),
+			State:  types.StringValue(a.State),
+		}
+	}
+
+	config.ID = types.StringValue(importid.Singleton(config.Device.ValueString()))
+	config.Elements, diags = types.ListValueFrom(ctx, faElementType, elements)
+	resp.Diagnostics.Append(diags...)
+	config.Assignments, diags = types.ListValueFrom(ctx, faAssignmentType, assignments)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, config)
+	resp.Diagnostics.Append(diags...)
+}
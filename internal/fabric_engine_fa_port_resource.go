@@ -0,0 +1,314 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineFAPortResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineFAPortResource{}
+
+// FabricEngineFAPortResource implements resource.Resource.
+type FabricEngineFAPortResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineFAPortResource returns a new instance of the resource.
+func NewFabricEngineFAPortResource() resource.Resource {
+	return &FabricEngineFAPortResource{}
+}
+
+// FabricEngineFAPortModel describes the resource model used in Terraform state.
+type FabricEngineFAPortModel struct {
+	ID                    types.String `tfsdk:"id"`
+	Device                types.String `tfsdk:"device"`
+	Port                  types.String `tfsdk:"port"`
+	MLTID                 types.Int32  `tfsdk:"mlt_id"`
+	MessageAuthentication types.Bool   `tfsdk:"message_authentication"`
+	AuthenticationKey     types.String `tfsdk:"authentication_key"`
+}
+
+func (r *FabricEngineFAPortResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_fa_port"
+}
+
+func (r *FabricEngineFAPortResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Enables Fabric Attach on a port or MLT with `fa enable`, with its message authentication settings. " +
+			"Auto-sense ports manage Fabric Attach themselves and cannot be configured with this resource.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"port": schema.StringAttribute{
+				MarkdownDescription: "Port Fabric Attach is enabled on, e.g. `1/1`. Exactly one of `port` and `mlt_id` must be set.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{portValidator{}},
+			},
+			"mlt_id": schema.Int32Attribute{
+				MarkdownDescription: "ID of the MLT Fabric Attach is enabled on. The MLT must exist.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 512)},
+			},
+			"message_authentication": schema.BoolAttribute{
+				MarkdownDescription: "Whether the Fabric Attach messages are authenticated (`fa message-authentication`). Defaults to true.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"authentication_key": schema.StringAttribute{
+				MarkdownDescription: "Message authentication key (`fa authentication-key`). Unset for the default key of the device. " +
+					"The device does not show it, so changes made outside of Terraform are not detected.",
+				Optional:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that exactly one interface is selected.
+func (r *FabricEngineFAPortResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineFAPortModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Port.IsNull() == config.MLTID.IsNull() && !config.Port.IsUnknown() && !config.MLTID.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(path.Root("port"), "Invalid attribute combination",
+			"Exactly one of port and mlt_id must be set.")
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineFAPortResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create enables Fabric Attach on the interface.
+func (r *FabricEngineFAPortResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineFAPortModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.ApplyFAInterface(ctx, nil, plan.faInterface()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to enable Fabric Attach", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("Fabric Attach interface not found",
+			fmt.Sprintf("Fabric Attach is not enabled on %s after being enabled.", plan.iface()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the interface from "show fa interface".
+func (r *FabricEngineFAPortResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineFAPortModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// Fabric Attach was disabled on the interface outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes the message authentication settings of the interface.
+func (r *FabricEngineFAPortResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineFAPortModel
+	var state FabricEngineFAPortModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old := state.faInterface()
+	if err := device.SSH.ApplyFAInterface(ctx, &old, plan.faInterface()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update Fabric Attach interface", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("Fabric Attach interface not found",
+			fmt.Sprintf("Fabric Attach is not enabled on %s after being enabled.", plan.iface()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete disables Fabric Attach on the interface.
+func (r *FabricEngineFAPortResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineFAPortModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.DeleteFAInterface(ctx, state.iface()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to disable Fabric Attach", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the interface named by the import ID,
+// "[<device>:]fa_port/port/<port>" or "[<device>:]fa_port/mlt/<mlt_id>".
+// The authentication key cannot be read from the device and must be set again.
+func (r *FabricEngineFAPortResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "fa_port")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	kind, key, _ := strings.Cut(id.Key, "/")
+	switch {
+	case kind == "port" && transport.ValidPort(key):
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("port"), key)...)
+	case kind == "mlt":
+		mltID, err := strconv.ParseInt(key, 10, 32)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("%q is not an MLT ID", key))
+			return
+		}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("mlt_id"), int32(mltID))...)
+	default:
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("invalid ID %q: expected fa_port/port/<port> or fa_port/mlt/<mlt_id>", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the Fabric Attach configuration of the interface into m. It
+// returns false if Fabric Attach is not enabled on the interface. The
+// authentication key is kept from m, since the device does not show it.
+func (r *FabricEngineFAPortResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineFAPortModel, diags *diag.Diagnostics) bool {
+
+	iface := m.iface()
+	f, err := device.SSH.FAInterface(ctx, iface)
+	if err != nil {
+		addCommandError(diags, "Unable to read Fabric Attach interface", err)
+		return true
+	}
+	if f == nil {
+		return false
+	}
+
+	key := "port/" + iface.Port
+	if iface.Port == "" {
+		key = "mlt/" + strconv.Itoa(int(iface.MLT))
+	}
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "fa_port", key))
+	m.MessageAuthentication = types.BoolValue(f.MessageAuthentication)
+	return true
+}
+
+// iface returns the interface selected by m.
+func (m *FabricEngineFAPortModel) iface() transport.Interface {
+	return transport.Interface{Port: m.Port.ValueString(), MLT: m.MLTID.ValueInt32()}
+}
+
+// faInterface returns the Fabric Attach configuration described by m.
+func (m *FabricEngineFAPortModel) faInterface() transport.FAInterface {
+	return transport.FAInterface{
+		Interface:             m.iface(),
+		MessageAuthentication: m.MessageAuthentication.ValueBool(),
+		AuthKey:               m.AuthenticationKey.ValueString(),
+	}
+}
@@ -1,19 +1,17 @@
 package provider
 
 import (
-	"bufio"
 	"context"
-	"fmt"
-	"io"
-	"regexp"
-	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"golang.org/x/crypto/ssh"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
 )
 
+var _ resource.ResourceWithImportState = &FabricEngineHostnameResource{}
+
 // FabricEngineHostnameResource implements resource.Resource.
 type FabricEngineHostnameResource struct {
 	client *ExtrmFabricEngineClient
@@ -26,8 +24,10 @@ func NewFabricEngineHostnameResource() resource.Resource {
 
 // FabricEngineHostnameModel describes the resource model used in Terraform state.
 type FabricEngineHostnameModel struct {
-	ID       types.String `tfsdk:"id"`
-	Hostname types.String `tfsdk:"hostname"`
+	ID        types.String `tfsdk:"id"`
+	Device    types.String `tfsdk:"device"`
+	Hostname  types.String `tfsdk:"hostname"`
+	OnDestroy types.String `tfsdk:"on_destroy"`
 }
 
 func (r *FabricEngineHostnameResource) Metadata(
@@ -41,13 +41,15 @@ func (r *FabricEngineHostnameResource) Schema(
 
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
-			"id":       schema.StringAttribute{Computed: true},
-			"hostname": schema.StringAttribute{Required: true},
+			"id":         schema.StringAttribute{Computed: true},
+			"device":     deviceAttribute(),
+			"hostname":   schema.StringAttribute{Required: true},
+			"on_destroy": onDestroyAttribute(),
 		},
 	}
 }
 
-// Configure retrieves the provider data (SSH client parameters) and assigns it to the resource.
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
 func (r *FabricEngineHostnameResource) Configure(
 	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 
@@ -73,127 +75,34 @@ func (r *FabricEngineHostnameResource) Create(
 		return
 	}
 
-	// SSH client configuration
-	cfg := &ssh.ClientConfig{
-		User:            r.client.Username,
-		Auth:            []ssh.AuthMethod{ssh.Password(r.client.Password)},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	address := fmt.Sprintf("%s:%d", r.client.Host, r.client.Port)
-	client, err := ssh.Dial("tcp", address, cfg)
-	if err != nil {
-		resp.Diagnostics.AddError("SSH connection error", err.Error())
-		return
-	}
-	defer client.Close()
-
-	// Start an interactive shell
-	session, err := client.NewSession()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot create SSH session", err.Error())
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
 		return
 	}
-	defer session.Close()
 
-	stdin, err := session.StdinPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdin pipe", err.Error())
-		return
-	}
-	stdout, err := session.StdoutPipe()
+	// Remember the hostname the device had before Terraform managed it, for on_destroy = "restore_original".
+	original, err := device.System.Hostname(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdout pipe", err.Error())
+		addCommandError(&resp.Diagnostics, "Unable to read hostname", err)
 		return
 	}
-	stderr, err := session.StderrPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stderr pipe", err.Error())
-		return
-	}
-
-	if err := session.Shell(); err != nil {
-		resp.Diagnostics.AddError("Failed to start remote shell", err.Error())
+	resp.Diagnostics.Append(saveOriginal(ctx, resp.Private, original)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Helper to send commands and collect output
-	send := func(cmd string) error {
-		_, err := fmt.Fprintf(stdin, "%s\n", cmd)
-		return err
-	}
-	var output string
-	go func() {
-		scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-		for scanner.Scan() {
-			line := scanner.Text()
-			output += line + "\n"
-		}
-	}()
-
-	// Sequence of commands with error handling
-	if err := send("enable"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to send 'enable': %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	if err := send("configure terminal"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to send 'configure terminal': %s\noutput:\n%s", err, output),
-		)
+	if err := device.System.SetHostname(ctx, plan.Hostname.ValueString()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to set hostname", err)
 		return
 	}
-	if err := send(fmt.Sprintf("sys name %s", plan.Hostname.ValueString())); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to set hostname: %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	// Exit configuration mode
-	if err := send("exit"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to exit configuration mode: %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	// Save the configuration:contentReference[oaicite:0]{index=0}
-	if err := send("save config"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to save configuration: %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	// Exit the CLI session
-	if err := send("exit"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to exit session: %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	// Wait for the session to end; ignore the "exited without exit status" error
-	if err := session.Wait(); err != nil {
-		if !strings.Contains(err.Error(), "exited without exit status") {
-			resp.Diagnostics.AddError(
-				"SSH command sequence failed",
-				fmt.Sprintf("error: %s\noutput:\n%s", err, output),
-			)
-			return
-		}
-	}
 
 	// Record the state
-	plan.ID = plan.Hostname
+	plan.ID = types.StringValue(importid.Singleton(plan.Device.ValueString()))
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 }
 
-// Read fetches the current hostname by executing "show sys-info" and parsing the SysName field.
+// Read fetches the current hostname, from "show sys-info" over SSH or from the system model over REST.
 func (r *FabricEngineHostnameResource) Read(
 	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 
@@ -204,114 +113,31 @@ func (r *FabricEngineHostnameResource) Read(
 		return
 	}
 
-	cfg := &ssh.ClientConfig{
-		User:            r.client.Username,
-		Auth:            []ssh.AuthMethod{ssh.Password(r.client.Password)},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-
-	address := fmt.Sprintf("%s:%d", r.client.Host, r.client.Port)
-	client, err := ssh.Dial("tcp", address, cfg)
-	if err != nil {
-		resp.Diagnostics.AddError("SSH connection error", err.Error())
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
 		return
 	}
-	defer client.Close()
 
-	session, err := client.NewSession()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot create SSH session", err.Error())
-		return
-	}
-	defer session.Close()
-
-	stdin, err := session.StdinPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdin pipe", err.Error())
-		return
-	}
-	stdout, err := session.StdoutPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdout pipe", err.Error())
-		return
-	}
-	stderr, err := session.StderrPipe()
+	hostname, err := device.System.Hostname(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stderr pipe", err.Error())
-		return
-	}
-
-	if err := session.Shell(); err != nil {
-		resp.Diagnostics.AddError("Failed to start remote shell", err.Error())
-		return
-	}
-
-	// Helper to send commands and collect output
-	send := func(cmd string) error {
-		_, err := fmt.Fprintf(stdin, "%s\n", cmd)
-		return err
-	}
-	var output string
-	go func() {
-		scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-		for scanner.Scan() {
-			line := scanner.Text()
-			output += line + "\n"
-		}
-	}()
-
-	// Sequence of commands with error handling
-	if err := send("enable"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to send 'enable': %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	if err := send("terminal more disable"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to send 'terminal more disable': %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	if err := send("show sys-info | include SysName"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to send 'show sys-info | include SysName': %s\noutput:\n%s", err, output),
-		)
-		return
-	}
-	if err := send("exit"); err != nil {
-		resp.Diagnostics.AddError(
-			"SSH command failed",
-			fmt.Sprintf("failed to send 'exit': %s\noutput:\n%s", err, output),
-		)
+		addCommandError(&resp.Diagnostics, "Unable to read hostname", err)
 		return
 	}
-
-	// Wait for the session to end; ignore the "exited without exit status" error
-	if err := session.Wait(); err != nil {
-		if !strings.Contains(err.Error(), "exited without exit status") {
-			resp.Diagnostics.AddError("SSH session wait error", err.Error())
-			return
-		}
+	// An imported hostname has not been changed by Terraform yet, so the current value is the original one.
+	var original string
+	if ok, diags := loadOriginal(ctx, req.Private, &original); !ok && !diags.HasError() {
+		resp.Diagnostics.Append(saveOriginal(ctx, resp.Private, hostname)...)
 	}
 
-	re := regexp.MustCompile(`SysName\s+:\s+(\S+)`)
-	matches := re.FindStringSubmatch(output)
-	if len(matches) == 2 {
-		state.Hostname = types.StringValue(matches[1])
-		state.ID = state.Hostname
-	} else {
-		resp.Diagnostics.AddWarning("Hostname not found", "Could not find SysName in output:\n"+output)
-	}
+	state.Hostname = types.StringValue(hostname)
+	state.ID = types.StringValue(importid.Singleton(state.Device.ValueString()))
 
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 }
 
-// Update changes the hostname only if the desired value differs from the current state.
+// Update changes the hostname only if the desired value differs from the current state, and always
+// saves the plan so that a new on_destroy policy is recorded.
 func (r *FabricEngineHostnameResource) Update(
 	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 
@@ -325,196 +151,65 @@ func (r *FabricEngineHostnameResource) Update(
 		return
 	}
 
-	// If the hostname is unchanged, no action is needed.
-	if plan.Hostname.ValueString() == state.Hostname.ValueString() {
-		return
-	}
-
-	// SSH client configuration
-	cfg := &ssh.ClientConfig{
-		User:            r.client.Username,
-		Auth:            []ssh.AuthMethod{ssh.Password(r.client.Password)},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	address := fmt.Sprintf("%s:%d", r.client.Host, r.client.Port)
-	client, err := ssh.Dial("tcp", address, cfg)
-	if err != nil {
-		resp.Diagnostics.AddError("SSH connection error", err.Error())
-		return
-	}
-	defer client.Close()
-
-	// Interactive shell
-	session, err := client.NewSession()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot create SSH session", err.Error())
-		return
-	}
-	defer session.Close()
-
-	stdin, err := session.StdinPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdin pipe", err.Error())
-		return
-	}
-	stdout, err := session.StdoutPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdout pipe", err.Error())
-		return
-	}
-	stderr, err := session.StderrPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stderr pipe", err.Error())
-		return
-	}
-
-	if err := session.Shell(); err != nil {
-		resp.Diagnostics.AddError("Failed to start remote shell", err.Error())
-		return
-	}
-
-	send := func(cmd string) error {
-		_, err := fmt.Fprintf(stdin, "%s\n", cmd)
-		return err
-	}
-	var output string
-	go func() {
-		scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-		for scanner.Scan() {
-			line := scanner.Text()
-			output += line + "\n"
+	// Only on_destroy may have changed, which needs no command.
+	if plan.Hostname.ValueString() != state.Hostname.ValueString() {
+		device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+		if device == nil {
+			return
 		}
-	}()
 
-	if err := send("enable"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to send 'enable': %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("configure terminal"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to send 'configure terminal': %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send(fmt.Sprintf("sys name %s", plan.Hostname.ValueString())); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to set hostname: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("exit"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to exit configuration mode: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("save config"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to save configuration: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("exit"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to exit session: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := session.Wait(); err != nil {
-		if !strings.Contains(err.Error(), "exited without exit status") {
-			resp.Diagnostics.AddError(
-				"SSH command sequence failed",
-				fmt.Sprintf("error: %s\noutput:\n%s", err, output),
-			)
+		if err := device.System.SetHostname(ctx, plan.Hostname.ValueString()); err != nil {
+			addCommandError(&resp.Diagnostics, "Unable to set hostname", err)
 			return
 		}
 	}
 
-	plan.ID = plan.Hostname
+	plan.ID = types.StringValue(importid.Singleton(plan.Device.ValueString()))
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 }
 
-// Delete resets the hostname to a default model (TEST-FABRIC-ENGINE) and removes the resource from state.
+// Delete applies the on_destroy policy and removes the resource from state.
 func (r *FabricEngineHostnameResource) Delete(
 	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 
-	// SSH client configuration
-	cfg := &ssh.ClientConfig{
-		User:            r.client.Username,
-		Auth:            []ssh.AuthMethod{ssh.Password(r.client.Password)},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	address := fmt.Sprintf("%s:%d", r.client.Host, r.client.Port)
-	client, err := ssh.Dial("tcp", address, cfg)
-	if err != nil {
-		resp.Diagnostics.AddError("SSH connection error", err.Error())
-		return
-	}
-	defer client.Close()
-
-	// Interactive shell
-	session, err := client.NewSession()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot create SSH session", err.Error())
-		return
-	}
-	defer session.Close()
-
-	stdin, err := session.StdinPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdin pipe", err.Error())
-		return
-	}
-	stdout, err := session.StdoutPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stdout pipe", err.Error())
-		return
-	}
-	stderr, err := session.StderrPipe()
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get stderr pipe", err.Error())
+	var state FabricEngineHostnameModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if err := session.Shell(); err != nil {
-		resp.Diagnostics.AddError("Failed to start remote shell", err.Error())
-		return
-	}
+	switch state.OnDestroy.ValueString() {
+	case onDestroyResetToDefault:
+		device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+		if device == nil {
+			return
+		}
+		if err := device.System.ResetHostname(ctx); err != nil {
+			addCommandError(&resp.Diagnostics, "Unable to reset hostname", err)
+			return
+		}
 
-	send := func(cmd string) error {
-		_, err := fmt.Fprintf(stdin, "%s\n", cmd)
-		return err
-	}
-	var output string
-	go func() {
-		scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-		for scanner.Scan() {
-			line := scanner.Text()
-			output += line + "\n"
+	case onDestroyRestoreOriginal:
+		var original string
+		ok, diags := loadOriginal(ctx, req.Private, &original)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !ok {
+			resp.Diagnostics.AddWarning("Original hostname unknown",
+				"No hostname was recorded before Terraform managed the device, the current hostname is retained.")
+			break
 		}
-	}()
 
-	if err := send("enable"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to send 'enable': %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("configure terminal"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to send 'configure terminal': %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("sys name TEST-FABRIC-ENGINE"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to reset hostname: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("exit"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to exit configuration mode: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("save config"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to save configuration: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := send("exit"); err != nil {
-		resp.Diagnostics.AddError("SSH command failed", fmt.Sprintf("failed to exit session: %s\noutput:\n%s", err, output))
-		return
-	}
-	if err := session.Wait(); err != nil {
-		if !strings.Contains(err.Error(), "exited without exit status") {
-			resp.Diagnostics.AddError(
-				"SSH command sequence failed",
-				fmt.Sprintf("error: %s\noutput:\n%s", err, output),
-			)
+		device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+		if device == nil {
+			return
+		}
+		if err := device.System.SetHostname(ctx, original); err != nil {
+			addCommandError(&resp.Diagnostics, "Unable to restore hostname", err)
 			return
 		}
 	}
@@ -522,3 +217,20 @@ func (r *FabricEngineHostnameResource) Delete(
 	// Remove the resource from Terraform state.
 	resp.State.RemoveResource(ctx)
 }
+
+// ImportState adopts the hostname of the device named by the import ID, "default" for the default device.
+func (r *FabricEngineHostnameResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	device, err := importid.ParseSingleton(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), importid.Singleton(device))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("on_destroy"), onDestroyRetain)...)
+	if device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), device)...)
+	}
+}
@@ -0,0 +1,460 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineIPInterfaceResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineIPInterfaceResource{}
+
+// FabricEngineIPInterfaceResource implements resource.Resource.
+type FabricEngineIPInterfaceResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineIPInterfaceResource returns a new instance of the resource.
+func NewFabricEngineIPInterfaceResource() resource.Resource {
+	return &FabricEngineIPInterfaceResource{}
+}
+
+// FabricEngineIPInterfaceModel describes the resource model used in Terraform state.
+type FabricEngineIPInterfaceModel struct {
+	ID                     types.String `tfsdk:"id"`
+	Device                 types.String `tfsdk:"device"`
+	VLANID                 types.Int32  `tfsdk:"vlan_id"`
+	Port                   types.String `tfsdk:"port"`
+	LoopbackID             types.Int32  `tfsdk:"loopback_id"`
+	BrouterVLANID          types.Int32  `tfsdk:"brouter_vlan_id"`
+	VRF                    types.String `tfsdk:"vrf"`
+	IPv4Address            types.String `tfsdk:"ipv4_address"`
+	IPv4SecondaryAddresses types.Set    `tfsdk:"ipv4_secondary_addresses"`
+	IPv6Addresses          types.Set    `tfsdk:"ipv6_addresses"`
+	SPBMulticast           types.Bool   `tfsdk:"spb_multicast"`
+}
+
+func (r *FabricEngineIPInterfaceResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_ip_interface"
+}
+
+func (r *FabricEngineIPInterfaceResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	emptySet := setdefault.StaticValue(types.SetValueMust(types.StringType, []attr.Value{}))
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Configures the IP addresses of a VLAN (`interface vlan`), a brouter port " +
+			"(`brouter port`) or a loopback (CLIP, `interface loopback`), in the global router or in a VRF.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"vlan_id": schema.Int32Attribute{
+				MarkdownDescription: "ID of the VLAN. Exactly one of `vlan_id`, `port` and `loopback_id` must be set.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 4059)},
+			},
+			"port": schema.StringAttribute{
+				MarkdownDescription: "Port of a brouter interface, e.g. `1/5`.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{portValidator{}},
+			},
+			"loopback_id": schema.Int32Attribute{
+				MarkdownDescription: "ID of the loopback (CLIP) interface, from 1 to 256.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 256)},
+			},
+			"brouter_vlan_id": schema.Int32Attribute{
+				MarkdownDescription: "Unused VLAN ID the brouter port is bound to, required with `port`.",
+				Optional:            true,
+				Validators:          []validator.Int32{int32Between(2, 4059)},
+			},
+			"vrf": schema.StringAttribute{
+				MarkdownDescription: "VRF of the interface. Unset for the global router. The VRF must exist.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{stringMatches(vrfNameRe, "up to 16 letters, digits, _, . or -")},
+			},
+			"ipv4_address": schema.StringAttribute{
+				MarkdownDescription: "Primary IPv4 address with its prefix length, e.g. `10.0.10.1/24`. " +
+					"Loopback addresses must be `/32`. Required with `port`.",
+				Optional:   true,
+				Validators: []validator.String{addressValidator{}},
+			},
+			"ipv4_secondary_addresses": schema.SetAttribute{
+				MarkdownDescription: "Secondary IPv4 addresses of a VLAN, with their prefix length.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Computed:            true,
+				Default:             emptySet,
+				Validators:          []validator.Set{addressValidator{}},
+			},
+			"ipv6_addresses": schema.SetAttribute{
+				MarkdownDescription: "IPv6 addresses with their prefix length, e.g. `2001:db8::1/64`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Computed:            true,
+				Default:             emptySet,
+				Validators:          []validator.Set{addressValidator{ipv6: true}},
+			},
+			"spb_multicast": schema.BoolAttribute{
+				MarkdownDescription: "Whether `ip spb-multicast enable` is set on a loopback. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that exactly one interface is selected and that the
+// addresses suit its kind.
+func (r *FabricEngineIPInterfaceResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineIPInterfaceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.VLANID.IsUnknown() || config.Port.IsUnknown() || config.LoopbackID.IsUnknown() {
+		return
+	}
+
+	set := 0
+	for _, null := range []bool{config.VLANID.IsNull(), config.Port.IsNull(), config.LoopbackID.IsNull()} {
+		if !null {
+			set++
+		}
+	}
+	if set != 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("vlan_id"), "Invalid attribute combination",
+			"Exactly one of vlan_id, port and loopback_id must be set.")
+		return
+	}
+
+	if config.Port.IsNull() != config.BrouterVLANID.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("brouter_vlan_id"), "Invalid attribute combination",
+			"brouter_vlan_id must be set with port, and only with port.")
+	}
+	if !config.Port.IsNull() && config.IPv4Address.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("ipv4_address"), "Missing attribute",
+			"ipv4_address is required by a brouter port.")
+	}
+	if config.VLANID.IsNull() && len(config.IPv4SecondaryAddresses.Elements()) > 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("ipv4_secondary_addresses"), "Invalid attribute combination",
+			"Secondary addresses can only be configured on a VLAN.")
+	}
+	if config.LoopbackID.IsNull() && config.SPBMulticast.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("spb_multicast"), "Invalid attribute combination",
+			"spb_multicast can only be enabled on a loopback.")
+	}
+
+	if config.IPv4Address.IsUnknown() {
+		return
+	}
+	if config.IPv4Address.IsNull() {
+		if len(config.IPv4SecondaryAddresses.Elements()) > 0 || config.SPBMulticast.ValueBool() {
+			resp.Diagnostics.AddAttributeError(path.Root("ipv4_address"), "Missing attribute",
+				"ipv4_address is required by secondary addresses and spb_multicast.")
+		}
+		if !config.IPv6Addresses.IsUnknown() && len(config.IPv6Addresses.Elements()) == 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("ipv4_address"), "Missing attribute",
+				"At least one of ipv4_address and ipv6_addresses must be set.")
+		}
+		return
+	}
+	if prefix, err := netip.ParsePrefix(config.IPv4Address.ValueString()); err == nil &&
+		!config.LoopbackID.IsNull() && prefix.Bits() != 32 {
+		resp.Diagnostics.AddAttributeError(path.Root("ipv4_address"), "Invalid attribute value",
+			"The address of a loopback interface must have a /32 prefix length.")
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineIPInterfaceResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create attaches the interface to its VRF and configures its addresses.
+func (r *FabricEngineIPInterfaceResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineIPInterfaceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	iface := plan.ipInterface(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.ApplyIPInterface(ctx, nil, iface); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to configure IP interface", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("IP interface not found",
+			fmt.Sprintf("%s has no address on the device after being configured.", iface))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the interface from "show ip interface vrf" and "show ipv6 address interface vrf".
+func (r *FabricEngineIPInterfaceResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineIPInterfaceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The addresses were removed outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update adds and removes the addresses that changed.
+func (r *FabricEngineIPInterfaceResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineIPInterfaceModel
+	var state FabricEngineIPInterfaceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old := state.ipInterface(ctx, &resp.Diagnostics)
+	iface := plan.ipInterface(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.ApplyIPInterface(ctx, &old, iface); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update IP interface", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("IP interface not found",
+			fmt.Sprintf("%s has no address on the device after being configured.", iface))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the addresses of the interface and moves it back to the global router.
+func (r *FabricEngineIPInterfaceResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineIPInterfaceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	iface := state.ipInterface(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.DeleteIPInterface(ctx, iface); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete IP interface", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the interface named by the import ID,
+// "[<device>:]ip_interface/<vrf>/vlan/<id>", ".../port/<port>" or
+// ".../loopback/<id>", where <vrf> is GlobalRouter for the global router.
+func (r *FabricEngineIPInterfaceResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "ip_interface")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	invalid := fmt.Sprintf("invalid ID %q: expected ip_interface/<vrf>/vlan/<vlan_id>, "+
+		"ip_interface/<vrf>/port/<port> or ip_interface/<vrf>/loopback/<loopback_id>", req.ID)
+	parts := strings.SplitN(id.Key, "/", 3)
+	if len(parts) != 3 || !vrfNameRe.MatchString(parts[0]) {
+		resp.Diagnostics.AddError("Invalid import ID", invalid)
+		return
+	}
+	vrf, kind, key := parts[0], parts[1], parts[2]
+	switch kind {
+	case "port":
+		if !transport.ValidPort(key) {
+			resp.Diagnostics.AddError("Invalid import ID", invalid)
+			return
+		}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("port"), key)...)
+	case "vlan", "loopback":
+		n, err := strconv.ParseInt(key, 10, 32)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid import ID", invalid)
+			return
+		}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(kind+"_id"), int32(n))...)
+	default:
+		resp.Diagnostics.AddError("Invalid import ID", invalid)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	if vrf != transport.GlobalRouter {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vrf"), vrf)...)
+	}
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the addresses of the interface into m. It returns false if
+// the interface has no address in its VRF.
+func (r *FabricEngineIPInterfaceResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineIPInterfaceModel, diags *diag.Diagnostics) bool {
+
+	i, err := device.SSH.IPInterface(ctx, m.ipInterface(ctx, diags))
+	if err != nil {
+		addCommandError(diags, "Unable to read IP interface", err)
+		return true
+	}
+	if i == nil {
+		return false
+	}
+
+	vrf := i.VRF
+	if vrf == "" {
+		vrf = transport.GlobalRouter
+	}
+	var key string
+	switch {
+	case i.Port != "":
+		key = "port/" + i.Port
+		m.BrouterVLANID = types.Int32Value(i.BrouterVLAN)
+	case i.Loopback != 0:
+		key = fmt.Sprintf("loopback/%d", i.Loopback)
+	default:
+		key = fmt.Sprintf("vlan/%d", i.VLAN)
+	}
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "ip_interface", vrf+"/"+key))
+	m.IPv4Address = types.StringNull()
+	if i.Address != "" {
+		m.IPv4Address = types.StringValue(i.Address)
+	}
+	m.IPv4SecondaryAddresses = stringsToSet(ctx, i.Secondary, diags)
+	m.IPv6Addresses = stringsToSet(ctx, i.IPv6, diags)
+	m.SPBMulticast = types.BoolValue(i.SPBMulticast)
+	return true
+}
+
+// ipInterface returns the interface described by m.
+func (m *FabricEngineIPInterfaceModel) ipInterface(ctx context.Context, diags *diag.Diagnostics) transport.IPInterface {
+	i := transport.IPInterface{
+		VLAN:         m.VLANID.ValueInt32(),
+		Port:         m.Port.ValueString(),
+		Loopback:     m.LoopbackID.ValueInt32(),
+		VRF:          m.VRF.ValueString(),
+		Address:      m.IPv4Address.ValueString(),
+		BrouterVLAN:  m.BrouterVLANID.ValueInt32(),
+		SPBMulticast: m.SPBMulticast.ValueBool(),
+	}
+	if !m.IPv4SecondaryAddresses.IsNull() && !m.IPv4SecondaryAddresses.IsUnknown() {
+		diags.Append(m.IPv4SecondaryAddresses.ElementsAs(ctx, &i.Secondary, false)...)
+	}
+	if !m.IPv6Addresses.IsNull() && !m.IPv6Addresses.IsUnknown() {
+		diags.Append(m.IPv6Addresses.ElementsAs(ctx, &i.IPv6, false)...)
+	}
+	return i
+}
+
+// stringsToSet returns values as a set attribute value, which is empty rather
+// than null when there are no values.
+func stringsToSet(ctx context.Context, values []string, diags *diag.Diagnostics) types.Set {
+	if values == nil {
+		values = []string{}
+	}
+	set, d := types.SetValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return set
+}
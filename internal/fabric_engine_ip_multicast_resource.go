@@ -0,0 +1,429 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineIPMulticastResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineIPMulticastResource{}
+
+// FabricEngineIPMulticastResource implements resource.Resource.
+type FabricEngineIPMulticastResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineIPMulticastResource returns a new instance of the resource.
+func NewFabricEngineIPMulticastResource() resource.Resource {
+	return &FabricEngineIPMulticastResource{}
+}
+
+// FabricEngineIPMulticastModel describes the resource model used in Terraform state.
+type FabricEngineIPMulticastModel struct {
+	ID         types.String `tfsdk:"id"`
+	Device     types.String `tfsdk:"device"`
+	VRF        types.String `tfsdk:"vrf"`
+	Interfaces types.Set    `tfsdk:"interfaces"`
+}
+
+// FabricEngineIGMPInterfaceModel describes the multicast settings of a VLAN
+// interface.
+type FabricEngineIGMPInterfaceModel struct {
+	VLANID                  types.Int32  `tfsdk:"vlan_id"`
+	SPBMulticast            types.Bool   `tfsdk:"spb_multicast"`
+	IGMPSnooping            types.Bool   `tfsdk:"igmp_snooping"`
+	IGMPSnoopQuerier        types.Bool   `tfsdk:"igmp_snoop_querier"`
+	IGMPSnoopQuerierAddress types.String `tfsdk:"igmp_snoop_querier_address"`
+	IGMPVersion             types.Int32  `tfsdk:"igmp_version"`
+}
+
+// igmpInterfaceType is the object type of an element of the interfaces
+// attribute.
+var igmpInterfaceType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"vlan_id":                    types.Int32Type,
+	"spb_multicast":              types.BoolType,
+	"igmp_snooping":              types.BoolType,
+	"igmp_snoop_querier":         types.BoolType,
+	"igmp_snoop_querier_address": types.StringType,
+	"igmp_version":               types.Int32Type,
+}}
+
+func (r *FabricEngineIPMulticastResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_ip_multicast"
+}
+
+func (r *FabricEngineIPMulticastResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages IP multicast over Fabric Connect in the global router (`spbm 1 multicast enable`) " +
+			"or in a VRF (`mvpn enable`), and the multicast settings of its VLAN interfaces. " +
+			"SPBM instance 1 must exist, and a VRF needs multicast enabled in the global router first.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"vrf": schema.StringAttribute{
+				MarkdownDescription: "VRF to enable multicast in. Unset for the global router. The VRF must exist.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{stringMatches(vrfNameRe, "up to 16 letters, digits, _, . or -")},
+			},
+			"interfaces": schema.SetNestedAttribute{
+				MarkdownDescription: "Multicast settings of the VLAN interfaces of the VRF. Each VLAN needs an IPv4 address. " +
+					"VLAN interfaces not listed have the default settings. Only the interfaces that changed are configured on update.",
+				Optional: true,
+				Computed: true,
+				Default:  setdefault.StaticValue(types.SetValueMust(igmpInterfaceType, []attr.Value{})),
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"vlan_id": schema.Int32Attribute{
+							MarkdownDescription: "VLAN of the IP interface, from 1 to 4059.",
+							Required:            true,
+							Validators:          []validator.Int32{int32Between(1, 4059)},
+						},
+						"spb_multicast": schema.BoolAttribute{
+							MarkdownDescription: "Whether multicast is routed over the fabric (`ip spb-multicast enable`). Defaults to true.",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(true),
+						},
+						"igmp_snooping": schema.BoolAttribute{
+							MarkdownDescription: "Whether IGMP snooping is enabled (`ip igmp snooping`). Defaults to false.",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+						"igmp_snoop_querier": schema.BoolAttribute{
+							MarkdownDescription: "Whether the switch is the IGMP querier of the VLAN (`ip igmp snoop-querier`). Defaults to false.",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+						"igmp_snoop_querier_address": schema.StringAttribute{
+							MarkdownDescription: "Source IPv4 address of the IGMP queries (`ip igmp snoop-querier-addr`).",
+							Optional:            true,
+						},
+						"igmp_version": schema.Int32Attribute{
+							MarkdownDescription: "IGMP version, from 1 to 3. Defaults to 2.",
+							Optional:            true,
+							Computed:            true,
+							Default:             int32default.StaticInt32(transport.DefaultIGMPVersion),
+							Validators:          []validator.Int32{int32Between(1, 3)},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig checks the querier addresses, and that every VLAN is listed
+// once with at least one setting the device shows.
+func (r *FabricEngineIPMulticastResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineIPMulticastModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || config.Interfaces.IsNull() || config.Interfaces.IsUnknown() {
+		return
+	}
+
+	var ifs []FabricEngineIGMPInterfaceModel
+	resp.Diagnostics.Append(config.Interfaces.ElementsAs(ctx, &ifs, false)...)
+	vlans := map[int32]bool{}
+	for _, i := range ifs {
+		if i.VLANID.IsUnknown() || i.VLANID.IsNull() {
+			continue
+		}
+		vlan := i.VLANID.ValueInt32()
+		if vlans[vlan] {
+			resp.Diagnostics.AddAttributeError(path.Root("interfaces"), "Invalid attribute value",
+				fmt.Sprintf("VLAN %d is listed several times.", vlan))
+		}
+		vlans[vlan] = true
+
+		if i.defaultsOnly() {
+			resp.Diagnostics.AddAttributeError(path.Root("interfaces"), "Invalid attribute value",
+				fmt.Sprintf("VLAN %d has only default multicast settings, remove it from interfaces.", vlan))
+		}
+
+		if i.IGMPSnoopQuerierAddress.IsUnknown() || i.IGMPSnoopQuerierAddress.IsNull() {
+			continue
+		}
+		s := i.IGMPSnoopQuerierAddress.ValueString()
+		addr, err := netip.ParseAddr(s)
+		switch {
+		case err != nil || !addr.Is4():
+			resp.Diagnostics.AddAttributeError(path.Root("interfaces"), "Invalid address",
+				fmt.Sprintf("%q is not an IPv4 address such as \"10.0.10.254\"", s))
+		case addr.String() != s:
+			resp.Diagnostics.AddAttributeError(path.Root("interfaces"), "Invalid address",
+				fmt.Sprintf("%q must be written %q, the way the device shows it", s, addr.String()))
+		}
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineIPMulticastResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create enables multicast in the VRF, then configures its VLAN interfaces.
+func (r *FabricEngineIPMulticastResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineIPMulticastModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	r.apply(ctx, device, nil, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the multicast configuration from "show isis spbm multicast"
+// or "show ip vrf mvpn", and "show ip igmp interface".
+func (r *FabricEngineIPMulticastResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineIPMulticastModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// Multicast was disabled outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes the multicast settings of the VLAN interfaces that changed.
+func (r *FabricEngineIPMulticastResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineIPMulticastModel
+	var state FabricEngineIPMulticastModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	old := state.ipMulticast(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, device, &old, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete restores the default multicast settings of the VLAN interfaces, then
+// disables multicast in the VRF.
+func (r *FabricEngineIPMulticastResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineIPMulticastModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	m := state.ipMulticast(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.DeleteIPMulticast(ctx, m); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to disable IP multicast", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the multicast configuration named by the import ID,
+// "[<device>:]ip_multicast/<vrf>", where <vrf> is GlobalRouter for the global
+// router, e.g. "ip_multicast/GlobalRouter".
+func (r *FabricEngineIPMulticastResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "ip_multicast")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	if !vrfNameRe.MatchString(id.Key) {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("invalid ID %q: expected ip_multicast/<vrf>, e.g. ip_multicast/GlobalRouter", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	if id.Key != transport.GlobalRouter {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vrf"), id.Key)...)
+	}
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// apply changes the multicast configuration from old, nil to enable it, to
+// the one planned in m and reads it back into m.
+func (r *FabricEngineIPMulticastResource) apply(
+	ctx context.Context, device *Device, old *transport.IPMulticast, m *FabricEngineIPMulticastModel, diags *diag.Diagnostics) {
+
+	multicast := m.ipMulticast(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	if err := device.SSH.ApplyIPMulticast(ctx, old, multicast); err != nil {
+		addCommandError(diags, "Unable to configure IP multicast", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, m, diags) {
+		diags.AddError("IP multicast not enabled", "IP multicast is not enabled on the device after being configured.")
+	}
+}
+
+// refresh reads the multicast configuration of the VRF into m. It returns
+// false if multicast is not enabled in the VRF.
+func (r *FabricEngineIPMulticastResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineIPMulticastModel, diags *diag.Diagnostics) bool {
+
+	multicast, err := device.SSH.IPMulticast(ctx, m.VRF.ValueString())
+	if err != nil {
+		addCommandError(diags, "Unable to read IP multicast configuration", err)
+		return true
+	}
+	if multicast == nil {
+		return false
+	}
+
+	vrf := multicast.VRF
+	if vrf == "" {
+		vrf = transport.GlobalRouter
+	}
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "ip_multicast", vrf))
+
+	ifs := make([]FabricEngineIGMPInterfaceModel, len(multicast.Interfaces))
+	for n, i := range multicast.Interfaces {
+		ifs[n] = FabricEngineIGMPInterfaceModel{
+			VLANID:                  types.Int32Value(i.VLAN),
+			SPBMulticast:            types.BoolValue(i.SPBMulticast),
+			IGMPSnooping:            types.BoolValue(i.Snooping),
+			IGMPSnoopQuerier:        types.BoolValue(i.SnoopQuerier),
+			IGMPSnoopQuerierAddress: types.StringNull(),
+			IGMPVersion:             types.Int32Value(i.Version),
+		}
+		if i.SnoopQuerierAddress != "" {
+			ifs[n].IGMPSnoopQuerierAddress = types.StringValue(i.SnoopQuerierAddress)
+		}
+	}
+	set, d := types.SetValueFrom(ctx, igmpInterfaceType, ifs)
+	diags.Append(d...)
+	m.Interfaces = set
+	return true
+}
+
+// defaultsOnly reports whether the configuration of the interface sets
+// nothing but the defaults, which the device does not show. A null setting
+// takes its default, and only spb_multicast defaults to enabled.
+func (i FabricEngineIGMPInterfaceModel) defaultsOnly() bool {
+	for _, v := range []attr.Value{i.SPBMulticast, i.IGMPSnooping, i.IGMPSnoopQuerier, i.IGMPSnoopQuerierAddress, i.IGMPVersion} {
+		if v.IsUnknown() {
+			return false
+		}
+	}
+	return !i.SPBMulticast.IsNull() && !i.SPBMulticast.ValueBool() &&
+		!i.IGMPSnooping.ValueBool() && !i.IGMPSnoopQuerier.ValueBool() && i.IGMPSnoopQuerierAddress.IsNull() &&
+		(i.IGMPVersion.IsNull() || i.IGMPVersion.ValueInt32() == transport.DefaultIGMPVersion)
+}
+
+// ipMulticast returns the multicast configuration described by m.
+func (m *FabricEngineIPMulticastModel) ipMulticast(ctx context.Context, diags *diag.Diagnostics) transport.IPMulticast {
+	multicast := transport.IPMulticast{VRF: m.VRF.ValueString()}
+	var ifs []FabricEngineIGMPInterfaceModel
+	diags.Append(m.Interfaces.ElementsAs(ctx, &ifs, false)...)
+	for _, i := range ifs {
+		multicast.Interfaces = append(multicast.Interfaces, transport.IGMPInterface{
+			VLAN:                i.VLANID.ValueInt32(),
+			SPBMulticast:        i.SPBMulticast.ValueBool(),
+			Snooping:            i.IGMPSnooping.ValueBool(),
+			SnoopQuerier:        i.IGMPSnoopQuerier.ValueBool(),
+			SnoopQuerierAddress: i.IGMPSnoopQuerierAddress.ValueString(),
+			Version:             i.IGMPVersion.ValueInt32(),
+		})
+	}
+	sort.Slice(multicast.Interfaces, func(i, j int) bool { return multicast.Interfaces[i].VLAN < multicast.Interfaces[j].VLAN })
+	return multicast
+}
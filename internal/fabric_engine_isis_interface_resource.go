@@ -0,0 +1,366 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineISISInterfaceResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineISISInterfaceResource{}
+
+// FabricEngineISISInterfaceResource implements resource.Resource.
+type FabricEngineISISInterfaceResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineISISInterfaceResource returns a new instance of the resource.
+func NewFabricEngineISISInterfaceResource() resource.Resource {
+	return &FabricEngineISISInterfaceResource{}
+}
+
+// FabricEngineISISInterfaceModel describes the resource model used in Terraform state.
+type FabricEngineISISInterfaceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Device         types.String `tfsdk:"device"`
+	Port           types.String `tfsdk:"port"`
+	MLTID          types.Int32  `tfsdk:"mlt_id"`
+	Metric         types.Int32  `tfsdk:"metric"`
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	HelloAuthType  types.String `tfsdk:"hello_auth_type"`
+	HelloAuthKey   types.String `tfsdk:"hello_auth_key"`
+	HelloAuthKeyID types.Int32  `tfsdk:"hello_auth_key_id"`
+}
+
+func (r *FabricEngineISISInterfaceResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_isis_interface"
+}
+
+func (r *FabricEngineISISInterfaceResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Configures IS-IS on an NNI port or MLT: `isis`, `isis spbm 1` and `isis enable`, " +
+			"with an optional SPBM L1 metric and hello authentication. SPBM instance 1 must exist, see the SPBM resource.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"port": schema.StringAttribute{
+				MarkdownDescription: "Port of the NNI link, e.g. `1/1`. Exactly one of `port` and `mlt_id` must be set.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{portValidator{}},
+			},
+			"mlt_id": schema.Int32Attribute{
+				MarkdownDescription: "ID of the MLT of the NNI trunk. The MLT must exist.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 512)},
+			},
+			"metric": schema.Int32Attribute{
+				MarkdownDescription: "SPBM L1 metric of the link, from 1 to 16777215. Defaults to 10.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(transport.DefaultISISMetric),
+				Validators:          []validator.Int32{int32Between(1, 16777215)},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether IS-IS is enabled on the interface with `isis enable`. Defaults to true.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"hello_auth_type": schema.StringAttribute{
+				MarkdownDescription: "Hello authentication type: `simple`, `hmac-md5` or `hmac-sha-256`.",
+				Optional:            true,
+				Validators: []validator.String{stringOneOf(
+					transport.HelloAuthSimple, transport.HelloAuthHMACMD5, transport.HelloAuthHMACSHA256)},
+			},
+			"hello_auth_key": schema.StringAttribute{
+				MarkdownDescription: "Hello authentication key. The device does not show it, so changes made outside of Terraform are not detected.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"hello_auth_key_id": schema.Int32Attribute{
+				MarkdownDescription: "Hello authentication key ID, from 1 to 255. Required by `hmac-sha-256`.",
+				Optional:            true,
+				Validators:          []validator.Int32{int32Between(1, 255)},
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that exactly one interface is selected and that the hello authentication is complete.
+func (r *FabricEngineISISInterfaceResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineISISInterfaceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Port.IsNull() == config.MLTID.IsNull() && !config.Port.IsUnknown() && !config.MLTID.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(path.Root("port"), "Invalid attribute combination",
+			"Exactly one of port and mlt_id must be set.")
+	}
+
+	if config.HelloAuthType.IsUnknown() {
+		return
+	}
+	switch {
+	case config.HelloAuthType.IsNull():
+		if !config.HelloAuthKey.IsNull() || !config.HelloAuthKeyID.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("hello_auth_type"), "Invalid attribute combination",
+				"hello_auth_key and hello_auth_key_id require hello_auth_type.")
+		}
+	case config.HelloAuthKey.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("hello_auth_key"), "Missing attribute",
+			"hello_auth_key is required when hello_auth_type is set.")
+	case config.HelloAuthType.ValueString() == transport.HelloAuthHMACSHA256 && config.HelloAuthKeyID.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("hello_auth_key_id"), "Missing attribute",
+			"hello_auth_key_id is required by hmac-sha-256 authentication.")
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineISISInterfaceResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create configures IS-IS SPBM instance 1 on the interface.
+func (r *FabricEngineISISInterfaceResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineISISInterfaceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.ApplyISISInterface(ctx, nil, plan.isisInterface()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to configure IS-IS interface", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("IS-IS interface not found",
+			fmt.Sprintf("IS-IS is not configured on %s after being configured.", plan.iface()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the interface from "show isis interface" and "show isis int-auth".
+func (r *FabricEngineISISInterfaceResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineISISInterfaceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// IS-IS was removed from the interface outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes the metric, the hello authentication and the admin state of the interface.
+func (r *FabricEngineISISInterfaceResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineISISInterfaceModel
+	var state FabricEngineISISInterfaceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old := state.isisInterface()
+	if err := device.SSH.ApplyISISInterface(ctx, &old, plan.isisInterface()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update IS-IS interface", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("IS-IS interface not found",
+			fmt.Sprintf("IS-IS is not configured on %s after being configured.", plan.iface()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes IS-IS from the interface.
+func (r *FabricEngineISISInterfaceResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineISISInterfaceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.DeleteISISInterface(ctx, state.iface()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to remove IS-IS interface", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the interface named by the import ID,
+// "[<device>:]isis_interface/port/<port>" or "[<device>:]isis_interface/mlt/<mlt_id>".
+// The hello authentication key cannot be read from the device and must be set again.
+func (r *FabricEngineISISInterfaceResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "isis_interface")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	kind, key, _ := strings.Cut(id.Key, "/")
+	switch {
+	case kind == "port" && transport.ValidPort(key):
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("port"), key)...)
+	case kind == "mlt":
+		mltID, err := strconv.ParseInt(key, 10, 32)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("%q is not an MLT ID", key))
+			return
+		}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("mlt_id"), int32(mltID))...)
+	default:
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("invalid ID %q: expected isis_interface/port/<port> or isis_interface/mlt/<mlt_id>", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the IS-IS configuration of the interface into m. It returns
+// false if IS-IS SPBM is not configured on the interface. The hello
+// authentication key is kept from m, since the device does not show it.
+func (r *FabricEngineISISInterfaceResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineISISInterfaceModel, diags *diag.Diagnostics) bool {
+
+	iface := m.iface()
+	i, err := device.SSH.ISISInterface(ctx, iface)
+	if err != nil {
+		addCommandError(diags, "Unable to read IS-IS interface", err)
+		return true
+	}
+	if i == nil {
+		return false
+	}
+
+	key := "port/" + iface.Port
+	if iface.Port == "" {
+		key = "mlt/" + strconv.Itoa(int(iface.MLT))
+	}
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "isis_interface", key))
+	m.Metric = types.Int32Value(i.Metric)
+	m.Enabled = types.BoolValue(i.Enabled)
+	m.HelloAuthType = types.StringNull()
+	m.HelloAuthKeyID = types.Int32Null()
+	if i.AuthType == "" {
+		m.HelloAuthKey = types.StringNull()
+	} else {
+		m.HelloAuthType = types.StringValue(i.AuthType)
+	}
+	if i.AuthKeyID != 0 {
+		m.HelloAuthKeyID = types.Int32Value(i.AuthKeyID)
+	}
+	return true
+}
+
+// iface returns the interface selected by m.
+func (m *FabricEngineISISInterfaceModel) iface() transport.Interface {
+	return transport.Interface{Port: m.Port.ValueString(), MLT: m.MLTID.ValueInt32()}
+}
+
+// isisInterface returns the IS-IS configuration described by m.
+func (m *FabricEngineISISInterfaceModel) isisInterface() transport.ISISInterface {
+	return transport.ISISInterface{
+		Interface: m.iface(),
+		Metric:    m.Metric.ValueInt32(),
+		Enabled:   m.Enabled.ValueBool(),
+		AuthType:  m.HelloAuthType.ValueString(),
+		AuthKey:   m.HelloAuthKey.ValueString(),
+		AuthKeyID: m.HelloAuthKeyID.ValueInt32(),
+	}
+}
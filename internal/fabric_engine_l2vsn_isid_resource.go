@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineL2VSNISIDResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineL2VSNISIDResource{}
+
+// FabricEngineL2VSNISIDResource implements resource.Resource.
+type FabricEngineL2VSNISIDResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineL2VSNISIDResource returns a new instance of the resource.
+func NewFabricEngineL2VSNISIDResource() resource.Resource {
+	return &FabricEngineL2VSNISIDResource{}
+}
+
+// FabricEngineL2VSNISIDModel describes the resource model used in Terraform state.
+type FabricEngineL2VSNISIDModel struct {
+	ID        types.String `tfsdk:"id"`
+	Device    types.String `tfsdk:"device"`
+	ISID      types.Int32  `tfsdk:"i_sid"`
+	Name      types.String `tfsdk:"name"`
+	Endpoints types.Set    `tfsdk:"endpoints"`
+}
+
+// FabricEngineEndpointModel describes a Flex UNI endpoint.
+type FabricEngineEndpointModel struct {
+	Port  types.String `tfsdk:"port"`
+	MLTID types.Int32  `tfsdk:"mlt_id"`
+	CVID  types.Int32  `tfsdk:"c_vid"`
+}
+
+// endpointType is the object type of an element of the endpoints attribute.
+var endpointType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"port":   types.StringType,
+	"mlt_id": types.Int32Type,
+	"c_vid":  types.Int32Type,
+}}
+
+func (r *FabricEngineL2VSNISIDResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_l2vsn_isid"
+}
+
+func (r *FabricEngineL2VSNISIDResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages an ELAN I-SID (`i-sid <n> elan`) and its Flex UNI endpoints. " +
+			"Flex UNI must be enabled on the endpoint ports and MLTs.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"i_sid": schema.Int32Attribute{
+				MarkdownDescription: "I-SID of the service, from 1 to 15999999.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 15999999)},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the I-SID. Defaults to the name assigned by the device, `ISID-<i_sid>`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"endpoints": schema.SetNestedAttribute{
+				MarkdownDescription: "Flex UNI endpoints of the service. Only the endpoints added or removed are configured on update.",
+				Optional:            true,
+				Computed:            true,
+				Default:             setdefault.StaticValue(types.SetValueMust(endpointType, []attr.Value{})),
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"port": schema.StringAttribute{
+							MarkdownDescription: "Port of the endpoint, e.g. `1/1`. Exactly one of `port` and `mlt_id` must be set.",
+							Optional:            true,
+							Validators:          []validator.String{portValidator{}},
+						},
+						"mlt_id": schema.Int32Attribute{
+							MarkdownDescription: "MLT of the endpoint.",
+							Optional:            true,
+							Validators:          []validator.Int32{int32Between(1, 512)},
+						},
+						"c_vid": schema.Int32Attribute{
+							MarkdownDescription: "C-VID of the traffic mapped to the service (`c-vid`). " +
+								"Unset for the untagged traffic of the interface (`untagged-traffic`).",
+							Optional:   true,
+							Validators: []validator.Int32{int32Between(1, 4094)},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that every endpoint is on exactly one port or MLT.
+func (r *FabricEngineL2VSNISIDResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineL2VSNISIDModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || config.Endpoints.IsNull() || config.Endpoints.IsUnknown() {
+		return
+	}
+
+	var endpoints []FabricEngineEndpointModel
+	resp.Diagnostics.Append(config.Endpoints.ElementsAs(ctx, &endpoints, false)...)
+	for _, e := range endpoints {
+		if e.Port.IsUnknown() || e.MLTID.IsUnknown() {
+			continue
+		}
+		if e.Port.IsNull() == e.MLTID.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("endpoints"), "Invalid attribute combination",
+				"Exactly one of port and mlt_id must be set in each endpoint.")
+			return
+		}
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineL2VSNISIDResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create creates the I-SID with its endpoints and name.
+func (r *FabricEngineL2VSNISIDResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineL2VSNISIDModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	vsn := plan.l2vsn(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.CreateL2VSN(ctx, vsn); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to create I-SID", err)
+		return
+	}
+
+	// Read back the attributes assigned by the device, such as the default name.
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("I-SID not found",
+			fmt.Sprintf("I-SID %d does not exist on the device after being configured.", plan.ISID.ValueInt32()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the I-SID from "show i-sid" and "show i-sid elan".
+func (r *FabricEngineL2VSNISIDResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineL2VSNISIDModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The I-SID was deleted outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update renames the I-SID and adds or removes the endpoints that changed.
+func (r *FabricEngineL2VSNISIDResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineL2VSNISIDModel
+	var state FabricEngineL2VSNISIDModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old := state.l2vsn(ctx, &resp.Diagnostics)
+	vsn := plan.l2vsn(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.UpdateL2VSN(ctx, old, vsn); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update I-SID", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("I-SID not found",
+			fmt.Sprintf("I-SID %d does not exist on the device after being configured.", plan.ISID.ValueInt32()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the I-SID, which also removes its endpoints.
+func (r *FabricEngineL2VSNISIDResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineL2VSNISIDModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.DeleteL2VSN(ctx, state.ISID.ValueInt32()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete I-SID", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the I-SID named by the import ID, "[<device>:]l2vsn/<i_sid>".
+func (r *FabricEngineL2VSNISIDResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "l2vsn")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	isid, err := strconv.ParseInt(id.Key, 10, 32)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("%q is not an I-SID", id.Key))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("i_sid"), int32(isid))...)
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the I-SID from the device into m. It returns false if the
+// I-SID does not exist.
+func (r *FabricEngineL2VSNISIDResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineL2VSNISIDModel, diags *diag.Diagnostics) bool {
+
+	vsn, err := device.SSH.L2VSN(ctx, m.ISID.ValueInt32())
+	if err != nil {
+		addCommandError(diags, "Unable to read I-SID", err)
+		return true
+	}
+	if vsn == nil {
+		return false
+	}
+
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "l2vsn", strconv.Itoa(int(vsn.ISID))))
+	m.Name = types.StringValue(vsn.Name)
+	m.Endpoints = endpointsToSet(ctx, vsn.Endpoints, diags)
+	return true
+}
+
+// l2vsn returns the I-SID described by m.
+func (m *FabricEngineL2VSNISIDModel) l2vsn(ctx context.Context, diags *diag.Diagnostics) transport.L2VSN {
+	return transport.L2VSN{
+		ISID:      m.ISID.ValueInt32(),
+		Name:      m.Name.ValueString(),
+		Endpoints: endpointsFromSet(ctx, m.Endpoints, diags),
+	}
+}
+
+// endpointsFromSet returns the endpoints of a set attribute, sorted.
+func endpointsFromSet(ctx context.Context, set types.Set, diags *diag.Diagnostics) []transport.Endpoint {
+	var models []FabricEngineEndpointModel
+	diags.Append(set.ElementsAs(ctx, &models, false)...)
+	endpoints := make([]transport.Endpoint, len(models))
+	for i, e := range models {
+		endpoints[i] = transport.Endpoint{
+			Interface: transport.Interface{Port: e.Port.ValueString(), MLT: e.MLTID.ValueInt32()},
+			CVID:      e.CVID.ValueInt32(),
+		}
+	}
+	transport.SortEndpoints(endpoints)
+	return endpoints
+}
+
+// endpointsToSet returns endpoints as a set attribute value, which is empty
+// rather than null when there are no endpoints.
+func endpointsToSet(ctx context.Context, endpoints []transport.Endpoint, diags *diag.Diagnostics) types.Set {
+	models := make([]FabricEngineEndpointModel, len(endpoints))
+	for i, e := range endpoints {
+		models[i] = FabricEngineEndpointModel{Port: types.StringNull(), MLTID: types.Int32Null(), CVID: types.Int32Null()}
+		if e.Port != "" {
+			models[i].Port = types.StringValue(e.Port)
+		} else {
+			models[i].MLTID = types.Int32Value(e.MLT)
+		}
+		if e.CVID != 0 {
+			models[i].CVID = types.Int32Value(e.CVID)
+		}
+	}
+	set, d := types.SetValueFrom(ctx, endpointType, models)
+	diags.Append(d...)
+	return set
+}
@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineMLTResource{}
+
+// mltNameRe matches the names accepted by "mlt <id> name".
+var mltNameRe = regexp.MustCompile(`^[^"]{1,20}$`)
+
+// FabricEngineMLTResource implements resource.Resource.
+type FabricEngineMLTResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineMLTResource returns a new instance of the resource.
+func NewFabricEngineMLTResource() resource.Resource {
+	return &FabricEngineMLTResource{}
+}
+
+// FabricEngineMLTModel describes the resource model used in Terraform state.
+type FabricEngineMLTModel struct {
+	ID                 types.String `tfsdk:"id"`
+	Device             types.String `tfsdk:"device"`
+	MLTID              types.Int32  `tfsdk:"mlt_id"`
+	Name               types.String `tfsdk:"name"`
+	EncapsulationDot1q types.Bool   `tfsdk:"encapsulation_dot1q"`
+	Members            types.Set    `tfsdk:"members"`
+	LACPKey            types.Int32  `tfsdk:"lacp_key"`
+}
+
+func (r *FabricEngineMLTResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_mlt"
+}
+
+func (r *FabricEngineMLTResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages an MLT (`mlt <id>`) and its member ports, either static or aggregated with LACP.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"mlt_id": schema.Int32Attribute{
+				MarkdownDescription: "MLT ID, from 1 to 512.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 512)},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the MLT, up to 20 characters. Defaults to the name assigned by the device, `MLT-<mlt_id>`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				Validators:          []validator.String{stringMatches(mltNameRe, "1 to 20 characters without quotes")},
+			},
+			"encapsulation_dot1q": schema.BoolAttribute{
+				MarkdownDescription: "Whether the MLT tags its traffic (`encapsulation dot1q`). Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"members": portsAttribute(
+				"Member ports of the MLT. Only the ports added or removed are configured on update, " +
+					"so the other members keep forwarding."),
+			"lacp_key": schema.Int32Attribute{
+				MarkdownDescription: "LACP key, from 1 to 512. When set, LACP is enabled on the MLT and the members join it " +
+					"with `lacp key`, `lacp aggregation enable` and `lacp enable` instead of `mlt <id> member`.",
+				Optional:   true,
+				Validators: []validator.Int32{int32Between(1, 512)},
+			},
+		},
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineMLTResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create creates the MLT and adds its member ports.
+func (r *FabricEngineMLTResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineMLTModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	mlt := plan.mlt(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.CreateMLT(ctx, mlt); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to create MLT", err)
+		return
+	}
+
+	// Read back the attributes assigned by the device, such as the default name.
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("MLT not found",
+			fmt.Sprintf("MLT %d does not exist on the device after being configured.", plan.MLTID.ValueInt32()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the MLT from "show mlt" and "show lacp interface mlt".
+func (r *FabricEngineMLTResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineMLTModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The MLT was deleted outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes the name, encapsulation and LACP key of the MLT and adds or
+// removes the member ports that changed.
+func (r *FabricEngineMLTResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineMLTModel
+	var state FabricEngineMLTModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old := state.mlt(ctx, &resp.Diagnostics)
+	mlt := plan.mlt(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.UpdateMLT(ctx, old, mlt); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update MLT", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("MLT not found",
+			fmt.Sprintf("MLT %d does not exist on the device after being configured.", plan.MLTID.ValueInt32()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the LACP configuration of the members and deletes the MLT.
+func (r *FabricEngineMLTResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineMLTModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	mlt := state.mlt(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.DeleteMLT(ctx, mlt); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete MLT", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the MLT named by the import ID, "[<device>:]mlt/<mlt_id>".
+func (r *FabricEngineMLTResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "mlt")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	mltID, err := strconv.ParseInt(id.Key, 10, 32)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("%q is not an MLT ID", id.Key))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("mlt_id"), int32(mltID))...)
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the MLT from the device into m. It returns false if the MLT
+// does not exist.
+func (r *FabricEngineMLTResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineMLTModel, diags *diag.Diagnostics) bool {
+
+	mlt, err := device.SSH.MLT(ctx, m.MLTID.ValueInt32())
+	if err != nil {
+		addCommandError(diags, "Unable to read MLT", err)
+		return true
+	}
+	if mlt == nil {
+		return false
+	}
+
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "mlt", strconv.Itoa(int(mlt.ID))))
+	m.Name = types.StringValue(mlt.Name)
+	m.EncapsulationDot1q = types.BoolValue(mlt.Tagged)
+	m.Members = portsToSet(ctx, mlt.Members, diags)
+	m.LACPKey = types.Int32Null()
+	if mlt.LACPKey != 0 {
+		m.LACPKey = types.Int32Value(mlt.LACPKey)
+	}
+	return true
+}
+
+// mlt returns the MLT described by m.
+func (m *FabricEngineMLTModel) mlt(ctx context.Context, diags *diag.Diagnostics) transport.MLT {
+	return transport.MLT{
+		ID:      m.MLTID.ValueInt32(),
+		Name:    m.Name.ValueString(),
+		Tagged:  m.EncapsulationDot1q.ValueBool(),
+		Members: portsFromSet(ctx, m.Members, diags),
+		LACPKey: m.LACPKey.ValueInt32(),
+	}
+}
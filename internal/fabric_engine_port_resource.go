@@ -0,0 +1,485 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEnginePortResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEnginePortResource{}
+
+// portNameRe matches the names accepted by "name" on a port.
+var portNameRe = regexp.MustCompile(`^[^"]{1,64}$`)
+
+// portSpeeds are the speeds accepted by "speed", in Mb/s.
+var portSpeeds = []int32{10, 100, 1000, 10000, 25000, 40000, 100000}
+
+// FabricEnginePortResource implements resource.Resource.
+type FabricEnginePortResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEnginePortResource returns a new instance of the resource.
+func NewFabricEnginePortResource() resource.Resource {
+	return &FabricEnginePortResource{}
+}
+
+// FabricEnginePortModel describes the resource model used in Terraform state.
+type FabricEnginePortModel struct {
+	ID                   types.String `tfsdk:"id"`
+	Device               types.String `tfsdk:"device"`
+	Port                 types.String `tfsdk:"port"`
+	PortRange            types.String `tfsdk:"port_range"`
+	Name                 types.String `tfsdk:"name"`
+	Shutdown             types.Bool   `tfsdk:"shutdown"`
+	AutoNegotiation      types.Bool   `tfsdk:"auto_negotiation"`
+	Speed                types.Int32  `tfsdk:"speed"`
+	Duplex               types.String `tfsdk:"duplex"`
+	DefaultVLANID        types.Int32  `tfsdk:"default_vlan_id"`
+	EncapsulationDot1q   types.Bool   `tfsdk:"encapsulation_dot1q"`
+	UntagPortDefaultVLAN types.Bool   `tfsdk:"untag_port_default_vlan"`
+	FlexUNI              types.Bool   `tfsdk:"flex_uni"`
+	AutoSense            types.Bool   `tfsdk:"auto_sense"`
+	SpanningTreeLearning types.Bool   `tfsdk:"spanning_tree_learning"`
+}
+
+func (r *FabricEnginePortResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_port"
+}
+
+func (r *FabricEnginePortResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the settings of a port, or of a range of ports sharing the same settings, " +
+			"under `interface gigabitEthernet`. Destroying the resource restores the default settings.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"port": schema.StringAttribute{
+				MarkdownDescription: "Port to configure, e.g. `1/1`. Exactly one of `port` and `port_range` must be set.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{portValidator{}},
+			},
+			"port_range": schema.StringAttribute{
+				MarkdownDescription: "Ports to configure, written the way the device shows them, e.g. `1/1-1/24` or `1/1-1/4,2/1`.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the port, up to 64 characters.",
+				Optional:            true,
+				Validators:          []validator.String{stringMatches(portNameRe, "1 to 64 characters without quotes")},
+			},
+			"shutdown": schema.BoolAttribute{
+				MarkdownDescription: "Whether the port is administratively down. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"auto_negotiation": schema.BoolAttribute{
+				MarkdownDescription: "Whether the speed and duplex are negotiated (`auto-negotiate enable`). Defaults to true.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"speed": schema.Int32Attribute{
+				MarkdownDescription: "Speed of the port in Mb/s: 10, 100, 1000, 10000, 25000, 40000 or 100000. " +
+					"Required when `auto_negotiation` is false, and not allowed otherwise.",
+				Optional: true,
+			},
+			"duplex": schema.StringAttribute{
+				MarkdownDescription: "Duplex of the port, `full` or `half`. Required when `auto_negotiation` is false, and not allowed otherwise.",
+				Optional:            true,
+				Validators:          []validator.String{stringOneOf("full", "half")},
+			},
+			"default_vlan_id": schema.Int32Attribute{
+				MarkdownDescription: "VLAN of the untagged traffic received on the port (`default-vlan-id`), from 1 to 4059. " +
+					"The VLAN must exist. Defaults to 1.",
+				Optional:   true,
+				Computed:   true,
+				Default:    int32default.StaticInt32(1),
+				Validators: []validator.Int32{int32Between(1, 4059)},
+			},
+			"encapsulation_dot1q": schema.BoolAttribute{
+				MarkdownDescription: "Whether the port tags its traffic (`encapsulation dot1q`). " +
+					"Leave unset when the tagging is managed by the `tagged_ports` and `untagged_ports` of the VLAN resource.",
+				Optional: true,
+			},
+			"untag_port_default_vlan": schema.BoolAttribute{
+				MarkdownDescription: "Whether a tagged port sends its default VLAN untagged (`untag-port-default-vlan enable`). Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"flex_uni": schema.BoolAttribute{
+				MarkdownDescription: "Whether Flex UNI is enabled (`flex-uni enable`). The port must not be a member of any VLAN. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"auto_sense": schema.BoolAttribute{
+				MarkdownDescription: "Whether auto-sense is enabled (`auto-sense enable`). Cannot be combined with `flex_uni`. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"spanning_tree_learning": schema.BoolAttribute{
+				MarkdownDescription: "Whether the port learns MAC addresses in the spanning tree forwarding state " +
+					"(`spanning-tree mstp learning`). Defaults to true.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that exactly one of port and port_range is set, and
+// the combinations of the speed, duplex and tagging settings.
+func (r *FabricEnginePortResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEnginePortModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Port.IsNull() == config.PortRange.IsNull() && !config.Port.IsUnknown() && !config.PortRange.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(path.Root("port"), "Invalid attribute combination",
+			"Exactly one of port and port_range must be set.")
+	}
+	if !config.PortRange.IsNull() && !config.PortRange.IsUnknown() {
+		s := config.PortRange.ValueString()
+		ports, err := transport.ParsePorts(s)
+		switch {
+		case err != nil || len(ports) == 0:
+			resp.Diagnostics.AddAttributeError(path.Root("port_range"), "Invalid port range",
+				fmt.Sprintf("%q is not a port list such as \"1/1-1/24\"", s))
+		case transport.FormatPorts(ports) != s:
+			resp.Diagnostics.AddAttributeError(path.Root("port_range"), "Invalid port range",
+				fmt.Sprintf("%q must be written %q, the way the device shows it", s, transport.FormatPorts(ports)))
+		}
+	}
+
+	if !config.AutoNegotiation.IsUnknown() {
+		if config.AutoNegotiation.ValueBool() || config.AutoNegotiation.IsNull() {
+			if !config.Speed.IsNull() || !config.Duplex.IsNull() {
+				resp.Diagnostics.AddAttributeError(path.Root("auto_negotiation"), "Invalid attribute combination",
+					"speed and duplex require auto_negotiation to be false.")
+			}
+		} else if config.Speed.IsNull() || config.Duplex.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("auto_negotiation"), "Missing attribute",
+				"speed and duplex are required when auto_negotiation is false.")
+		}
+	}
+	if !config.Speed.IsNull() && !config.Speed.IsUnknown() {
+		valid := false
+		for _, speed := range portSpeeds {
+			valid = valid || config.Speed.ValueInt32() == speed
+		}
+		if !valid {
+			resp.Diagnostics.AddAttributeError(path.Root("speed"), "Invalid attribute value",
+				fmt.Sprintf("Speed must be one of %v, got %d", portSpeeds, config.Speed.ValueInt32()))
+		}
+	}
+
+	if config.UntagPortDefaultVLAN.ValueBool() && !config.EncapsulationDot1q.IsNull() &&
+		!config.EncapsulationDot1q.IsUnknown() && !config.EncapsulationDot1q.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("untag_port_default_vlan"), "Invalid attribute combination",
+			"untag_port_default_vlan requires encapsulation_dot1q.")
+	}
+	if config.FlexUNI.ValueBool() && config.AutoSense.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("auto_sense"), "Invalid attribute combination",
+			"flex_uni and auto_sense cannot both be enabled.")
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEnginePortResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create configures the settings of the ports that differ from the plan.
+func (r *FabricEnginePortResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEnginePortModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	// The ports always exist, so the plan is applied over their current
+	// settings.
+	ports := plan.ports()
+	current, err := device.SSH.PortConfigs(ctx, ports)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read port configuration", err)
+		return
+	}
+	var configs []transport.PortConfig
+	for _, port := range ports {
+		c, ok := current[port]
+		if !ok {
+			resp.Diagnostics.AddError("Port not found", fmt.Sprintf("Port %s does not exist on the device.", port))
+			return
+		}
+		configs = append(configs, c)
+	}
+	config := plan.portConfig()
+	old := transport.SummarizePortConfigs(configs, config)
+	if plan.EncapsulationDot1q.IsNull() {
+		config.Tagged = old.Tagged
+	}
+	if err := device.SSH.ApplyPortConfig(ctx, ports, old, config); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to configure port", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("Port not found", fmt.Sprintf("Port %s does not exist on the device.", plan.key()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the ports from "show interfaces gigabitEthernet config" and
+// "show interfaces gigabitEthernet name".
+func (r *FabricEnginePortResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEnginePortModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The port no longer exists, e.g. after a module was removed.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update configures the settings that changed on every port.
+func (r *FabricEnginePortResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEnginePortModel
+	var state FabricEnginePortModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old, config := state.portConfig(), plan.portConfig()
+	if plan.EncapsulationDot1q.IsNull() {
+		config.Tagged = old.Tagged
+	}
+	if err := device.SSH.ApplyPortConfig(ctx, plan.ports(), old, config); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to configure port", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("Port not found", fmt.Sprintf("Port %s does not exist on the device.", plan.key()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete restores the default settings of the ports. The tagging is left
+// alone when encapsulation_dot1q is not managed.
+func (r *FabricEnginePortResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEnginePortModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old, config := state.portConfig(), transport.DefaultPortConfig
+	if state.EncapsulationDot1q.IsNull() {
+		config.Tagged = old.Tagged
+	}
+	if err := device.SSH.ApplyPortConfig(ctx, state.ports(), old, config); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to reset port", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the ports named by the import ID, "[<device>:]port/<port>"
+// or "[<device>:]port/<port_range>".
+func (r *FabricEnginePortResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "port")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	switch ports, err := transport.ParsePorts(id.Key); {
+	case err != nil || len(ports) == 0 || transport.FormatPorts(ports) != id.Key:
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("invalid ID %q: expected port/<port> or port/<port_range>", req.ID))
+		return
+	case strings.ContainsAny(id.Key, "-,"):
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("port_range"), id.Key)...)
+	default:
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("port"), id.Key)...)
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the ports from the device into m. It returns false if one of
+// the ports does not exist. When the ports of a range differ, the settings of
+// the first port that differs from m are reported, so the difference shows in
+// the plan.
+func (r *FabricEnginePortResource) refresh(
+	ctx context.Context, device *Device, m *FabricEnginePortModel, diags *diag.Diagnostics) bool {
+
+	ports := m.ports()
+	current, err := device.SSH.PortConfigs(ctx, ports)
+	if err != nil {
+		addCommandError(diags, "Unable to read port configuration", err)
+		return true
+	}
+	var configs []transport.PortConfig
+	for _, port := range ports {
+		c, ok := current[port]
+		if !ok {
+			return false
+		}
+		configs = append(configs, c)
+	}
+	p := transport.SummarizePortConfigs(configs, m.portConfig())
+
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "port", m.key()))
+	m.Name = types.StringNull()
+	if p.Name != "" {
+		m.Name = types.StringValue(p.Name)
+	}
+	m.Shutdown = types.BoolValue(p.Shutdown)
+	m.AutoNegotiation = types.BoolValue(p.AutoNegotiation)
+	m.Speed, m.Duplex = types.Int32Null(), types.StringNull()
+	if !p.AutoNegotiation {
+		m.Speed, m.Duplex = types.Int32Value(p.Speed), types.StringValue(p.Duplex)
+	}
+	m.DefaultVLANID = types.Int32Value(p.DefaultVLAN)
+	if !m.EncapsulationDot1q.IsNull() {
+		m.EncapsulationDot1q = types.BoolValue(p.Tagged)
+	}
+	m.UntagPortDefaultVLAN = types.BoolValue(p.UntagDefaultVLAN)
+	m.FlexUNI = types.BoolValue(p.FlexUNI)
+	m.AutoSense = types.BoolValue(p.AutoSense)
+	m.SpanningTreeLearning = types.BoolValue(p.SpanningTreeLearning)
+	return true
+}
+
+// key returns the port or the port range of m.
+func (m *FabricEnginePortModel) key() string {
+	if !m.PortRange.IsNull() {
+		return m.PortRange.ValueString()
+	}
+	return m.Port.ValueString()
+}
+
+// ports returns the ports configured by m.
+func (m *FabricEnginePortModel) ports() []string {
+	// The port range was validated by ValidateConfig.
+	ports, _ := transport.ParsePorts(m.key())
+	return ports
+}
+
+// portConfig returns the port configuration described by m.
+func (m *FabricEnginePortModel) portConfig() transport.PortConfig {
+	return transport.PortConfig{
+		Name:                 m.Name.ValueString(),
+		Shutdown:             m.Shutdown.ValueBool(),
+		AutoNegotiation:      m.AutoNegotiation.ValueBool(),
+		Speed:                m.Speed.ValueInt32(),
+		Duplex:               m.Duplex.ValueString(),
+		DefaultVLAN:          m.DefaultVLANID.ValueInt32(),
+		Tagged:               m.EncapsulationDot1q.ValueBool(),
+		UntagDefaultVLAN:     m.UntagPortDefaultVLAN.ValueBool(),
+		FlexUNI:              m.FlexUNI.ValueBool(),
+		AutoSense:            m.AutoSense.ValueBool(),
+		SpanningTreeLearning: m.SpanningTreeLearning.ValueBool(),
+	}
+}
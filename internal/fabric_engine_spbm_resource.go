@@ -0,0 +1,358 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineSPBMResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineSPBMResource{}
+
+var (
+	systemIDRe   = regexp.MustCompile(`^[0-9a-f]{4}\.[0-9a-f]{4}\.[0-9a-f]{4}$`)
+	nickNameRe   = regexp.MustCompile(`^[0-9a-f]\.[0-9a-f]{2}\.[0-9a-f]{2}$`)
+	manualAreaRe = regexp.MustCompile(`^[0-9a-f]{2}(\.[0-9a-f]{4}){0,6}$`)
+)
+
+// FabricEngineSPBMResource implements resource.Resource.
+type FabricEngineSPBMResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineSPBMResource returns a new instance of the resource.
+func NewFabricEngineSPBMResource() resource.Resource {
+	return &FabricEngineSPBMResource{}
+}
+
+// FabricEngineSPBMModel describes the resource model used in Terraform state.
+type FabricEngineSPBMModel struct {
+	ID         types.String `tfsdk:"id"`
+	Device     types.String `tfsdk:"device"`
+	SystemID   types.String `tfsdk:"system_id"`
+	ManualArea types.String `tfsdk:"manual_area"`
+	NickName   types.String `tfsdk:"nick_name"`
+	BVLANs     types.List   `tfsdk:"b_vlans"`
+	Enabled    types.Bool   `tfsdk:"enabled"`
+	OnDestroy  types.String `tfsdk:"on_destroy"`
+}
+
+func (r *FabricEngineSPBMResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_spbm"
+}
+
+func (r *FabricEngineSPBMResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the global Fabric Connect configuration of a device: SPBM instance 1 and IS-IS. " +
+			"The B-VLANs must exist, see the `spbm-bvlan` type of the VLAN resource.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"system_id": schema.StringAttribute{
+				MarkdownDescription: "IS-IS system ID, formatted as `xxxx.xxxx.xxxx`. Defaults to the value derived from the base MAC address.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				Validators:          []validator.String{stringMatches(systemIDRe, "xxxx.xxxx.xxxx")},
+			},
+			"manual_area": schema.StringAttribute{
+				MarkdownDescription: "IS-IS manual area, e.g. `49.0001`.",
+				Required:            true,
+				Validators:          []validator.String{stringMatches(manualAreaRe, "xx.xxxx")},
+			},
+			"nick_name": schema.StringAttribute{
+				MarkdownDescription: "SPBM nick-name of the device, formatted as `x.xx.xx`.",
+				Required:            true,
+				Validators:          []validator.String{stringMatches(nickNameRe, "x.xx.xx")},
+			},
+			"b_vlans": schema.ListAttribute{
+				MarkdownDescription: "One or two B-VLANs of SPBM instance 1. The first one is the primary B-VLAN.",
+				ElementType:         types.Int32Type,
+				Required:            true,
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether IS-IS is enabled with `router isis enable`. Defaults to true.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"on_destroy": onDestroyAttribute(),
+		},
+	}
+}
+
+// ValidateConfig checks the number and range of the B-VLANs.
+func (r *FabricEngineSPBMResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineSPBMModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || config.BVLANs.IsUnknown() {
+		return
+	}
+
+	var bvlans []types.Int32
+	resp.Diagnostics.Append(config.BVLANs.ElementsAs(ctx, &bvlans, false)...)
+	if len(bvlans) < 1 || len(bvlans) > 2 {
+		resp.Diagnostics.AddAttributeError(path.Root("b_vlans"), "Invalid attribute value",
+			fmt.Sprintf("SPBM instance 1 requires one or two B-VLANs, got %d", len(bvlans)))
+	}
+	seen := map[int32]bool{}
+	for _, bvlan := range bvlans {
+		if bvlan.IsUnknown() || bvlan.IsNull() {
+			continue
+		}
+		if id := bvlan.ValueInt32(); id < 2 || id > 4059 || seen[id] {
+			resp.Diagnostics.AddAttributeError(path.Root("b_vlans"), "Invalid attribute value",
+				fmt.Sprintf("B-VLANs must be distinct VLAN IDs from 2 to 4059, got %d", id))
+		}
+		seen[bvlan.ValueInt32()] = true
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineSPBMResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create records the current configuration for on_destroy = "restore_original" and applies the planned one.
+func (r *FabricEngineSPBMResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineSPBMModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	current, err := device.SSH.SPBM(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read SPBM configuration", err)
+		return
+	}
+	resp.Diagnostics.Append(saveOriginal(ctx, resp.Private, current)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.apply(ctx, device, current, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the configuration from "show isis", "show isis manual-area" and "show isis spbm".
+func (r *FabricEngineSPBMResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineSPBMModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	current, err := device.SSH.SPBM(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read SPBM configuration", err)
+		return
+	}
+	// An imported configuration has not been changed by Terraform yet, so it is the original one.
+	var original transport.SPBM
+	if ok, diags := loadOriginal(ctx, req.Private, &original); !ok && !diags.HasError() {
+		resp.Diagnostics.Append(saveOriginal(ctx, resp.Private, current)...)
+	}
+
+	state.fromSPBM(ctx, current, &resp.Diagnostics)
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update applies the planned configuration, disabling IS-IS around the changes that require it.
+func (r *FabricEngineSPBMResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineSPBMModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	// Diff against the device rather than the state, so the IS-IS state the changes depend on is accurate.
+	current, err := device.SSH.SPBM(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read SPBM configuration", err)
+		return
+	}
+
+	r.apply(ctx, device, current, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete applies the on_destroy policy and removes the resource from state.
+func (r *FabricEngineSPBMResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineSPBMModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var target transport.SPBM
+	switch state.OnDestroy.ValueString() {
+	case onDestroyResetToDefault:
+		// The zero value disables IS-IS and removes the SPBM instance, the manual area and the system ID.
+	case onDestroyRestoreOriginal:
+		ok, diags := loadOriginal(ctx, req.Private, &target)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !ok {
+			resp.Diagnostics.AddWarning("Original SPBM configuration unknown",
+				"No configuration was recorded before Terraform managed the device, the current configuration is retained.")
+			resp.State.RemoveResource(ctx)
+			return
+		}
+	default:
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	current, err := device.SSH.SPBM(ctx)
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read SPBM configuration", err)
+		return
+	}
+	if err := device.SSH.ApplySPBM(ctx, *current, target); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to reset SPBM configuration", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the configuration of the device named by the import ID, "default" for the default device.
+func (r *FabricEngineSPBMResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	device, err := importid.ParseSingleton(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), importid.Singleton(device))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("on_destroy"), onDestroyRetain)...)
+	if device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), device)...)
+	}
+}
+
+// apply changes the device configuration from current to the one planned in
+// m, then reads it back into m.
+func (r *FabricEngineSPBMResource) apply(
+	ctx context.Context, device *Device, current *transport.SPBM, m *FabricEngineSPBMModel, diags *diag.Diagnostics) {
+
+	spbm := transport.SPBM{
+		SystemID:   m.SystemID.ValueString(),
+		ManualArea: m.ManualArea.ValueString(),
+		NickName:   m.NickName.ValueString(),
+		Enabled:    m.Enabled.ValueBool(),
+	}
+	if m.SystemID.IsUnknown() {
+		// Keep the system ID of the device.
+		spbm.SystemID = current.SystemID
+	}
+	diags.Append(m.BVLANs.ElementsAs(ctx, &spbm.BVLANs, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	if err := device.SSH.ApplySPBM(ctx, *current, spbm); err != nil {
+		addCommandError(diags, "Unable to configure SPBM", err)
+		return
+	}
+
+	applied, err := device.SSH.SPBM(ctx)
+	if err != nil {
+		addCommandError(diags, "Unable to read SPBM configuration", err)
+		return
+	}
+	m.fromSPBM(ctx, applied, diags)
+}
+
+// fromSPBM sets the attributes of m from the configuration read from the
+// device.
+func (m *FabricEngineSPBMModel) fromSPBM(ctx context.Context, s *transport.SPBM, diags *diag.Diagnostics) {
+	m.ID = types.StringValue(importid.Singleton(m.Device.ValueString()))
+	m.SystemID = types.StringValue(s.SystemID)
+	m.ManualArea = types.StringValue(s.ManualArea)
+	m.NickName = types.StringValue(s.NickName)
+	m.Enabled = types.BoolValue(s.Enabled)
+
+	bvlans := s.BVLANs
+	if bvlans == nil {
+		bvlans = []int32{}
+	}
+	list, d := types.ListValueFrom(ctx, types.Int32Type, bvlans)
+	diags.Append(d...)
+	m.BVLANs = list
+}
@@ -0,0 +1,405 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineStaticRouteResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineStaticRouteResource{}
+
+// FabricEngineStaticRouteResource implements resource.Resource.
+type FabricEngineStaticRouteResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineStaticRouteResource returns a new instance of the resource.
+func NewFabricEngineStaticRouteResource() resource.Resource {
+	return &FabricEngineStaticRouteResource{}
+}
+
+// FabricEngineStaticRouteModel describes the resource model used in Terraform state.
+type FabricEngineStaticRouteModel struct {
+	ID           types.String `tfsdk:"id"`
+	Device       types.String `tfsdk:"device"`
+	Destination  types.String `tfsdk:"destination"`
+	VRF          types.String `tfsdk:"vrf"`
+	NextHops     types.Set    `tfsdk:"next_hops"`
+	Preference   types.Int32  `tfsdk:"preference"`
+	Enabled      types.Bool   `tfsdk:"enabled"`
+	LocalNextHop types.Bool   `tfsdk:"local_next_hop"`
+}
+
+// FabricEngineNextHopModel describes a next hop of a static route.
+type FabricEngineNextHopModel struct {
+	Address types.String `tfsdk:"address"`
+	Weight  types.Int32  `tfsdk:"weight"`
+}
+
+// nextHopType is the object type of an element of the next_hops attribute.
+var nextHopType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"address": types.StringType,
+	"weight":  types.Int32Type,
+}}
+
+func (r *FabricEngineStaticRouteResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_static_route"
+}
+
+func (r *FabricEngineStaticRouteResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the static routes (`ip route`) to a destination, in the global router " +
+			"or in a VRF. Each next hop is a route of its own on the device; several next hops are used for ECMP.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"destination": schema.StringAttribute{
+				MarkdownDescription: "Destination network with its prefix length, e.g. `10.1.0.0/16` or `0.0.0.0/0`.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{addressValidator{network: true}},
+			},
+			"vrf": schema.StringAttribute{
+				MarkdownDescription: "VRF of the route. Unset for the global router. The VRF must exist.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{stringMatches(vrfNameRe, "up to 16 letters, digits, _, . or -")},
+			},
+			"next_hops": schema.SetNestedAttribute{
+				MarkdownDescription: "Next hops of the route. Only the next hops added, removed or changed are configured on update.",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							MarkdownDescription: "IPv4 address of the next hop.",
+							Required:            true,
+						},
+						"weight": schema.Int32Attribute{
+							MarkdownDescription: "Cost of the route through the next hop, from 1 to 65535.",
+							Required:            true,
+							Validators:          []validator.Int32{int32Between(1, 65535)},
+						},
+					},
+				},
+			},
+			"preference": schema.Int32Attribute{
+				MarkdownDescription: "Route preference of every next hop, from 1 to 255. Defaults to 5.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(transport.DefaultRoutePreference),
+				Validators:          []validator.Int32{int32Between(1, 255)},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the routes are enabled. Defaults to true.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"local_next_hop": schema.BoolAttribute{
+				MarkdownDescription: "Whether the next hops must be on a directly connected network for the routes " +
+					"to be active (`local-next-hop enable`). Defaults to true.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+			},
+		},
+	}
+}
+
+// ValidateConfig checks the next hop addresses.
+func (r *FabricEngineStaticRouteResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineStaticRouteModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || config.NextHops.IsNull() || config.NextHops.IsUnknown() {
+		return
+	}
+
+	if len(config.NextHops.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("next_hops"), "Missing attribute",
+			"At least one next hop must be set.")
+		return
+	}
+	var nextHops []FabricEngineNextHopModel
+	resp.Diagnostics.Append(config.NextHops.ElementsAs(ctx, &nextHops, false)...)
+	seen := map[string]bool{}
+	for _, nh := range nextHops {
+		if nh.Address.IsNull() || nh.Address.IsUnknown() {
+			continue
+		}
+		s := nh.Address.ValueString()
+		addr, err := netip.ParseAddr(s)
+		switch {
+		case err != nil || !addr.Is4():
+			resp.Diagnostics.AddAttributeError(path.Root("next_hops"), "Invalid address",
+				fmt.Sprintf("%q is not an IPv4 address such as \"192.168.1.1\"", s))
+		case addr.String() != s:
+			resp.Diagnostics.AddAttributeError(path.Root("next_hops"), "Invalid address",
+				fmt.Sprintf("%q must be written %q, the way the device shows it", s, addr.String()))
+		case seen[s]:
+			resp.Diagnostics.AddAttributeError(path.Root("next_hops"), "Duplicate next hop",
+				fmt.Sprintf("Next hop %s is set more than once.", s))
+		}
+		seen[s] = true
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineStaticRouteResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create creates a route through every next hop.
+func (r *FabricEngineStaticRouteResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineStaticRouteModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	route := plan.staticRoute(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.ApplyStaticRoute(ctx, nil, route); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to create static route", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("Static route not found",
+			fmt.Sprintf("No static route to %s exists on the device after being configured.", route.Destination))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the routes from "show ip route static".
+func (r *FabricEngineStaticRouteResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineStaticRouteModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The routes were deleted outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update adds, changes and removes the next hops that differ.
+func (r *FabricEngineStaticRouteResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineStaticRouteModel
+	var state FabricEngineStaticRouteModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old := state.staticRoute(ctx, &resp.Diagnostics)
+	route := plan.staticRoute(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.ApplyStaticRoute(ctx, &old, route); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update static route", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("Static route not found",
+			fmt.Sprintf("No static route to %s exists on the device after being configured.", route.Destination))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the route through every next hop.
+func (r *FabricEngineStaticRouteResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineStaticRouteModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	route := state.staticRoute(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.DeleteStaticRoute(ctx, route); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete static route", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the routes named by the import ID,
+// "[<device>:]static_route/<vrf>/<destination>", where <vrf> is GlobalRouter
+// for the global router, e.g. "static_route/GlobalRouter/10.1.0.0/16".
+func (r *FabricEngineStaticRouteResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "static_route")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	vrf, destination, _ := strings.Cut(id.Key, "/")
+	prefix, err := netip.ParsePrefix(destination)
+	if !vrfNameRe.MatchString(vrf) || err != nil || !prefix.Addr().Is4() || prefix.Masked().String() != destination {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("invalid ID %q: expected static_route/<vrf>/<destination>, e.g. static_route/GlobalRouter/10.1.0.0/16", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("destination"), destination)...)
+	if vrf != transport.GlobalRouter {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vrf"), vrf)...)
+	}
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the routes into m. It returns false if there is no route to
+// the destination. The preference and flags are set on every next hop; when
+// a next hop differs from m, its value is reported so that the drift shows.
+func (r *FabricEngineStaticRouteResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineStaticRouteModel, diags *diag.Diagnostics) bool {
+
+	route, err := device.SSH.StaticRoute(ctx, m.VRF.ValueString(), m.Destination.ValueString())
+	if err != nil {
+		addCommandError(diags, "Unable to read static route", err)
+		return true
+	}
+	if route == nil {
+		return false
+	}
+
+	vrf := route.VRF
+	if vrf == "" {
+		vrf = transport.GlobalRouter
+	}
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "static_route", vrf+"/"+route.Destination))
+
+	preference, enabled, localNextHop := m.Preference, m.Enabled, m.LocalNextHop
+	m.Preference = types.Int32Value(route.NextHops[0].Preference)
+	m.Enabled = types.BoolValue(route.NextHops[0].Enabled)
+	m.LocalNextHop = types.BoolValue(route.NextHops[0].LocalNextHop)
+	models := make([]FabricEngineNextHopModel, len(route.NextHops))
+	for i, nh := range route.NextHops {
+		models[i] = FabricEngineNextHopModel{Address: types.StringValue(nh.Address), Weight: types.Int32Value(nh.Weight)}
+		if nh.Preference != preference.ValueInt32() {
+			m.Preference = types.Int32Value(nh.Preference)
+		}
+		if nh.Enabled != enabled.ValueBool() {
+			m.Enabled = types.BoolValue(nh.Enabled)
+		}
+		if nh.LocalNextHop != localNextHop.ValueBool() {
+			m.LocalNextHop = types.BoolValue(nh.LocalNextHop)
+		}
+	}
+	set, d := types.SetValueFrom(ctx, nextHopType, models)
+	diags.Append(d...)
+	m.NextHops = set
+	return true
+}
+
+// staticRoute returns the routes described by m.
+func (m *FabricEngineStaticRouteModel) staticRoute(ctx context.Context, diags *diag.Diagnostics) transport.StaticRoute {
+	route := transport.StaticRoute{VRF: m.VRF.ValueString(), Destination: m.Destination.ValueString()}
+	var models []FabricEngineNextHopModel
+	diags.Append(m.NextHops.ElementsAs(ctx, &models, false)...)
+	for _, nh := range models {
+		route.NextHops = append(route.NextHops, transport.NextHop{
+			Address:      nh.Address.ValueString(),
+			Weight:       nh.Weight.ValueInt32(),
+			Preference:   m.Preference.ValueInt32(),
+			Enabled:      m.Enabled.ValueBool(),
+			LocalNextHop: m.LocalNextHop.ValueBool(),
+		})
+	}
+	return route
+}
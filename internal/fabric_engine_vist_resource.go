@@ -0,0 +1,483 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineVISTResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineVISTResource{}
+var _ resource.ResourceWithModifyPlan = &FabricEngineVISTResource{}
+
+// virtualBMACRe matches the MAC addresses accepted by "spbm 1 smlt-virt-bmac".
+var virtualBMACRe = regexp.MustCompile(`^[0-9a-f]{2}(:[0-9a-f]{2}){5}$`)
+
+// FabricEngineVISTResource implements resource.Resource.
+type FabricEngineVISTResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineVISTResource returns a new instance of the resource.
+func NewFabricEngineVISTResource() resource.Resource {
+	return &FabricEngineVISTResource{}
+}
+
+// FabricEngineVISTModel describes the resource model used in Terraform state.
+type FabricEngineVISTModel struct {
+	ID               types.String `tfsdk:"id"`
+	Device           types.String `tfsdk:"device"`
+	PeerDevice       types.String `tfsdk:"peer_device"`
+	PeerIP           types.String `tfsdk:"peer_ip"`
+	VLANID           types.Int32  `tfsdk:"vlan_id"`
+	SMLTPeerSystemID types.String `tfsdk:"smlt_peer_system_id"`
+	SMLTVirtualBMAC  types.String `tfsdk:"smlt_virtual_bmac"`
+	SMLTMLTIDs       types.Set    `tfsdk:"smlt_mlt_ids"`
+}
+
+func (r *FabricEngineVISTResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_vist"
+}
+
+func (r *FabricEngineVISTResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the virtual IST of a switch in an SMLT cluster (`virtual-ist peer-ip`), " +
+			"the SMLT settings of SPBM instance 1 and the MLTs configured with `smlt`. " +
+			"The VLAN needs an IP interface in the global router whose subnet contains the peer IP, " +
+			"and SPBM instance 1 must exist. Each switch of the cluster has its own resource.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"peer_device": schema.StringAttribute{
+				MarkdownDescription: "Name of the other switch of the cluster in the provider's `devices` map. " +
+					"When set, `smlt_peer_system_id` defaults to the system ID of the peer, and the apply warns " +
+					"if the virtual IST of the peer then uses another virtual BMAC or VLAN or does not point back to this switch.",
+				Optional: true,
+			},
+			"peer_ip": schema.StringAttribute{
+				MarkdownDescription: "IPv4 address of the peer switch on the virtual IST VLAN.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"vlan_id": schema.Int32Attribute{
+				MarkdownDescription: "VLAN carrying the virtual IST, from 2 to 4059.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(2, 4059)},
+			},
+			"smlt_peer_system_id": schema.StringAttribute{
+				MarkdownDescription: "IS-IS system ID of the peer switch, formatted as `xxxx.xxxx.xxxx`. " +
+					"Required unless `peer_device` is set.",
+				Optional:   true,
+				Computed:   true,
+				Validators: []validator.String{stringMatches(systemIDRe, "xxxx.xxxx.xxxx")},
+			},
+			"smlt_virtual_bmac": schema.StringAttribute{
+				MarkdownDescription: "Virtual B-MAC shared by both switches of the cluster, formatted as `xx:xx:xx:xx:xx:xx`.",
+				Required:            true,
+				Validators:          []validator.String{stringMatches(virtualBMACRe, "xx:xx:xx:xx:xx:xx")},
+			},
+			"smlt_mlt_ids": schema.SetAttribute{
+				MarkdownDescription: "IDs of the MLTs configured with `smlt`. The MLTs must exist. Defaults to none.",
+				ElementType:         types.Int32Type,
+				Optional:            true,
+				Computed:            true,
+				Default:             setdefault.StaticValue(types.SetValueMust(types.Int32Type, []attr.Value{})),
+			},
+		},
+	}
+}
+
+// ValidateConfig checks the peer IP and MLT IDs, and that the peer system ID
+// is known.
+func (r *FabricEngineVISTResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineVISTModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.PeerIP.IsNull() && !config.PeerIP.IsUnknown() {
+		s := config.PeerIP.ValueString()
+		addr, err := netip.ParseAddr(s)
+		switch {
+		case err != nil || !addr.Is4():
+			resp.Diagnostics.AddAttributeError(path.Root("peer_ip"), "Invalid address",
+				fmt.Sprintf("%q is not an IPv4 address such as \"10.0.0.2\"", s))
+		case addr.String() != s:
+			resp.Diagnostics.AddAttributeError(path.Root("peer_ip"), "Invalid address",
+				fmt.Sprintf("%q must be written %q, the way the device shows it", s, addr.String()))
+		}
+	}
+	if config.PeerDevice.IsNull() && config.SMLTPeerSystemID.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("smlt_peer_system_id"), "Missing attribute",
+			"smlt_peer_system_id is required unless peer_device is set.")
+	}
+	if !config.PeerDevice.IsNull() && config.PeerDevice.Equal(config.Device) {
+		resp.Diagnostics.AddAttributeError(path.Root("peer_device"), "Invalid attribute value",
+			"peer_device must be the other switch of the cluster, not device.")
+	}
+
+	if config.SMLTMLTIDs.IsNull() || config.SMLTMLTIDs.IsUnknown() {
+		return
+	}
+	var ids []types.Int32
+	resp.Diagnostics.Append(config.SMLTMLTIDs.ElementsAs(ctx, &ids, false)...)
+	for _, id := range ids {
+		if id.IsUnknown() || id.IsNull() {
+			continue
+		}
+		if id.ValueInt32() < 1 || id.ValueInt32() > 512 {
+			resp.Diagnostics.AddAttributeError(path.Root("smlt_mlt_ids"), "Invalid attribute value",
+				fmt.Sprintf("MLT IDs must be from 1 to 512, got %d", id.ValueInt32()))
+		}
+	}
+}
+
+// ModifyPlan defaults smlt_peer_system_id to the system ID of the peer
+// device. The plan is not checked against the virtual IST of the peer, whose
+// resource may change it in the same run.
+func (r *FabricEngineVISTResource) ModifyPlan(
+	ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+	var plan FabricEngineVISTModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || plan.PeerDevice.IsNull() || plan.PeerDevice.IsUnknown() {
+		return
+	}
+
+	peer := r.peer(plan.PeerDevice, &resp.Diagnostics)
+	if peer == nil {
+		return
+	}
+	if plan.SMLTPeerSystemID.IsUnknown() {
+		spbm, err := peer.SSH.SPBM(ctx)
+		if err != nil {
+			addCommandError(&resp.Diagnostics, "Unable to read SPBM configuration of the peer", err)
+			return
+		}
+		plan.SMLTPeerSystemID = types.StringValue(spbm.SystemID)
+		diags = resp.Plan.SetAttribute(ctx, path.Root("smlt_peer_system_id"), plan.SMLTPeerSystemID)
+		resp.Diagnostics.Append(diags...)
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineVISTResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create configures the virtual IST, the SMLT settings of SPBM and the SMLTs.
+func (r *FabricEngineVISTResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineVISTModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	r.apply(ctx, device, nil, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the virtual IST from "show virtual-ist", "show isis spbm"
+// and "show mlt".
+func (r *FabricEngineVISTResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineVISTModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The virtual IST was deleted outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes the SMLT settings of SPBM and adds or removes "smlt" on the
+// MLTs that changed.
+func (r *FabricEngineVISTResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineVISTModel
+	var state FabricEngineVISTModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	old := state.vist(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, device, &old, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes "smlt" from the MLTs, the virtual IST and the SMLT settings
+// of SPBM.
+func (r *FabricEngineVISTResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineVISTModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	vist := state.vist(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.DeleteVIST(ctx, vist); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete virtual IST", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the virtual IST of the device named by the import ID, "default" for the default device.
+func (r *FabricEngineVISTResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	device, err := importid.ParseSingleton(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), importid.Singleton(device))...)
+	if device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), device)...)
+	}
+}
+
+// apply changes the virtual IST from old, nil to create it, to the one
+// planned in m, reads it back into m and checks it against the peer device.
+func (r *FabricEngineVISTResource) apply(
+	ctx context.Context, device *Device, old *transport.VIST, m *FabricEngineVISTModel, diags *diag.Diagnostics) {
+
+	var peer *Device
+	if !m.PeerDevice.IsNull() {
+		if peer = r.peer(m.PeerDevice, diags); peer == nil {
+			return
+		}
+		if m.SMLTPeerSystemID.IsUnknown() {
+			// peer_device was not known when the plan was made.
+			spbm, err := peer.SSH.SPBM(ctx)
+			if err != nil {
+				addCommandError(diags, "Unable to read SPBM configuration of the peer", err)
+				return
+			}
+			m.SMLTPeerSystemID = types.StringValue(spbm.SystemID)
+		}
+	}
+
+	vist := m.vist(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	if err := device.SSH.ApplyVIST(ctx, old, vist); err != nil {
+		addCommandError(diags, "Unable to configure virtual IST", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, m, diags) {
+		diags.AddError("Virtual IST not found", "The virtual IST does not exist on the device after being configured.")
+		return
+	}
+	if peer != nil {
+		// The virtual IST is configured by now, so failing to read the peer
+		// must not fail the apply either.
+		var check diag.Diagnostics
+		r.checkPeer(ctx, device, peer, m, &check)
+		diags.Append(check.Warnings()...)
+		for _, d := range check.Errors() {
+			diags.AddWarning(d.Summary(), d.Detail())
+		}
+	}
+}
+
+// refresh reads the virtual IST from the device into m. It returns false if
+// no virtual IST is configured.
+func (r *FabricEngineVISTResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineVISTModel, diags *diag.Diagnostics) bool {
+
+	vist, err := device.SSH.VIST(ctx)
+	if err != nil {
+		addCommandError(diags, "Unable to read virtual IST", err)
+		return true
+	}
+	if vist == nil {
+		return false
+	}
+
+	m.ID = types.StringValue(importid.Singleton(m.Device.ValueString()))
+	m.PeerIP = types.StringValue(vist.PeerIP)
+	m.VLANID = types.Int32Value(vist.VLAN)
+	m.SMLTPeerSystemID = types.StringValue(vist.PeerSystemID)
+	m.SMLTVirtualBMAC = types.StringValue(vist.VirtualBMAC)
+	ids := vist.SMLTs
+	if ids == nil {
+		ids = []int32{}
+	}
+	set, d := types.SetValueFrom(ctx, types.Int32Type, ids)
+	diags.Append(d...)
+	m.SMLTMLTIDs = set
+	return true
+}
+
+// peer looks up the peer device and reports an error on the peer_device
+// attribute when it does not exist.
+func (r *FabricEngineVISTResource) peer(name types.String, diags *diag.Diagnostics) *Device {
+	d, err := r.client.Device(name.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("peer_device"), "Unknown device", err.Error())
+		return nil
+	}
+	return d
+}
+
+// checkPeer warns about the settings of m that do not match the peer device:
+// its system ID, and the virtual BMAC, VLAN and peer system ID of its virtual
+// IST once it is configured. These are only warnings since the resource of
+// the peer may not have been applied yet, when both switches of the cluster
+// change in the same run.
+func (r *FabricEngineVISTResource) checkPeer(
+	ctx context.Context, device, peer *Device, m *FabricEngineVISTModel, diags *diag.Diagnostics) {
+
+	peerSPBM, err := peer.SSH.SPBM(ctx)
+	if err != nil {
+		addCommandError(diags, "Unable to read SPBM configuration of the peer", err)
+		return
+	}
+	if id := m.SMLTPeerSystemID; !id.IsUnknown() && id.ValueString() != peerSPBM.SystemID {
+		diags.AddAttributeWarning(path.Root("smlt_peer_system_id"), "SMLT peer mismatch",
+			fmt.Sprintf("smlt_peer_system_id is %s but the system ID of %s is %s.",
+				id.ValueString(), peer.Name, peerSPBM.SystemID))
+	}
+
+	peerVIST, err := peer.SSH.VIST(ctx)
+	if err != nil {
+		addCommandError(diags, "Unable to read virtual IST of the peer", err)
+		return
+	}
+	if peerVIST == nil {
+		return
+	}
+	if bmac := m.SMLTVirtualBMAC; !bmac.IsUnknown() && bmac.ValueString() != peerVIST.VirtualBMAC {
+		diags.AddAttributeWarning(path.Root("smlt_virtual_bmac"), "SMLT peer mismatch",
+			fmt.Sprintf("Both switches of an SMLT cluster must use the same virtual BMAC, "+
+				"%s uses %s.", peer.Name, peerVIST.VirtualBMAC))
+	}
+	if vlan := m.VLANID; !vlan.IsUnknown() && vlan.ValueInt32() != peerVIST.VLAN {
+		diags.AddAttributeWarning(path.Root("vlan_id"), "SMLT peer mismatch",
+			fmt.Sprintf("Both switches of an SMLT cluster must use the same virtual IST VLAN, "+
+				"%s uses VLAN %d.", peer.Name, peerVIST.VLAN))
+	}
+	if peerVIST.PeerSystemID == "" {
+		return
+	}
+	spbm, err := device.SSH.SPBM(ctx)
+	if err != nil {
+		addCommandError(diags, "Unable to read SPBM configuration", err)
+		return
+	}
+	if peerVIST.PeerSystemID != spbm.SystemID {
+		diags.AddWarning("SMLT peer mismatch",
+			fmt.Sprintf("The SMLT peer system ID of %s is %s but the system ID of this switch is %s.",
+				peer.Name, peerVIST.PeerSystemID, spbm.SystemID))
+	}
+}
+
+// vist returns the virtual IST described by m.
+func (m *FabricEngineVISTModel) vist(ctx context.Context, diags *diag.Diagnostics) transport.VIST {
+	v := transport.VIST{
+		PeerIP:       m.PeerIP.ValueString(),
+		VLAN:         m.VLANID.ValueInt32(),
+		PeerSystemID: m.SMLTPeerSystemID.ValueString(),
+		VirtualBMAC:  m.SMLTVirtualBMAC.ValueString(),
+	}
+	diags.Append(m.SMLTMLTIDs.ElementsAs(ctx, &v.SMLTs, false)...)
+	return v
+}
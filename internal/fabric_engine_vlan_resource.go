@@ -0,0 +1,345 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+// vlanNameRe matches the names accepted by "vlan name": printable ASCII
+// characters except the double quote, which cannot be escaped, and the
+// question mark, which opens the CLI help.
+var vlanNameRe = regexp.MustCompile(`^[ !#-/0-9:;<=>@-~]{1,64}$`)
+
+var _ resource.ResourceWithImportState = &FabricEngineVLANResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineVLANResource{}
+
+// FabricEngineVLANResource implements resource.Resource.
+type FabricEngineVLANResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineVLANResource returns a new instance of the resource.
+func NewFabricEngineVLANResource() resource.Resource {
+	return &FabricEngineVLANResource{}
+}
+
+// FabricEngineVLANModel describes the resource model used in Terraform state.
+type FabricEngineVLANModel struct {
+	ID            types.String `tfsdk:"id"`
+	Device        types.String `tfsdk:"device"`
+	VLANID        types.Int32  `tfsdk:"vlan_id"`
+	Name          types.String `tfsdk:"name"`
+	Type          types.String `tfsdk:"type"`
+	STG           types.Int32  `tfsdk:"stg"`
+	ISID          types.Int32  `tfsdk:"i_sid"`
+	TaggedPorts   types.Set    `tfsdk:"tagged_ports"`
+	UntaggedPorts types.Set    `tfsdk:"untagged_ports"`
+}
+
+func (r *FabricEngineVLANResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_vlan"
+}
+
+func (r *FabricEngineVLANResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a VLAN, its I-SID mapping and its member ports.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"vlan_id": schema.Int32Attribute{
+				MarkdownDescription: "VLAN ID, from 2 to 4059.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(2, 4059)},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the VLAN. Defaults to the name assigned by the device, `VLAN-<vlan_id>`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				Validators:          []validator.String{stringMatches(vlanNameRe, "1 to 64 printable ASCII characters without quotes or ?")},
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Type of the VLAN: `port-mstprstp` (default) or `spbm-bvlan` for an SPBM backbone VLAN.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(transport.VLANTypePort),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{stringOneOf(transport.VLANTypePort, transport.VLANTypeBVLAN)},
+			},
+			"stg": schema.Int32Attribute{
+				MarkdownDescription: "Spanning tree group of a `port-mstprstp` VLAN, from 0 to 63. Defaults to 0.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(0),
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(0, 63)},
+			},
+			"i_sid": schema.Int32Attribute{
+				MarkdownDescription: "I-SID the VLAN is mapped to with `vlan i-sid`, which extends it over the SPB fabric as an L2 VSN.",
+				Optional:            true,
+				Validators:          []validator.Int32{int32Between(1, 15999999)},
+			},
+			"tagged_ports": portsAttribute(
+				"Member ports sending the VLAN tagged. Tagging is a port setting on Fabric Engine: " +
+					"these ports are configured with `encapsulation dot1q`."),
+			"untagged_ports": portsAttribute(
+				"Member ports sending the VLAN untagged. These ports are configured with `no encapsulation dot1q`."),
+		},
+	}
+}
+
+// ValidateConfig rejects ports listed both as tagged and untagged, and an STG on a B-VLAN.
+func (r *FabricEngineVLANResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineVLANModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Type.ValueString() == transport.VLANTypeBVLAN && config.STG.ValueInt32() != 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("stg"), "Invalid attribute combination",
+			"The spanning tree group of an spbm-bvlan VLAN is assigned by the device and cannot be set.")
+	}
+
+	if config.TaggedPorts.IsUnknown() || config.UntaggedPorts.IsUnknown() {
+		return
+	}
+	tagged := portSet(portsFromSet(ctx, config.TaggedPorts, &resp.Diagnostics))
+	for _, port := range portsFromSet(ctx, config.UntaggedPorts, &resp.Diagnostics) {
+		if tagged[port] {
+			resp.Diagnostics.AddAttributeError(path.Root("untagged_ports"), "Conflicting port tagging",
+				fmt.Sprintf("Port %s cannot be both a tagged and an untagged member of the VLAN.", port))
+		}
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineVLANResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create creates the VLAN, maps its I-SID and adds its member ports.
+func (r *FabricEngineVLANResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineVLANModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	vlan := plan.vlan(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.CreateVLAN(ctx, vlan); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to create VLAN", err)
+		return
+	}
+
+	// Read back the attributes assigned by the device, such as the default name.
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("VLAN not found",
+			fmt.Sprintf("VLAN %d does not exist on the device after being configured.", plan.VLANID.ValueInt32()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the VLAN from "show vlan basic", "show vlan i-sid" and "show vlan members".
+func (r *FabricEngineVLANResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineVLANModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The VLAN was deleted outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update renames the VLAN, changes its I-SID mapping and adjusts its member ports.
+func (r *FabricEngineVLANResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineVLANModel
+	var state FabricEngineVLANModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	old := state.vlan(ctx, &resp.Diagnostics)
+	vlan := plan.vlan(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := device.SSH.UpdateVLAN(ctx, old, vlan); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update VLAN", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("VLAN not found",
+			fmt.Sprintf("VLAN %d does not exist on the device after being configured.", plan.VLANID.ValueInt32()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the VLAN, which also removes its member ports and I-SID mapping.
+func (r *FabricEngineVLANResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineVLANModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.DeleteVLAN(ctx, state.VLANID.ValueInt32()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete VLAN", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the VLAN named by the import ID, "[<device>:]vlan/<vlan_id>".
+func (r *FabricEngineVLANResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "vlan")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	vlanID, err := strconv.ParseInt(id.Key, 10, 32)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("%q is not a VLAN ID", id.Key))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vlan_id"), int32(vlanID))...)
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the VLAN from the device into m. It returns false if the VLAN
+// does not exist.
+func (r *FabricEngineVLANResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineVLANModel, diags *diag.Diagnostics) bool {
+
+	vlan, err := device.SSH.VLAN(ctx, m.VLANID.ValueInt32())
+	if err != nil {
+		addCommandError(diags, "Unable to read VLAN", err)
+		return true
+	}
+	if vlan == nil {
+		return false
+	}
+
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "vlan", strconv.Itoa(int(vlan.ID))))
+	m.Name = types.StringValue(vlan.Name)
+	m.Type = types.StringValue(vlan.Type)
+	m.STG = types.Int32Value(vlan.STG)
+	m.ISID = types.Int32Null()
+	if vlan.ISID != 0 {
+		m.ISID = types.Int32Value(vlan.ISID)
+	}
+	m.TaggedPorts = portsToSet(ctx, vlan.TaggedPorts, diags)
+	m.UntaggedPorts = portsToSet(ctx, vlan.UntaggedPorts, diags)
+	return true
+}
+
+// vlan returns the VLAN described by m.
+func (m *FabricEngineVLANModel) vlan(ctx context.Context, diags *diag.Diagnostics) transport.VLAN {
+	return transport.VLAN{
+		ID:            m.VLANID.ValueInt32(),
+		Name:          m.Name.ValueString(),
+		Type:          m.Type.ValueString(),
+		STG:           m.STG.ValueInt32(),
+		ISID:          m.ISID.ValueInt32(),
+		TaggedPorts:   portsFromSet(ctx, m.TaggedPorts, diags),
+		UntaggedPorts: portsFromSet(ctx, m.UntaggedPorts, diags),
+	}
+}
@@ -0,0 +1,378 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/importid"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ resource.ResourceWithImportState = &FabricEngineVRFResource{}
+var _ resource.ResourceWithValidateConfig = &FabricEngineVRFResource{}
+var _ resource.ResourceWithModifyPlan = &FabricEngineVRFResource{}
+
+// vrfNameRe matches the names accepted by "ip vrf".
+var vrfNameRe = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,16}$`)
+
+// FabricEngineVRFResource implements resource.Resource.
+type FabricEngineVRFResource struct {
+	client *ExtrmFabricEngineClient
+}
+
+// NewFabricEngineVRFResource returns a new instance of the resource.
+func NewFabricEngineVRFResource() resource.Resource {
+	return &FabricEngineVRFResource{}
+}
+
+// FabricEngineVRFModel describes the resource model used in Terraform state.
+type FabricEngineVRFModel struct {
+	ID                 types.String `tfsdk:"id"`
+	Device             types.String `tfsdk:"device"`
+	Name               types.String `tfsdk:"name"`
+	VRFID              types.Int32  `tfsdk:"vrf_id"`
+	ISID               types.Int32  `tfsdk:"i_sid"`
+	IPVPNEnabled       types.Bool   `tfsdk:"ipvpn_enabled"`
+	RedistributeDirect types.Bool   `tfsdk:"redistribute_direct"`
+	RedistributeStatic types.Bool   `tfsdk:"redistribute_static"`
+}
+
+func (r *FabricEngineVRFResource) Metadata(
+	ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+
+	resp.TypeName = req.ProviderTypeName + "_vrf"
+}
+
+func (r *FabricEngineVRFResource) Schema(
+	ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a VRF (`ip vrf <name> vrfid <id>`) and, when `i_sid` is set, its L3 VSN: " +
+			"`ipvpn`, `i-sid` and `ipvpn enable` in the `router vrf` context, with the redistribution of its " +
+			"direct and static routes into IS-IS. A VRF cannot be destroyed while interfaces are attached to it.",
+		Attributes: map[string]schema.Attribute{
+			"id":     schema.StringAttribute{Computed: true},
+			"device": deviceAttribute(),
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the VRF, up to 16 letters, digits, `_`, `.` or `-`.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:          []validator.String{stringMatches(vrfNameRe, "up to 16 letters, digits, _, . or -")},
+			},
+			"vrf_id": schema.Int32Attribute{
+				MarkdownDescription: "ID of the VRF, from 1 to 511.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.Int32{int32planmodifier.RequiresReplace()},
+				Validators:          []validator.Int32{int32Between(1, 511)},
+			},
+			"i_sid": schema.Int32Attribute{
+				MarkdownDescription: "I-SID of the L3 VSN, from 1 to 15999999. Unset for a VRF without IP VPN.",
+				Optional:            true,
+				Validators:          []validator.Int32{int32Between(1, 15999999)},
+			},
+			"ipvpn_enabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether IP VPN is enabled with `ipvpn enable`. Defaults to true when `i_sid` is set.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"redistribute_direct": schema.BoolAttribute{
+				MarkdownDescription: "Whether the direct routes of the VRF are redistributed into IS-IS. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"redistribute_static": schema.BoolAttribute{
+				MarkdownDescription: "Whether the static routes of the VRF are redistributed into IS-IS. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that IP VPN is only enabled with an I-SID.
+func (r *FabricEngineVRFResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+
+	var config FabricEngineVRFModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.ISID.IsNull() && config.IPVPNEnabled.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("ipvpn_enabled"), "Missing attribute",
+			"i_sid is required to enable IP VPN.")
+	}
+}
+
+// ModifyPlan defaults ipvpn_enabled to whether i_sid is set, and warns when a
+// VRF planned for destruction or replacement still has interfaces. It is only
+// a warning since the interfaces may be destroyed earlier in the same run;
+// Delete refuses the VRF if they are still attached by then.
+func (r *FabricEngineVRFResource) ModifyPlan(
+	ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+
+	var plan, state FabricEngineVRFModel
+	if !req.Plan.Raw.IsNull() {
+		diags := req.Plan.Get(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if plan.IPVPNEnabled.IsUnknown() && !plan.ISID.IsUnknown() {
+			plan.IPVPNEnabled = types.BoolValue(!plan.ISID.IsNull())
+			diags = resp.Plan.SetAttribute(ctx, path.Root("ipvpn_enabled"), plan.IPVPNEnabled)
+			resp.Diagnostics.Append(diags...)
+		}
+	}
+	if req.State.Raw.IsNull() || r.client == nil {
+		return
+	}
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !req.Plan.Raw.IsNull() && plan.Name.Equal(state.Name) && plan.VRFID.Equal(state.VRFID) &&
+		plan.Device.Equal(state.Device) {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+	vrf, err := device.SSH.VRF(ctx, state.Name.ValueString())
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read VRF", err)
+		return
+	}
+	if vrf != nil && vrf.Interfaces > 0 {
+		resp.Diagnostics.AddWarning("VRF has interfaces",
+			fmt.Sprintf("VRF %s has interfaces attached to it (%d found) and cannot be deleted until they are "+
+				"moved to another VRF or their IP configuration is removed. The apply fails unless they are "+
+				"destroyed before the VRF in the same run.", vrf.Name, vrf.Interfaces))
+	}
+}
+
+// Configure retrieves the provider data (SSH client) and assigns it to the resource.
+func (r *FabricEngineVRFResource) Configure(
+	ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*ExtrmFabricEngineClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected client type", "The provider did not return a valid client")
+		return
+	}
+	r.client = c
+}
+
+// Create creates the VRF and its L3 VSN.
+func (r *FabricEngineVRFResource) Create(
+	ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+
+	var plan FabricEngineVRFModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.CreateVRF(ctx, plan.vrf()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to create VRF", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("VRF not found",
+			fmt.Sprintf("VRF %s does not exist on the device after being created.", plan.Name.ValueString()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the VRF from "show ip vrf", "show ip ipvpn" and "show ip isis redistribute".
+func (r *FabricEngineVRFResource) Read(
+	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+
+	var state FabricEngineVRFModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if !r.refresh(ctx, device, &state, &resp.Diagnostics) {
+		// The VRF was deleted outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes the L3 VSN of the VRF.
+func (r *FabricEngineVRFResource) Update(
+	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+
+	var plan FabricEngineVRFModel
+	var state FabricEngineVRFModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(plan.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	if err := device.SSH.UpdateVRF(ctx, state.vrf(), plan.vrf()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to update VRF", err)
+		return
+	}
+
+	if !r.refresh(ctx, device, &plan, &resp.Diagnostics) {
+		resp.Diagnostics.AddError("VRF not found",
+			fmt.Sprintf("VRF %s does not exist on the device after being configured.", plan.Name.ValueString()))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the VRF, which also removes its L3 VSN. A VRF that still has
+// interfaces is refused with an explicit error; interfaces destroyed in the
+// same run are already gone by then.
+func (r *FabricEngineVRFResource) Delete(
+	ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+
+	var state FabricEngineVRFModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device := r.client.requireDevice(state.Device, &resp.Diagnostics)
+	if device == nil {
+		return
+	}
+
+	vrf, err := device.SSH.VRF(ctx, state.Name.ValueString())
+	if err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to read VRF", err)
+		return
+	}
+	if vrf != nil && vrf.Interfaces > 0 {
+		resp.Diagnostics.AddError("VRF has interfaces",
+			fmt.Sprintf("VRF %s cannot be deleted while interfaces are attached to it (%d found). "+
+				"Move them to another VRF or remove their IP configuration first.", vrf.Name, vrf.Interfaces))
+		return
+	}
+
+	if err := device.SSH.DeleteVRF(ctx, state.Name.ValueString()); err != nil {
+		addCommandError(&resp.Diagnostics, "Unable to delete VRF", err)
+		return
+	}
+
+	// Remove the resource from Terraform state.
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState adopts the VRF named by the import ID, "[<device>:]vrf/<name>".
+func (r *FabricEngineVRFResource) ImportState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+
+	id, err := importid.Parse(req.ID, "vrf")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	if !vrfNameRe.MatchString(id.Key) {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("%q is not a VRF name", id.Key))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id.String())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), id.Key)...)
+	if id.Device != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("device"), id.Device)...)
+	}
+}
+
+// refresh reads the VRF from the device into m. It returns false if the VRF
+// does not exist.
+func (r *FabricEngineVRFResource) refresh(
+	ctx context.Context, device *Device, m *FabricEngineVRFModel, diags *diag.Diagnostics) bool {
+
+	vrf, err := device.SSH.VRF(ctx, m.Name.ValueString())
+	if err != nil {
+		addCommandError(diags, "Unable to read VRF", err)
+		return true
+	}
+	if vrf == nil {
+		return false
+	}
+
+	m.ID = types.StringValue(importid.Format(m.Device.ValueString(), "vrf", vrf.Name))
+	m.VRFID = types.Int32Value(vrf.ID)
+	m.ISID = types.Int32Null()
+	if vrf.ISID != 0 {
+		m.ISID = types.Int32Value(vrf.ISID)
+	}
+	m.IPVPNEnabled = types.BoolValue(vrf.IPVPNEnabled)
+	m.RedistributeDirect = types.BoolValue(vrf.RedistributeDirect)
+	m.RedistributeStatic = types.BoolValue(vrf.RedistributeStatic)
+	return true
+}
+
+// vrf returns the VRF described by m.
+func (m *FabricEngineVRFModel) vrf() transport.VRF {
+	return transport.VRF{
+		Name:               m.Name.ValueString(),
+		ID:                 m.VRFID.ValueInt32(),
+		ISID:               m.ISID.ValueInt32(),
+		IPVPNEnabled:       m.IPVPNEnabled.ValueBool(),
+		RedistributeDirect: m.RedistributeDirect.ValueBool(),
+		RedistributeStatic: m.RedistributeStatic.ValueBool(),
+	}
+}
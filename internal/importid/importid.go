@@ -0,0 +1,108 @@
+// Package importid implements the ID scheme shared by every resource of the
+// provider. The ID stored in state is also the ID accepted by terraform
+// import.
+//
+// Settings that exist once per device, such as the hostname, are identified
+// by the device name alone, Default standing for the device configured by
+// the top-level provider attributes:
+//
+//	default
+//	spine1
+//
+// Other objects are identified by a kind and a key, prefixed with the device
+// name and a colon unless they live on the default device. The key may itself
+// contain slashes:
+//
+//	vlan/10
+//	spine1:vlan/10
+//	port/1/1
+//	spine1:mlt/5
+package importid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default is the device name used in IDs for the default device.
+const Default = "default"
+
+// ID is a parsed resource ID.
+type ID struct {
+	// Device is the device name, empty for the default device.
+	Device string
+	// Kind is the object type, e.g. "vlan" or "port".
+	Kind string
+	// Key identifies the object among those of its kind, e.g. "10" or "1/1".
+	Key string
+}
+
+// String formats id.
+func (id ID) String() string {
+	if id.Kind == "" {
+		return Singleton(id.Device)
+	}
+	s := id.Kind + "/" + id.Key
+	if id.Device != "" {
+		s = id.Device + ":" + s
+	}
+	return s
+}
+
+// Parts returns the slash separated components of the key.
+func (id ID) Parts() []string {
+	return strings.Split(id.Key, "/")
+}
+
+// Singleton returns the ID of a per-device setting on device, which is empty
+// for the default device.
+func Singleton(device string) string {
+	if device == "" {
+		return Default
+	}
+	return device
+}
+
+// Format returns the ID of the object of the given kind and key on device.
+func Format(device, kind, key string) string {
+	return ID{Device: device, Kind: kind, Key: key}.String()
+}
+
+// ParseSingleton returns the device name encoded in the ID of a per-device
+// setting, empty for the default device.
+func ParseSingleton(s string) (string, error) {
+	if s == "" || strings.ContainsAny(s, ":/") {
+		return "", fmt.Errorf("invalid ID %q: expected a device name, or %q for the default device", s, Default)
+	}
+	if s == Default {
+		return "", nil
+	}
+	return s, nil
+}
+
+// Parse parses the ID of an object whose kind must be one of kinds.
+func Parse(s string, kinds ...string) (ID, error) {
+	var id ID
+	rest := s
+	if device, after, ok := strings.Cut(s, ":"); ok {
+		if device == "" {
+			return id, fmt.Errorf("invalid ID %q: empty device name", s)
+		}
+		if device != Default {
+			id.Device = device
+		}
+		rest = after
+	}
+
+	kind, key, ok := strings.Cut(rest, "/")
+	if !ok || key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return id, fmt.Errorf("invalid ID %q: expected [<device>:]<%s>/<key>", s, strings.Join(kinds, "|"))
+	}
+	for _, k := range kinds {
+		if k == kind {
+			id.Kind, id.Key = kind, key
+			return id, nil
+		}
+	}
+	return id, fmt.Errorf("invalid ID %q: kind must be one of %s, got %q", s, strings.Join(kinds, ", "), kind)
+}
@@ -0,0 +1,75 @@
+package importid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSingleton(t *testing.T) {
+	for device, want := range map[string]string{"": "default", "spine1": "spine1"} {
+		id := Singleton(device)
+		if id != want {
+			t.Errorf("Singleton(%q) = %q, want %q", device, id, want)
+		}
+		got, err := ParseSingleton(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != device {
+			t.Errorf("ParseSingleton(%q) = %q, want %q", id, got, device)
+		}
+	}
+
+	for _, id := range []string{"", "spine1:vlan/10", "vlan/10"} {
+		if _, err := ParseSingleton(id); err == nil {
+			t.Errorf("ParseSingleton(%q) succeeded", id)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]ID{
+		"vlan/10":         {Kind: "vlan", Key: "10"},
+		"spine1:vlan/10":  {Device: "spine1", Kind: "vlan", Key: "10"},
+		"default:vlan/10": {Kind: "vlan", Key: "10"},
+		"port/1/1":        {Kind: "port", Key: "1/1"},
+		"leaf2:mlt/5":     {Device: "leaf2", Kind: "mlt", Key: "5"},
+	}
+	for s, want := range cases {
+		got, err := Parse(s, "vlan", "port", "mlt")
+		if err != nil {
+			t.Errorf("Parse(%q): %v", s, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Parse(%q) = %+v, want %+v", s, got, want)
+		}
+	}
+
+	for _, s := range []string{"", "vlan", "vlan/", "port/1/", ":vlan/10", "isid/100", "spine1:default"} {
+		if _, err := Parse(s, "vlan", "port", "mlt"); err == nil {
+			t.Errorf("Parse(%q) succeeded", s)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := map[string]ID{
+		"vlan/10":         {Kind: "vlan", Key: "10"},
+		"spine1:port/1/1": {Device: "spine1", Kind: "port", Key: "1/1"},
+		"spine1":          {Device: "spine1"},
+		"default":         {},
+	}
+	for want, id := range cases {
+		if got := id.String(); got != want {
+			t.Errorf("%+v.String() = %q, want %q", id, got, want)
+		}
+	}
+
+	if got := Format("", "port", "1/1"); got != "port/1/1" {
+		t.Errorf("Format = %q", got)
+	}
+	if got := (ID{Kind: "port", Key: "1/1"}).Parts(); !reflect.DeepEqual(got, []string{"1", "1"}) {
+		t.Errorf("Parts = %q", got)
+	}
+}
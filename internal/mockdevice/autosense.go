@@ -0,0 +1,164 @@
+package mockdevice
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// defaultOnboardingISID is the I-SID auto-sense ports place unauthenticated
+// devices in.
+const defaultOnboardingISID = 15999999
+
+// autoSense holds the global I-SIDs of auto-sense ports.
+type autoSense struct {
+	// onboarding is set by "auto-sense onboarding i-sid".
+	onboarding int
+	// voice is set by "auto-sense voice i-sid", its I-SID is 0 if none.
+	voice autoSenseService
+	// data maps the I-SIDs set by "auto-sense data i-sid" to their C-VID.
+	data map[int]int
+}
+
+// autoSenseService is an I-SID and the C-VID it is mapped to on auto-sense
+// ports.
+type autoSenseService struct {
+	isid int
+	cvid int
+}
+
+// isidUser returns the auto-sense setting using isid, if any.
+func (a *autoSense) isidUser(isid int) string {
+	switch {
+	case a.onboarding == isid:
+		return "the auto-sense onboarding service"
+	case a.voice.isid == isid:
+		return "the auto-sense voice service"
+	}
+	if _, ok := a.data[isid]; ok {
+		return "an auto-sense data service"
+	}
+	return ""
+}
+
+// checkCVID rejects cvid if another voice or data service than the one of
+// isid maps it.
+func (a *autoSense) checkCVID(isid, cvid int) error {
+	if a.voice.isid != 0 && a.voice.isid != isid && a.voice.cvid == cvid {
+		return errorf("C-VID %d is already used by the auto-sense voice I-SID %d", cvid, a.voice.isid)
+	}
+	for other, c := range a.data {
+		if other != isid && c == cvid {
+			return errorf("C-VID %d is already used by the auto-sense data I-SID %d", cvid, other)
+		}
+	}
+	return nil
+}
+
+func (a *autoSense) sortedData() []autoSenseService {
+	var data []autoSenseService
+	for isid, cvid := range a.data {
+		data = append(data, autoSenseService{isid: isid, cvid: cvid})
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i].isid < data[j].isid })
+	return data
+}
+
+func init() {
+	// serviceArgs parses the I-SID and C-VID of a voice or data service.
+	// The I-SID must be free unless owned reports it is already the one of
+	// the service.
+	serviceArgs := func(sh *shell, args []string, owned func(isid int) bool) (int, int, error) {
+		isid, err := parseInt(args[0], "I-SID", 1, 15999999)
+		if err != nil {
+			return 0, 0, err
+		}
+		cvid, err := parseInt(args[1], "C-VID", 1, 4094)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !owned(isid) {
+			if user := sh.dev.isidUser(isid); user != "" {
+				return 0, 0, errorf("I-SID %d is already used by %s", isid, user)
+			}
+		}
+		return isid, cvid, sh.dev.autoSense.checkCVID(isid, cvid)
+	}
+
+	registerConfig([]mode{modeConfig}, "auto-sense onboarding i-sid <isid>", func(sh *shell, args []string) (string, error) {
+		isid, err := parseInt(args[0], "I-SID", 1, 15999999)
+		if err != nil {
+			return "", err
+		}
+		if isid != sh.dev.autoSense.onboarding {
+			if user := sh.dev.isidUser(isid); user != "" {
+				return "", errorf("I-SID %d is already used by %s", isid, user)
+			}
+		}
+		sh.dev.autoSense.onboarding = isid
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "auto-sense voice i-sid <isid> c-vid <cvid>", func(sh *shell, args []string) (string, error) {
+		a := &sh.dev.autoSense
+		isid, cvid, err := serviceArgs(sh, args, func(isid int) bool { return isid == a.voice.isid })
+		if err != nil {
+			return "", err
+		}
+		a.voice = autoSenseService{isid: isid, cvid: cvid}
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no auto-sense voice i-sid", func(sh *shell, _ []string) (string, error) {
+		sh.dev.autoSense.voice = autoSenseService{}
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "auto-sense data i-sid <isid> c-vid <cvid>", func(sh *shell, args []string) (string, error) {
+		a := &sh.dev.autoSense
+		isid, cvid, err := serviceArgs(sh, args, func(isid int) bool {
+			_, ok := a.data[isid]
+			return ok
+		})
+		if err != nil {
+			return "", err
+		}
+		a.data[isid] = cvid
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no auto-sense data i-sid <isid>", func(sh *shell, args []string) (string, error) {
+		isid, err := parseInt(args[0], "I-SID", 1, 15999999)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := sh.dev.autoSense.data[isid]; !ok {
+			return "", errorf("I-SID %d is not an auto-sense data I-SID", isid)
+		}
+		delete(sh.dev.autoSense.data, isid)
+		return "", nil
+	})
+
+	register(nil, "show auto-sense", func(sh *shell, _ []string) (string, error) {
+		a := sh.dev.autoSense
+		rows := []string{fmt.Sprintf("%-11s %-10d %s", "onboarding", a.onboarding, "--")}
+		if a.voice.isid != 0 {
+			rows = append(rows, fmt.Sprintf("%-11s %-10d %d", "voice", a.voice.isid, a.voice.cvid))
+		}
+		for _, s := range a.sortedData() {
+			rows = append(rows, fmt.Sprintf("%-11s %-10d %d", "data", s.isid, s.cvid))
+		}
+		return table("Auto-Sense I-SIDs", "TYPE        I-SID      C-VID", rows), nil
+	})
+
+	registerSection(37, "AUTO-SENSE CONFIGURATION", func(d *device) []string {
+		a := d.autoSense
+		var lines []string
+		if a.onboarding != defaultOnboardingISID {
+			lines = append(lines, "auto-sense onboarding i-sid "+strconv.Itoa(a.onboarding))
+		}
+		if a.voice.isid != 0 {
+			lines = append(lines, fmt.Sprintf("auto-sense voice i-sid %d c-vid %d", a.voice.isid, a.voice.cvid))
+		}
+		for _, s := range a.sortedData() {
+			lines = append(lines, fmt.Sprintf("auto-sense data i-sid %d c-vid %d", s.isid, s.cvid))
+		}
+		return lines
+	})
+}
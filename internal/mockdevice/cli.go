@@ -0,0 +1,285 @@
+package mockdevice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// mode is a CLI command mode. Configuration sub-modes, e.g. "config-if", are
+// entered from the global configuration mode and share its "exit" and "end"
+// commands.
+type mode string
+
+const (
+	modeUser       mode = "user"
+	modePrivileged mode = "privileged"
+	modeConfig     mode = "config"
+)
+
+func (m mode) isConfig() bool {
+	return strings.HasPrefix(string(m), string(modeConfig))
+}
+
+// cliError is the message printed by the CLI instead of the output of a
+// rejected command.
+type cliError string
+
+func (e cliError) Error() string { return string(e) }
+
+// Replies of the real CLI to commands it cannot parse.
+const (
+	errInvalid    cliError = "% Invalid input detected at '^' marker."
+	errIncomplete cliError = "% Incomplete command."
+)
+
+// errorf returns the reply of the CLI to a command it parsed but refused.
+func errorf(format string, args ...any) error {
+	return cliError("Error: " + fmt.Sprintf(format, args...))
+}
+
+// command is a CLI command. Its pattern is a list of keywords and
+// placeholders: "<x>" matches a single word and "<x...>" the rest of the line.
+type command struct {
+	modes   []mode
+	pattern []string
+	// changes marks commands modifying the running configuration.
+	changes bool
+	run     func(sh *shell, args []string) (string, error)
+}
+
+// commands is the command table of the CLI, filled by the init functions of
+// the emulated features.
+var commands []command
+
+// register adds a command available in modes, or in every mode if modes is
+// empty.
+func register(modes []mode, pattern string, run func(sh *shell, args []string) (string, error)) {
+	commands = append(commands, command{modes: modes, pattern: strings.Fields(pattern), run: run})
+}
+
+// registerConfig adds a command changing the running configuration.
+func registerConfig(modes []mode, pattern string, run func(sh *shell, args []string) (string, error)) {
+	commands = append(commands, command{modes: modes, pattern: strings.Fields(pattern), changes: true, run: run})
+}
+
+func (c *command) allowed(m mode) bool {
+	if len(c.modes) == 0 {
+		return true
+	}
+	for _, cm := range c.modes {
+		if cm == m {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns the values of the placeholders of c if words is an instance
+// of its pattern. partial is set if words is a strict prefix of the pattern.
+func (c *command) match(words []string) (args []string, ok, partial bool) {
+	for i, p := range c.pattern {
+		if i == len(words) {
+			return nil, false, true
+		}
+		switch {
+		case strings.HasPrefix(p, "<") && strings.HasSuffix(p, "...>"):
+			return append(args, strings.Join(words[i:], " ")), true, false
+		case strings.HasPrefix(p, "<"):
+			args = append(args, words[i])
+		case !strings.EqualFold(p, words[i]):
+			return nil, false, false
+		}
+	}
+	return args, len(words) == len(c.pattern), false
+}
+
+// shell is an interactive CLI session.
+type shell struct {
+	srv  *Server
+	dev  *device
+	mode mode
+	// target is the object configured by the current sub-mode, e.g. the
+	// port list of "interface gigabitEthernet".
+	target string
+	closed bool
+}
+
+func (s *Server) shell(ch ssh.Channel) {
+	defer ch.Close()
+
+	sh := &shell{srv: s, dev: s.dev, mode: modeUser}
+	fmt.Fprintf(ch, "\r\nCopyright(c) 2010-2024 Extreme Networks.\r\nAll Rights Reserved.\r\n\r\n%s", sh.prompt())
+
+	r := bufio.NewReader(ch)
+	for !sh.closed {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		out := sh.exec(line)
+		if sh.closed {
+			return
+		}
+		writeLines(ch, line+"\n"+out)
+		io.WriteString(ch, sh.prompt())
+	}
+}
+
+// prompt returns the prompt of the current mode, e.g. "VSP-8284XSQ:1#".
+func (sh *shell) prompt() string {
+	sh.srv.mu.Lock()
+	host := sh.dev.hostname
+	sh.srv.mu.Unlock()
+
+	switch {
+	case sh.mode == modeUser:
+		return host + ":1>"
+	case sh.mode.isConfig():
+		return host + ":1(" + string(sh.mode) + ")#"
+	default:
+		return host + ":1#"
+	}
+}
+
+// exec runs line and returns what the CLI prints before the next prompt.
+func (sh *shell) exec(line string) string {
+	if line == "" {
+		return ""
+	}
+
+	sh.srv.mu.Lock()
+	defer sh.srv.mu.Unlock()
+	sh.srv.commands = append(sh.srv.commands, line)
+	for prefix, message := range sh.srv.rejects {
+		if strings.HasPrefix(line, prefix) {
+			return message + "\n"
+		}
+	}
+
+	cmdline, filter, _ := strings.Cut(line, " | ")
+	out, err := sh.dispatch(tokenize(cmdline))
+	if err == nil && filter != "" {
+		out, err = pipe(out, filter)
+	}
+	if err != nil {
+		return err.Error() + "\n"
+	}
+	return out
+}
+
+func (sh *shell) dispatch(words []string) (string, error) {
+	if out, ok := sh.navigate(words); ok {
+		return out, nil
+	}
+
+	partial := false
+	for i := range commands {
+		c := &commands[i]
+		if !c.allowed(sh.mode) {
+			continue
+		}
+		args, ok, prefix := c.match(words)
+		if !ok {
+			partial = partial || prefix
+			continue
+		}
+		out, err := c.run(sh, args)
+		if err == nil && c.changes {
+			sh.dev.unsaved = true
+		}
+		return out, err
+	}
+	if partial {
+		return "", errIncomplete
+	}
+	return "", errInvalid
+}
+
+// navigate handles the commands moving between modes.
+func (sh *shell) navigate(words []string) (string, bool) {
+	line := strings.ToLower(strings.Join(words, " "))
+	switch {
+	case line == "enable" && sh.mode == modeUser:
+		sh.mode = modePrivileged
+	case line == "disable" && sh.mode == modePrivileged:
+		sh.mode = modeUser
+	case line == "configure terminal" && sh.mode == modePrivileged:
+		sh.mode = modeConfig
+	case line == "end" && sh.mode.isConfig():
+		sh.mode, sh.target = modePrivileged, ""
+	case line == "exit" && sh.mode == modeConfig:
+		sh.mode = modePrivileged
+	case line == "exit" && sh.mode.isConfig():
+		sh.mode, sh.target = modeConfig, ""
+	case line == "exit", line == "logout":
+		sh.closed = true
+	case line == "terminal more disable", line == "terminal more enable":
+	default:
+		return "", false
+	}
+	return "", true
+}
+
+// tokenize splits line into words. Double quotes group words containing
+// spaces.
+func tokenize(line string) []string {
+	var words []string
+	var word strings.Builder
+	quoted, inWord := false, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case r == ' ' && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// pipe applies an output modifier such as "include SysName" to out.
+func pipe(out, filter string) (string, error) {
+	op, arg, _ := strings.Cut(strings.TrimSpace(filter), " ")
+	if arg == "" {
+		return "", errIncomplete
+	}
+
+	var keep func(line string) bool
+	switch op {
+	case "include":
+		keep = func(line string) bool { return strings.Contains(line, arg) }
+	case "exclude":
+		keep = func(line string) bool { return !strings.Contains(line, arg) }
+	default:
+		return "", errInvalid
+	}
+
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(out, "\n") {
+		if line != "" && keep(strings.TrimSuffix(line, "\n")) {
+			b.WriteString(line)
+		}
+	}
+	return b.String(), nil
+}
+
+// writeLines writes text with the line endings of a terminal.
+func writeLines(w io.Writer, text string) {
+	io.WriteString(w, strings.ReplaceAll(text, "\n", "\r\n"))
+}
@@ -0,0 +1,114 @@
+package mockdevice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultHostname is the system name of a switch with a factory default
+// configuration.
+const DefaultHostname = "VSP-8284XSQ"
+
+// device is the running configuration shared by every session of a server.
+// It is guarded by the server's mutex.
+type device struct {
+	hostname string
+	ports    map[string]*port
+	vlans    map[int]*vlan
+	mlts     map[int]*mlt
+	elans    map[int]*elan
+	vrfs     map[string]*vrf
+	// ipInterfaces are the IP interfaces by name, e.g. "Vlan10".
+	ipInterfaces map[string]*ipInterface
+	// staticRoutes are the static routes by VRF, destination and next hop.
+	staticRoutes map[staticRouteKey]*staticRoute
+	isis         isis
+	vist         vist
+	autoSense    autoSense
+	dvr          dvr
+	// faElements are the Fabric Attach elements discovered by port.
+	faElements map[string]FAElement
+	// unsaved is set by configuration commands and cleared by "save config".
+	unsaved bool
+	// startupConfig is the running configuration as of the last
+	// "save config".
+	startupConfig string
+}
+
+func newDevice() *device {
+	d := &device{
+		hostname:     DefaultHostname,
+		ports:        map[string]*port{},
+		vlans:        map[int]*vlan{},
+		mlts:         map[int]*mlt{},
+		elans:        map[int]*elan{},
+		vrfs:         map[string]*vrf{},
+		ipInterfaces: map[string]*ipInterface{},
+		staticRoutes: map[staticRouteKey]*staticRoute{},
+		isis:         isis{systemID: DefaultSystemID},
+		autoSense:    autoSense{onboarding: defaultOnboardingISID, data: map[int]int{}},
+		faElements:   map[string]FAElement{},
+	}
+	for slot := 1; slot <= slots; slot++ {
+		for num := 1; num <= portsPerSlot; num++ {
+			d.ports[fmt.Sprintf("%d/%d", slot, num)] = newPort()
+		}
+	}
+	def := &vlan{id: 1, name: "Default", typ: vlanTypePort, members: map[string]bool{}}
+	for name := range d.ports {
+		def.members[name] = true
+	}
+	d.vlans[1] = def
+	d.startupConfig = d.runningConfig()
+	return d
+}
+
+// section is a part of "show running-config".
+type section struct {
+	order int
+	title string
+	lines func(d *device) []string
+}
+
+// sections holds the parts of the running configuration, filled by the init
+// functions of the emulated features.
+var sections []section
+
+// registerSection adds a part of the running configuration. Sections are
+// printed by increasing order and skipped when they have no lines.
+func registerSection(order int, title string, lines func(d *device) []string) {
+	sections = append(sections, section{order: order, title: title, lines: lines})
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].order < sections[j].order })
+}
+
+// runningConfig renders the running configuration the way the switch prints
+// it.
+func (d *device) runningConfig() string {
+	var b strings.Builder
+	b.WriteString("config terminal\n")
+	for _, s := range sections {
+		lines := s.lines(d)
+		if len(lines) == 0 {
+			continue
+		}
+		b.WriteString("#\n# " + s.title + "\n#\n")
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("end\n")
+	return b.String()
+}
+
+func init() {
+	register(nil, "show running-config", func(sh *shell, _ []string) (string, error) {
+		return sh.dev.runningConfig(), nil
+	})
+	register([]mode{modePrivileged, modeConfig}, "save config", func(sh *shell, _ []string) (string, error) {
+		sh.dev.unsaved = false
+		sh.dev.startupConfig = sh.dev.runningConfig()
+		return "Save config to file /intflash/config.cfg successful.\n", nil
+	})
+}
@@ -0,0 +1,212 @@
+package mockdevice
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+// DVR roles.
+const (
+	dvrController = "controller"
+	dvrLeaf       = "leaf"
+)
+
+// dvr is the Distributed Virtual Routing configuration of the switch, set by
+// "dvr controller" or "dvr leaf". The DVR settings of the VLAN interfaces are
+// kept with their IP interface, and shown in its running configuration.
+type dvr struct {
+	// role is empty if DVR is not configured.
+	role     string
+	domainID int
+	// injection and injectDefaultRoute are cleared by "no dvr injection" and
+	// "no dvr inject-default-route" on a controller.
+	injection          bool
+	injectDefaultRoute bool
+}
+
+// dvrInterfaces returns the VLAN interfaces with a DVR gateway, in order.
+func (d *device) dvrInterfaces() []*ipInterface {
+	var ifs []*ipInterface
+	for _, i := range d.sortedIPInterfaces() {
+		if i.dvrGateway.IsValid() {
+			ifs = append(ifs, i)
+		}
+	}
+	return ifs
+}
+
+func init() {
+	create := func(role string) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, args []string) (string, error) {
+			id, err := parseInt(args[0], "DVR domain ID", 1, 255)
+			if err != nil {
+				return "", err
+			}
+			switch d := &sh.dev.dvr; {
+			case d.role == role && d.domainID == id:
+				return "", nil
+			case d.role != "":
+				return "", errorf("The switch is already a DVR %s of domain %d, use \"no dvr %s\" first", d.role, d.domainID, d.role)
+			}
+			sh.dev.dvr = dvr{role: role, domainID: id, injection: true, injectDefaultRoute: true}
+			return "", nil
+		}
+	}
+	remove := func(role string) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, _ []string) (string, error) {
+			if sh.dev.dvr.role != role {
+				return "", errorf("The switch is not a DVR %s", role)
+			}
+			if ifs := sh.dev.dvrInterfaces(); len(ifs) > 0 {
+				return "", errorf("DVR is configured on %s, remove it first", ifs[0].name())
+			}
+			sh.dev.dvr = dvr{}
+			return "", nil
+		}
+	}
+	registerConfig([]mode{modeConfig}, "dvr controller <id>", create(dvrController))
+	registerConfig([]mode{modeConfig}, "dvr leaf <id>", create(dvrLeaf))
+	registerConfig([]mode{modeConfig}, "no dvr controller", remove(dvrController))
+	registerConfig([]mode{modeConfig}, "no dvr leaf", remove(dvrLeaf))
+
+	// controller returns a command handler applying fn to the DVR settings of
+	// a controller.
+	controller := func(fn func(d *dvr)) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, _ []string) (string, error) {
+			if sh.dev.dvr.role != dvrController {
+				return "", errorf("This setting is only supported on a DVR controller")
+			}
+			fn(&sh.dev.dvr)
+			return "", nil
+		}
+	}
+	registerConfig([]mode{modeConfig}, "dvr injection", controller(func(d *dvr) { d.injection = true }))
+	registerConfig([]mode{modeConfig}, "no dvr injection", controller(func(d *dvr) { d.injection = false }))
+	registerConfig([]mode{modeConfig}, "dvr inject-default-route", controller(func(d *dvr) { d.injectDefaultRoute = true }))
+	registerConfig([]mode{modeConfig}, "no dvr inject-default-route", controller(func(d *dvr) { d.injectDefaultRoute = false }))
+
+	// vlanInterface returns the IP interface of the VLAN of the current
+	// sub-mode, which DVR requires on a controller.
+	vlanInterface := func(sh *shell) (*ipInterface, error) {
+		if sh.dev.dvr.role != dvrController {
+			return nil, errorf("DVR interfaces are only supported on a DVR controller")
+		}
+		i, ok := sh.dev.ipInterfaces[ipInterfaceName(ipKindVLAN, sh.target)]
+		if !ok || !i.primary.IsValid() {
+			return nil, errorf("Vlan%s has no IPv4 address", sh.target)
+		}
+		return i, nil
+	}
+	vlanMode := []mode{"config-if-vlan"}
+	registerConfig(vlanMode, "dvr gw-ipv4 <ip>", func(sh *shell, args []string) (string, error) {
+		i, err := vlanInterface(sh)
+		if err != nil {
+			return "", err
+		}
+		gw, err := netip.ParseAddr(args[0])
+		if err != nil || !gw.Is4() {
+			return "", errorf("Invalid gateway address %q", args[0])
+		}
+		if !i.primary.Masked().Contains(gw) || i.primary.Addr() == gw {
+			return "", errorf("Gateway %s is not a free address of the subnet %s of %s", gw, i.primary.Masked(), i.name())
+		}
+		if i.dvrEnabled {
+			return "", errorf("DVR is enabled on %s, disable it before changing the gateway", i.name())
+		}
+		i.dvrGateway = gw
+		return "", nil
+	})
+	registerConfig(vlanMode, "no dvr gw-ipv4", func(sh *shell, _ []string) (string, error) {
+		i, err := vlanInterface(sh)
+		if err != nil {
+			return "", err
+		}
+		if i.dvrEnabled {
+			return "", errorf("DVR is enabled on %s, disable it before removing the gateway", i.name())
+		}
+		i.dvrGateway = netip.Addr{}
+		return "", nil
+	})
+	registerConfig(vlanMode, "dvr enable", func(sh *shell, _ []string) (string, error) {
+		i, err := vlanInterface(sh)
+		if err != nil {
+			return "", err
+		}
+		if !i.dvrGateway.IsValid() {
+			return "", errorf("%s has no DVR gateway, configure dvr gw-ipv4 first", i.name())
+		}
+		if v, _ := strconv.Atoi(i.id); sh.dev.vlans[v] == nil || sh.dev.vlans[v].isid == 0 {
+			return "", errorf("DVR requires VLAN %s to be mapped to an I-SID", i.id)
+		}
+		i.dvrEnabled = true
+		return "", nil
+	})
+	registerConfig(vlanMode, "no dvr enable", func(sh *shell, _ []string) (string, error) {
+		i, err := vlanInterface(sh)
+		if err != nil {
+			return "", err
+		}
+		i.dvrEnabled = false
+		return "", nil
+	})
+
+	register(nil, "show dvr", func(sh *shell, _ []string) (string, error) {
+		d := sh.dev.dvr
+		role, domain, injection, defaultRoute := "None", "--", "--", "--"
+		status := func(b bool) string {
+			if b {
+				return "enabled"
+			}
+			return "disabled"
+		}
+		switch d.role {
+		case dvrController:
+			role, domain = "Controller", strconv.Itoa(d.domainID)
+			injection, defaultRoute = status(d.injection), status(d.injectDefaultRoute)
+		case dvrLeaf:
+			role, domain = "Leaf", strconv.Itoa(d.domainID)
+		}
+		rule := "================================================================================"
+		return fmt.Sprintf("%s\n%46s\n%s\n"+
+			"        Role                        : %s\n"+
+			"        Domain ID                   : %s\n"+
+			"        Injection                   : %s\n"+
+			"        Inject Default Route        : %s\n",
+			rule, "DVR Summary Info", rule, role, domain, injection, defaultRoute), nil
+	})
+
+	register(nil, "show dvr interfaces", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		for _, i := range sh.dev.dvrInterfaces() {
+			vrf, admin := globalRouter, "disable"
+			if i.vrf != "" {
+				vrf = i.vrf
+			}
+			if i.dvrEnabled {
+				admin = "enable"
+			}
+			rows = append(rows, fmt.Sprintf("%-10s %-16s %-18s %-16s %s", i.name(), vrf, i.primary, i.dvrGateway, admin))
+		}
+		return table("DVR Interfaces",
+			"INTERFACE  VRF              IP ADDRESS         GW IPV4          DVR\n"+
+				"                                                                ADMIN", rows), nil
+	})
+
+	registerSection(42, "DVR CONFIGURATION", func(d *device) []string {
+		var lines []string
+		switch d.dvr.role {
+		case dvrController:
+			lines = append(lines, "dvr controller "+strconv.Itoa(d.dvr.domainID))
+			if !d.dvr.injection {
+				lines = append(lines, "no dvr injection")
+			}
+			if !d.dvr.injectDefaultRoute {
+				lines = append(lines, "no dvr inject-default-route")
+			}
+		case dvrLeaf:
+			lines = append(lines, "dvr leaf "+strconv.Itoa(d.dvr.domainID))
+		}
+		return lines
+	})
+}
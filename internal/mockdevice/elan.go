@@ -0,0 +1,304 @@
+package mockdevice
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// elan is an ELAN I-SID created with "i-sid <isid> elan", whose Flex UNI
+// endpoints map C-VIDs or untagged traffic of ports and MLTs to the service.
+type elan struct {
+	isid      int
+	name      string
+	endpoints map[endpoint]bool
+}
+
+// endpoint is a Flex UNI endpoint. It is on a port or on an MLT, and cvid is
+// 0 for untagged traffic.
+type endpoint struct {
+	port string
+	mlt  int
+	cvid int
+}
+
+// ifName returns the name of the interface of e in the show commands, e.g.
+// "Port1/1" or "Mlt2".
+func (e endpoint) ifName() string {
+	if e.port != "" {
+		return "Port" + e.port
+	}
+	return "Mlt" + strconv.Itoa(e.mlt)
+}
+
+// key orders endpoints by port, MLT and C-VID.
+func (e endpoint) key() int {
+	k := e.mlt * 10000
+	if e.port != "" {
+		k = -1000000 + portKey(e.port)*10
+	}
+	return k*10000 + e.cvid
+}
+
+// command returns the ELAN sub-mode command adding e.
+func (e endpoint) command() string {
+	var cmd string
+	if e.cvid == 0 {
+		cmd = "untagged-traffic"
+	} else {
+		cmd = fmt.Sprintf("c-vid %d", e.cvid)
+	}
+	if e.port != "" {
+		return cmd + " port " + e.port
+	}
+	return fmt.Sprintf("%s mlt %d", cmd, e.mlt)
+}
+
+func (e *elan) sortedEndpoints() []endpoint {
+	var eps []endpoint
+	for ep := range e.endpoints {
+		eps = append(eps, ep)
+	}
+	sort.Slice(eps, func(i, j int) bool { return eps[i].key() < eps[j].key() })
+	return eps
+}
+
+func (d *device) sortedELANs() []*elan {
+	var elans []*elan
+	for _, e := range d.elans {
+		elans = append(elans, e)
+	}
+	sort.Slice(elans, func(i, j int) bool { return elans[i].isid < elans[j].isid })
+	return elans
+}
+
+// endpointOwner returns the ELAN ep belongs to, if any.
+func (d *device) endpointOwner(ep endpoint) *elan {
+	for _, e := range d.elans {
+		if e.endpoints[ep] {
+			return e
+		}
+	}
+	return nil
+}
+
+// endpoints returns the endpoints of the interfaces given to the Flex UNI
+// commands, "port <ports>" or "mlt <id>", after checking Flex UNI is enabled
+// on them.
+func (d *device) endpoints(kind, list string, cvid int) ([]endpoint, error) {
+	if kind == "mlt" {
+		m, err := d.mltArg(list)
+		if err != nil {
+			return nil, err
+		}
+		if !m.flexUNI {
+			return nil, errorf("Flex UNI is not enabled on MLT %d", m.id)
+		}
+		return []endpoint{{mlt: m.id, cvid: cvid}}, nil
+	}
+	ports, err := d.parsePorts(list)
+	if err != nil {
+		return nil, err
+	}
+	var eps []endpoint
+	for _, name := range ports {
+		if !d.ports[name].flexUNI {
+			return nil, errorf("Flex UNI is not enabled on port %s", name)
+		}
+		eps = append(eps, endpoint{port: name, cvid: cvid})
+	}
+	return eps, nil
+}
+
+// hasEndpoints reports whether any ELAN has an endpoint on the port or MLT.
+func (d *device) hasEndpoints(port string, mlt int) bool {
+	for _, e := range d.elans {
+		for ep := range e.endpoints {
+			if ep.port == port && ep.mlt == mlt {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	elanMode := []mode{"config-elan"}
+
+	registerConfig([]mode{modeConfig}, "i-sid <isid> elan", func(sh *shell, args []string) (string, error) {
+		isid, err := parseInt(args[0], "I-SID", 1, 15999999)
+		if err != nil {
+			return "", err
+		}
+		for _, v := range sh.dev.vlans {
+			if v.isid == isid {
+				return "", errorf("I-SID %d is already mapped to VLAN %d", isid, v.id)
+			}
+		}
+		if _, ok := sh.dev.elans[isid]; !ok {
+			if user := sh.dev.isidUser(isid); user != "" {
+				return "", errorf("I-SID %d is already used by %s", isid, user)
+			}
+			sh.dev.elans[isid] = &elan{isid: isid, name: fmt.Sprintf("ISID-%d", isid), endpoints: map[endpoint]bool{}}
+		}
+		sh.mode, sh.target = "config-elan", strconv.Itoa(isid)
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no i-sid <isid>", func(sh *shell, args []string) (string, error) {
+		isid, err := parseInt(args[0], "I-SID", 1, 15999999)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := sh.dev.elans[isid]; !ok {
+			return "", errorf("I-SID %d does not exist", isid)
+		}
+		delete(sh.dev.elans, isid)
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "i-sid name <isid> <name>", func(sh *shell, args []string) (string, error) {
+		isid, err := parseInt(args[0], "I-SID", 1, 15999999)
+		if err != nil {
+			return "", err
+		}
+		e, ok := sh.dev.elans[isid]
+		if !ok {
+			return "", errorf("I-SID %d does not exist", isid)
+		}
+		e.name = args[1]
+		return "", nil
+	})
+
+	// member returns a command handler adding or removing the endpoints of
+	// the interfaces given after "port" or "mlt".
+	member := func(kind string, tagged, add bool) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, args []string) (string, error) {
+			isid, _ := strconv.Atoi(sh.target)
+			e := sh.dev.elans[isid]
+			cvid := 0
+			if tagged {
+				var err error
+				if cvid, err = parseInt(args[0], "C-VID", 1, 4094); err != nil {
+					return "", err
+				}
+				args = args[1:]
+			}
+			eps, err := sh.dev.endpoints(kind, args[0], cvid)
+			if err != nil {
+				return "", err
+			}
+			for _, ep := range eps {
+				owner := sh.dev.endpointOwner(ep)
+				switch {
+				case add && owner != nil && owner != e:
+					return "", errorf("%s is already used by I-SID %d", ep.command(), owner.isid)
+				case !add && owner != e:
+					return "", errorf("%s is not configured in I-SID %d", ep.command(), e.isid)
+				}
+			}
+			for _, ep := range eps {
+				if add {
+					e.endpoints[ep] = true
+				} else {
+					delete(e.endpoints, ep)
+				}
+			}
+			return "", nil
+		}
+	}
+	for _, kind := range []string{"port", "mlt"} {
+		registerConfig(elanMode, "c-vid <cvid> "+kind+" <if>", member(kind, true, true))
+		registerConfig(elanMode, "no c-vid <cvid> "+kind+" <if>", member(kind, true, false))
+		registerConfig(elanMode, "untagged-traffic "+kind+" <if>", member(kind, false, true))
+		registerConfig(elanMode, "no untagged-traffic "+kind+" <if>", member(kind, false, false))
+	}
+
+	registerConfig([]mode{"config-if", "config-mlt"}, "flex-uni enable", func(sh *shell, _ []string) (string, error) {
+		if sh.mode == "config-if" {
+			ports, _ := sh.dev.parsePorts(sh.target)
+			for _, name := range ports {
+				if vlans := sh.dev.sortedVLANsOf(name); len(vlans) > 0 {
+					return "", errorf("Port %s is a member of VLAN %d, remove it from every VLAN first", name, vlans[0].id)
+				}
+				if sh.dev.ports[name].autoSense {
+					return "", errorf("Port %s has auto-sense enabled", name)
+				}
+			}
+		}
+		return "", sh.eachInterface(func(i *iface) error {
+			i.flexUNI = true
+			return nil
+		})
+	})
+	registerConfig([]mode{"config-if", "config-mlt"}, "no flex-uni enable", func(sh *shell, _ []string) (string, error) {
+		if sh.mode == "config-mlt" {
+			id, _ := strconv.Atoi(sh.target)
+			if sh.dev.hasEndpoints("", id) {
+				return "", errorf("MLT %d is an endpoint of an ELAN I-SID", id)
+			}
+		} else {
+			ports, _ := sh.dev.parsePorts(sh.target)
+			for _, name := range ports {
+				if sh.dev.hasEndpoints(name, 0) {
+					return "", errorf("Port %s is an endpoint of an ELAN I-SID", name)
+				}
+			}
+		}
+		return "", sh.eachInterface(func(i *iface) error {
+			i.flexUNI = false
+			return nil
+		})
+	})
+
+	register(nil, "show i-sid", func(sh *shell, _ []string) (string, error) {
+		// I-SIDs are listed in order, whether they are mapped to a VLAN or
+		// created with "i-sid <isid> elan".
+		rows := map[int]string{}
+		for _, v := range sh.dev.vlans {
+			if v.isid != 0 {
+				rows[v.isid] = fmt.Sprintf("%-10d %-9s %-8d %-10s %s", v.isid, "ELAN", v.id, "CONFIG", fmt.Sprintf("ISID-%d", v.isid))
+			}
+		}
+		for _, e := range sh.dev.elans {
+			rows[e.isid] = fmt.Sprintf("%-10d %-9s %-8s %-10s %s", e.isid, "ELAN", "N/A", "CONFIG", e.name)
+		}
+		var isids []int
+		for isid := range rows {
+			isids = append(isids, isid)
+		}
+		sort.Ints(isids)
+		var sorted []string
+		for _, isid := range isids {
+			sorted = append(sorted, rows[isid])
+		}
+		return table("Isid Info",
+			"ISID       ISID      VLANID   ORIGIN     ISID\n"+
+				"ID         TYPE                          NAME", sorted), nil
+	})
+
+	register(nil, "show i-sid elan", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		for _, e := range sh.dev.sortedELANs() {
+			for _, ep := range e.sortedEndpoints() {
+				cvid := "untag"
+				if ep.cvid != 0 {
+					cvid = strconv.Itoa(ep.cvid)
+				}
+				rows = append(rows, fmt.Sprintf("%-10d %-7s %s", e.isid, cvid, ep.ifName()))
+			}
+		}
+		return table("Isid Elan Info",
+			"ISID       C-VID   INTERFACE", rows), nil
+	})
+
+	registerSection(35, "I-SID CONFIGURATION", func(d *device) []string {
+		var lines []string
+		for _, e := range d.sortedELANs() {
+			lines = append(lines, fmt.Sprintf("i-sid %d elan", e.isid))
+			for _, ep := range e.sortedEndpoints() {
+				lines = append(lines, ep.command())
+			}
+			lines = append(lines, "exit", fmt.Sprintf("i-sid name %d %q", e.isid, e.name))
+		}
+		return lines
+	})
+}
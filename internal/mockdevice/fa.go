@@ -0,0 +1,198 @@
+package mockdevice
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// faInterface holds the Fabric Attach settings of a port or an MLT, set by
+// "fa enable".
+type faInterface struct {
+	// msgAuth is cleared by "no fa message-authentication".
+	msgAuth bool
+	// key is set by "fa authentication-key", empty for the default key.
+	key string
+}
+
+// lines returns the interface commands configuring f.
+func (f *faInterface) lines() []string {
+	if f == nil {
+		return nil
+	}
+	lines := []string{"fa enable"}
+	if !f.msgAuth {
+		lines = append(lines, "no fa message-authentication")
+	}
+	if f.key != "" {
+		lines = append(lines, "fa authentication-key "+f.key)
+	}
+	return lines
+}
+
+// faInterfaces returns the interfaces with Fabric Attach enabled, named as in
+// the INTERFACE column of "show fa interface".
+func (d *device) faInterfaces() ([]string, []*faInterface) {
+	var names []string
+	var fas []*faInterface
+	for _, name := range d.portNames() {
+		if f := d.ports[name].fa; f != nil {
+			names, fas = append(names, "Port"+name), append(fas, f)
+		}
+	}
+	for _, m := range d.sortedMLTs() {
+		if m.fa != nil {
+			names, fas = append(names, "Mlt"+strconv.Itoa(m.id)), append(fas, m.fa)
+		}
+	}
+	return names, fas
+}
+
+// FAElement is a Fabric Attach client or proxy connected to a port, with the
+// I-SID/VLAN assignments it requested.
+type FAElement struct {
+	Port string
+	// Type is the element type advertised by the element, e.g. "proxy" or
+	// "clientWapType1".
+	Type string
+	// VLAN is the management VLAN of the element.
+	VLAN int
+	// SystemID is the Fabric Attach system ID of the element, e.g.
+	// "f4:ce:46:aa:bb:cc:00:00:00:01".
+	SystemID string
+	// Trusted reports whether the element passed message authentication.
+	Trusted     bool
+	Assignments []FAAssignment
+}
+
+// FAAssignment is an I-SID/VLAN binding requested by a Fabric Attach element.
+type FAAssignment struct {
+	ISID int
+	VLAN int
+	// State is "active", "pending" or "rejected".
+	State string
+}
+
+// DiscoverFAElement makes the switch report e as if it had been advertised
+// over LLDP on its port, replacing the element previously discovered there.
+// Fabric Attach must be enabled on the port, directly or by auto-sense.
+func (s *Server) DiscoverFAElement(e FAElement) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.dev.ports[e.Port]
+	if !ok {
+		return fmt.Errorf("port %s does not exist", e.Port)
+	}
+	if p.fa == nil && !p.autoSense {
+		return fmt.Errorf("Fabric Attach is not enabled on port %s", e.Port)
+	}
+	s.dev.faElements[e.Port] = e
+	return nil
+}
+
+// sortedFAElements returns the elements discovered on the ports that still
+// have Fabric Attach enabled, by port.
+func (d *device) sortedFAElements() []FAElement {
+	var elements []FAElement
+	for _, name := range d.portNames() {
+		e, ok := d.faElements[name]
+		if p := d.ports[name]; ok && (p.fa != nil || p.autoSense) {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+func init() {
+	ifModes := []mode{"config-if", "config-mlt"}
+
+	// update returns a command handler applying fn to the Fabric Attach
+	// settings of the selected interfaces.
+	update := func(fn func(f *faInterface, args []string)) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, args []string) (string, error) {
+			return "", sh.eachInterface(func(i *iface) error {
+				if i.fa == nil {
+					return errorf("Fabric Attach is not enabled on this interface")
+				}
+				fn(i.fa, args)
+				return nil
+			})
+		}
+	}
+
+	registerConfig(ifModes, "fa enable", func(sh *shell, _ []string) (string, error) {
+		if sh.mode == "config-if" {
+			err := sh.eachPortChecked(func(name string, p *port) error {
+				if p.autoSense {
+					return errorf("Port %s has auto-sense enabled, which manages Fabric Attach", name)
+				}
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+		return "", sh.eachInterface(func(i *iface) error {
+			if i.fa == nil {
+				i.fa = &faInterface{msgAuth: true}
+			}
+			return nil
+		})
+	})
+	registerConfig(ifModes, "no fa enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachInterface(func(i *iface) error {
+			i.fa = nil
+			return nil
+		})
+	})
+	registerConfig(ifModes, "fa message-authentication", update(func(f *faInterface, _ []string) {
+		f.msgAuth = true
+	}))
+	registerConfig(ifModes, "no fa message-authentication", update(func(f *faInterface, _ []string) {
+		f.msgAuth = false
+	}))
+	registerConfig(ifModes, "fa authentication-key <key>", update(func(f *faInterface, args []string) {
+		f.key = args[0]
+	}))
+	registerConfig(ifModes, "no fa authentication-key", update(func(f *faInterface, _ []string) {
+		f.key = ""
+	}))
+
+	register(nil, "show fa interface", func(sh *shell, _ []string) (string, error) {
+		names, fas := sh.dev.faInterfaces()
+		var rows []string
+		for n, f := range fas {
+			auth := "disabled"
+			if f.msgAuth {
+				auth = "enabled"
+			}
+			rows = append(rows, fmt.Sprintf("%-11s %-9s %-14s %-9s %s", names[n], "enabled", "enabled", auth, "****"))
+		}
+		return table("Fabric Attach Interfaces",
+			"INTERFACE   SERVICE   ADVERTISEMENT  MSG AUTH  MSG AUTH\n"+
+				"            STATUS    STATUS         STATUS    KEY", rows), nil
+	})
+
+	register(nil, "show fa elements", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		for _, e := range sh.dev.sortedFAElements() {
+			state := "untrusted"
+			if e.Trusted {
+				state = "trusted"
+			}
+			rows = append(rows, fmt.Sprintf("%-8s %-24s %-6d %-31s %s", e.Port, e.Type, e.VLAN, e.SystemID, state))
+		}
+		return table("Fabric Attach Element Information",
+			"PORT     TYPE                     VLAN   SYSTEM ID                       STATE", rows), nil
+	})
+
+	register(nil, "show fa assignment", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		for _, e := range sh.dev.sortedFAElements() {
+			for _, a := range e.Assignments {
+				rows = append(rows, fmt.Sprintf("%-8s %-10d %-6d %s", e.Port, a.ISID, a.VLAN, a.State))
+			}
+		}
+		return table("Fabric Attach Assignment Map",
+			"PORT     I-SID      VLAN   STATE", rows), nil
+	})
+}
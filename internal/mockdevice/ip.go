@@ -0,0 +1,487 @@
+package mockdevice
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of IP interfaces.
+const (
+	ipKindVLAN     = "vlan"
+	ipKindBrouter  = "brouter"
+	ipKindLoopback = "loopback"
+)
+
+// ipInterface is the IP configuration of a VLAN, a brouter port or a
+// loopback (CLIP) interface.
+type ipInterface struct {
+	kind string
+	// id is the VLAN ID, the port or the loopback ID.
+	id  string
+	vrf string
+	// primary is the primary IPv4 address, or the subnet given to
+	// "brouter port". It is invalid if the interface has no IPv4 address.
+	primary   netip.Prefix
+	secondary []netip.Prefix
+	ipv6      []netip.Prefix
+	// brouterVLAN is the VLAN ID given to "brouter port".
+	brouterVLAN  int
+	spbMulticast bool
+	// dvrGateway is the DVR gateway set by "dvr gw-ipv4" on a VLAN interface,
+	// and dvrEnabled reports whether "dvr enable" is set.
+	dvrGateway netip.Addr
+	dvrEnabled bool
+	igmp       igmp
+}
+
+// ipInterfaceName returns the name of an IP interface in the show commands,
+// e.g. "Vlan10", "Port1/5" or "Clip1".
+func ipInterfaceName(kind, id string) string {
+	switch kind {
+	case ipKindVLAN:
+		return "Vlan" + id
+	case ipKindBrouter:
+		return "Port" + id
+	default:
+		return "Clip" + id
+	}
+}
+
+func (i *ipInterface) name() string {
+	return ipInterfaceName(i.kind, i.id)
+}
+
+// key orders IP interfaces by kind, then VLAN ID, port or loopback ID.
+func (i *ipInterface) key() int {
+	switch i.kind {
+	case ipKindVLAN:
+		n, _ := strconv.Atoi(i.id)
+		return n
+	case ipKindBrouter:
+		return 10000 + portKey(i.id)
+	default:
+		n, _ := strconv.Atoi(i.id)
+		return 100000 + n
+	}
+}
+
+// hasAddresses reports whether any IPv4 or IPv6 address is configured.
+func (i *ipInterface) hasAddresses() bool {
+	return i.primary.IsValid() || len(i.ipv6) > 0
+}
+
+func (i *ipInterface) prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	if i.primary.IsValid() {
+		prefixes = append(prefixes, i.primary)
+	}
+	return append(append(prefixes, i.secondary...), i.ipv6...)
+}
+
+// command returns the command entering the context of the interface.
+func (i *ipInterface) command() string {
+	switch i.kind {
+	case ipKindVLAN:
+		return "interface vlan " + i.id
+	case ipKindBrouter:
+		return "interface gigabitEthernet " + i.id
+	default:
+		return "interface loopback " + i.id
+	}
+}
+
+// lines returns the commands configuring the interface in its context.
+func (i *ipInterface) lines() []string {
+	var lines []string
+	if i.vrf != "" {
+		lines = append(lines, "vrf "+i.vrf)
+	}
+	switch {
+	case i.kind == ipKindBrouter && i.primary.IsValid():
+		lines = append(lines, fmt.Sprintf("brouter port %s vlan %d subnet %s", i.id, i.brouterVLAN, i.primary))
+	case i.primary.IsValid():
+		lines = append(lines, "ip address "+i.primary.String())
+	}
+	for _, p := range i.secondary {
+		lines = append(lines, "ip address "+p.String()+" secondary")
+	}
+	for _, p := range i.ipv6 {
+		lines = append(lines, "ipv6 interface address "+p.String())
+	}
+	if i.spbMulticast {
+		lines = append(lines, "ip spb-multicast enable")
+	}
+	lines = append(lines, i.igmp.lines()...)
+	if i.dvrGateway.IsValid() {
+		lines = append(lines, "dvr gw-ipv4 "+i.dvrGateway.String())
+	}
+	if i.dvrEnabled {
+		lines = append(lines, "dvr enable")
+	}
+	return lines
+}
+
+func (d *device) sortedIPInterfaces() []*ipInterface {
+	var ifs []*ipInterface
+	for _, i := range d.ipInterfaces {
+		ifs = append(ifs, i)
+	}
+	sort.Slice(ifs, func(a, b int) bool { return ifs[a].key() < ifs[b].key() })
+	return ifs
+}
+
+// vrfInterfaces returns the number of IP interfaces of each kind attached to
+// the VRF, or to the global router if name is empty.
+func (d *device) vrfInterfaces(name string) map[string]int {
+	counts := map[string]int{}
+	for _, i := range d.ipInterfaces {
+		if i.vrf == name {
+			counts[i.kind]++
+		}
+	}
+	return counts
+}
+
+// ipInterface returns the IP interface configured by the current sub-mode,
+// creating it if needed. It is only added to the device by saveIPInterface.
+func (sh *shell) ipInterface() (*ipInterface, error) {
+	kind := ipKindVLAN
+	switch sh.mode {
+	case "config-loopback":
+		kind = ipKindLoopback
+	case "config-if":
+		kind = ipKindBrouter
+		if ports, _ := sh.dev.parsePorts(sh.target); len(ports) != 1 {
+			return nil, errorf("IP interfaces can only be configured on a single port")
+		}
+	}
+	if i, ok := sh.dev.ipInterfaces[ipInterfaceName(kind, sh.target)]; ok {
+		return i, nil
+	}
+	return &ipInterface{kind: kind, id: sh.target}, nil
+}
+
+// saveIPInterface adds i to the device, or removes it once it has no VRF and
+// no addresses left.
+func (d *device) saveIPInterface(i *ipInterface) {
+	if i.vrf == "" && !i.hasAddresses() {
+		delete(d.ipInterfaces, i.name())
+		return
+	}
+	d.ipInterfaces[i.name()] = i
+}
+
+// checkOverlap rejects the addresses of i overlapping with the subnet of
+// another interface of its VRF.
+func (d *device) checkOverlap(i *ipInterface, p netip.Prefix) error {
+	for _, other := range d.ipInterfaces {
+		if other.vrf != i.vrf {
+			continue
+		}
+		for _, q := range other.prefixes() {
+			if q.Overlaps(p) {
+				return errorf("%s overlaps with %s on %s", p, q, other.name())
+			}
+		}
+	}
+	return nil
+}
+
+// parsePrefix parses an address with its prefix length, e.g. "10.0.0.1/24".
+func parsePrefix(s string, ipv6 bool) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(s)
+	if err != nil || p.Addr().Is6() != ipv6 {
+		return netip.Prefix{}, errorf("Invalid address %q", s)
+	}
+	return p, nil
+}
+
+// maskString returns the dotted network mask of an IPv4 prefix length.
+func maskString(bits int) string {
+	mask := uint32(0xffffffff) << (32 - bits)
+	if bits == 0 {
+		mask = 0
+	}
+	return netip.AddrFrom4([4]byte{byte(mask >> 24), byte(mask >> 16), byte(mask >> 8), byte(mask)}).String()
+}
+
+func removePrefix(prefixes []netip.Prefix, addr netip.Addr) ([]netip.Prefix, bool) {
+	for n, p := range prefixes {
+		if p.Addr() == addr {
+			return append(prefixes[:n:n], prefixes[n+1:]...), true
+		}
+	}
+	return prefixes, false
+}
+
+func init() {
+	ifModes := []mode{"config-if-vlan", "config-loopback", "config-if"}
+	ipv4Modes := []mode{"config-if-vlan", "config-loopback"}
+
+	register([]mode{modeConfig}, "interface vlan <id>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vlanArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		sh.mode, sh.target = "config-if-vlan", strconv.Itoa(v.id)
+		return "", nil
+	})
+	register([]mode{modeConfig}, "interface loopback <id>", func(sh *shell, args []string) (string, error) {
+		id, err := parseInt(args[0], "loopback ID", 1, 256)
+		if err != nil {
+			return "", err
+		}
+		sh.mode, sh.target = "config-loopback", strconv.Itoa(id)
+		return "", nil
+	})
+
+	// change returns a command handler applying fn to the IP interface of the
+	// current sub-mode.
+	change := func(fn func(sh *shell, i *ipInterface, args []string) error) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, args []string) (string, error) {
+			i, err := sh.ipInterface()
+			if err != nil {
+				return "", err
+			}
+			if err := fn(sh, i, args); err != nil {
+				return "", err
+			}
+			sh.dev.saveIPInterface(i)
+			return "", nil
+		}
+	}
+
+	registerConfig(ifModes, "vrf <name>", change(func(sh *shell, i *ipInterface, args []string) error {
+		if _, err := sh.dev.vrfArg(args[0]); err != nil {
+			return err
+		}
+		if i.hasAddresses() {
+			return errorf("Remove the IP addresses of %s before changing its VRF", i.name())
+		}
+		i.vrf = args[0]
+		return nil
+	}))
+	registerConfig(ifModes, "no vrf", change(func(sh *shell, i *ipInterface, _ []string) error {
+		if i.hasAddresses() {
+			return errorf("Remove the IP addresses of %s before changing its VRF", i.name())
+		}
+		i.vrf = ""
+		return nil
+	}))
+
+	registerConfig(ipv4Modes, "ip address <prefix>", change(func(sh *shell, i *ipInterface, args []string) error {
+		p, err := parsePrefix(args[0], false)
+		if err != nil {
+			return err
+		}
+		if i.kind == ipKindLoopback && p.Bits() != 32 {
+			return errorf("The address of a loopback interface must have a /32 mask")
+		}
+		if i.primary.IsValid() {
+			return errorf("%s already has the address %s", i.name(), i.primary)
+		}
+		if err := sh.dev.checkOverlap(i, p); err != nil {
+			return err
+		}
+		i.primary = p
+		return nil
+	}))
+	registerConfig([]mode{"config-if-vlan"}, "ip address <prefix> secondary", change(func(sh *shell, i *ipInterface, args []string) error {
+		p, err := parsePrefix(args[0], false)
+		if err != nil {
+			return err
+		}
+		if !i.primary.IsValid() {
+			return errorf("%s has no primary address", i.name())
+		}
+		if err := sh.dev.checkOverlap(i, p); err != nil {
+			return err
+		}
+		i.secondary = append(i.secondary, p)
+		return nil
+	}))
+	registerConfig(ipv4Modes, "no ip address <addr>", change(func(sh *shell, i *ipInterface, args []string) error {
+		addr, err := netip.ParseAddr(args[0])
+		if err != nil {
+			return errorf("Invalid address %q", args[0])
+		}
+		var ok bool
+		if i.secondary, ok = removePrefix(i.secondary, addr); ok {
+			return nil
+		}
+		if !i.primary.IsValid() || i.primary.Addr() != addr {
+			return errorf("%s is not configured on %s", addr, i.name())
+		}
+		if len(i.secondary) > 0 || i.multicast() || i.dvrGateway.IsValid() {
+			return errorf("Remove the secondary addresses, multicast settings and DVR gateway of %s first", i.name())
+		}
+		i.primary = netip.Prefix{}
+		return nil
+	}))
+
+	registerConfig(ifModes, "ipv6 interface address <prefix>", change(func(sh *shell, i *ipInterface, args []string) error {
+		p, err := parsePrefix(args[0], true)
+		if err != nil {
+			return err
+		}
+		if err := sh.dev.checkOverlap(i, p); err != nil {
+			return err
+		}
+		i.ipv6 = append(i.ipv6, p)
+		return nil
+	}))
+	registerConfig(ifModes, "no ipv6 interface address <prefix>", change(func(sh *shell, i *ipInterface, args []string) error {
+		p, err := parsePrefix(args[0], true)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if i.ipv6, ok = removePrefix(i.ipv6, p.Addr()); !ok {
+			return errorf("%s is not configured on %s", p, i.name())
+		}
+		return nil
+	}))
+
+	registerConfig(ipv4Modes, "ip spb-multicast enable", change(func(sh *shell, i *ipInterface, _ []string) error {
+		if !i.primary.IsValid() {
+			return errorf("%s has no IPv4 address", i.name())
+		}
+		if i.kind == ipKindVLAN {
+			if err := sh.dev.checkMulticastScope(i.vrf); err != nil {
+				return err
+			}
+		}
+		i.spbMulticast = true
+		return nil
+	}))
+	registerConfig(ipv4Modes, "no ip spb-multicast enable", change(func(sh *shell, i *ipInterface, _ []string) error {
+		i.spbMulticast = false
+		return nil
+	}))
+
+	registerConfig([]mode{"config-if"}, "brouter port <port> vlan <vid> subnet <prefix>", change(func(sh *shell, i *ipInterface, args []string) error {
+		if args[0] != i.id {
+			return errorf("Port %s is not the port of the interface context", args[0])
+		}
+		vid, err := parseInt(args[1], "VLAN ID", 2, 4059)
+		if err != nil {
+			return err
+		}
+		if _, ok := sh.dev.vlans[vid]; ok {
+			return errorf("VLAN %d already exists, a brouter port needs an unused VLAN ID", vid)
+		}
+		for _, other := range sh.dev.ipInterfaces {
+			if other.kind == ipKindBrouter && other.brouterVLAN == vid && other != i {
+				return errorf("VLAN ID %d is already used by brouter port %s", vid, other.id)
+			}
+		}
+		p, err := parsePrefix(args[2], false)
+		if err != nil {
+			return err
+		}
+		if i.primary.IsValid() {
+			return errorf("Port %s is already a brouter port", i.id)
+		}
+		if err := sh.dev.checkOverlap(i, p); err != nil {
+			return err
+		}
+		i.primary, i.brouterVLAN = p, vid
+		return nil
+	}))
+	registerConfig([]mode{"config-if"}, "no brouter port <port>", change(func(sh *shell, i *ipInterface, args []string) error {
+		if args[0] != i.id || !i.primary.IsValid() {
+			return errorf("Port %s is not a brouter port", args[0])
+		}
+		i.primary, i.brouterVLAN = netip.Prefix{}, 0
+		return nil
+	}))
+
+	// vrfArgOrGlobal returns the VRF named by the argument of the show
+	// commands, "" for the global router.
+	vrfArgOrGlobal := func(sh *shell, name string) (string, error) {
+		if name == globalRouter {
+			return "", nil
+		}
+		_, err := sh.dev.vrfArg(name)
+		return name, err
+	}
+
+	register(nil, "show ip interface vrf <name>", func(sh *shell, args []string) (string, error) {
+		vrf, err := vrfArgOrGlobal(sh, args[0])
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, i := range sh.dev.sortedIPInterfaces() {
+			if i.vrf != vrf || !i.primary.IsValid() {
+				continue
+			}
+			vlan, brouter, spbmc := i.id, "false", "--"
+			switch i.kind {
+			case ipKindBrouter:
+				vlan, brouter = strconv.Itoa(i.brouterVLAN), "true"
+			case ipKindLoopback:
+				vlan, spbmc = "--", "disable"
+				if i.spbMulticast {
+					spbmc = "enable"
+				}
+			}
+			row := func(p netip.Prefix, typ string) string {
+				return fmt.Sprintf("%-15s %-15s %-15s %-9s %-7s %-7s %s",
+					i.name(), p.Addr(), maskString(p.Bits()), typ, vlan, brouter, spbmc)
+			}
+			rows = append(rows, row(i.primary, "primary"))
+			for _, p := range i.secondary {
+				rows = append(rows, row(p, "secondary"))
+			}
+		}
+		return table("IP Interface - VRF "+args[0],
+			"INTERFACE       IP              NET             TYPE      VLAN    BROUTER SPB\n"+
+				"                ADDRESS         MASK                      ID              MULTICAST", rows), nil
+	})
+
+	register(nil, "show ipv6 address interface vrf <name>", func(sh *shell, args []string) (string, error) {
+		vrf, err := vrfArgOrGlobal(sh, args[0])
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, i := range sh.dev.sortedIPInterfaces() {
+			if i.vrf != vrf {
+				continue
+			}
+			id := map[string]string{ipKindVLAN: "V-", ipKindBrouter: "P-", ipKindLoopback: "C-"}[i.kind] + i.id
+			for _, p := range i.ipv6 {
+				rows = append(rows, fmt.Sprintf("%-43s %-14s %-9s %-8s %s", p, id, "UNICAST", "MANUAL", "PREFERRED"))
+			}
+		}
+		return table("Address Information - VRF "+args[0],
+			"IPV6 ADDRESS/PREFIX LENGTH                  VID/BID/TID    TYPE      ORIGIN   STATUS", rows), nil
+	})
+
+	registerSection(45, "IP INTERFACE CONFIGURATION", func(d *device) []string {
+		var lines []string
+		for _, i := range d.sortedIPInterfaces() {
+			lines = append(append(append(lines, i.command()), i.lines()...), "exit")
+		}
+		return lines
+	})
+}
+
+// vrfName returns the name of the VRF of the VLAN interface, GlobalRouter if
+// it has none.
+func (d *device) vrfName(vlan int) string {
+	if i, ok := d.ipInterfaces[ipInterfaceName(ipKindVLAN, strconv.Itoa(vlan))]; ok && i.vrf != "" {
+		return i.vrf
+	}
+	return globalRouter
+}
+
+// vrfSummary formats the interface counts of a VRF for "show ip vrf".
+func vrfSummary(name string, id int, counts map[string]int) string {
+	return strings.TrimSpace(fmt.Sprintf("%-16s %-7d %-8s %-6d %-8d %d",
+		name, id, "disable", counts[ipKindVLAN], counts[ipKindBrouter], counts[ipKindLoopback]))
+}
@@ -0,0 +1,377 @@
+package mockdevice
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSystemID is the IS-IS system ID the switch derives from its base
+// MAC address.
+const DefaultSystemID = "b0ad.aa42.5384"
+
+var (
+	systemIDRe   = regexp.MustCompile(`^[0-9a-f]{4}\.[0-9a-f]{4}\.[0-9a-f]{4}$`)
+	nickNameRe   = regexp.MustCompile(`^[0-9a-f]\.[0-9a-f]{2}\.[0-9a-f]{2}$`)
+	manualAreaRe = regexp.MustCompile(`^[0-9a-f]{2}(\.[0-9a-f]{4}){0,6}$`)
+)
+
+// isis holds the global IS-IS and SPBM configuration.
+type isis struct {
+	// spbm is set by the global "spbm" command.
+	spbm       bool
+	enabled    bool
+	systemID   string
+	manualArea string
+	// instance is set by "spbm 1" in the IS-IS context.
+	instance bool
+	nickName string
+	bvids    []int
+	primary  int
+	// smltPeerSystemID and smltVirtualBMAC are set by
+	// "spbm 1 smlt-peer-system-id" and "spbm 1 smlt-virt-bmac".
+	smltPeerSystemID string
+	smltVirtualBMAC  string
+	// multicast is set by "spbm 1 multicast enable".
+	multicast bool
+}
+
+// checkDisabled rejects the changes the switch only accepts while IS-IS is
+// disabled.
+func (i *isis) checkDisabled() error {
+	if i.enabled {
+		return errorf("Cannot modify this parameter while IS-IS is enabled, use \"no router isis enable\" first")
+	}
+	return nil
+}
+
+func (i *isis) checkInstance() error {
+	if !i.instance {
+		return errorf("SPBM instance 1 does not exist")
+	}
+	return nil
+}
+
+// parseVLANList parses a list of VLAN IDs such as "4051-4052" or "4051,4052".
+func parseVLANList(list string) ([]int, error) {
+	var ids []int
+	for _, item := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		from, err := parseInt(first, "VLAN ID", 1, 4059)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseInt(last, "VLAN ID", from, 4059)
+		if err != nil {
+			return nil, err
+		}
+		for id := from; id <= to; id++ {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// formatVLANList prints VLAN IDs the way the switch does, collapsing
+// consecutive IDs into ranges.
+func formatVLANList(ids []int) string {
+	var items []string
+	for i := 0; i < len(ids); {
+		j := i
+		for j+1 < len(ids) && ids[j+1] == ids[j]+1 {
+			j++
+		}
+		if i == j {
+			items = append(items, strconv.Itoa(ids[i]))
+		} else {
+			items = append(items, fmt.Sprintf("%d-%d", ids[i], ids[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
+
+// isBVID reports whether the VLAN is a B-VID of the SPBM instance.
+func (i *isis) isBVID(id int) bool {
+	for _, bvid := range i.bvids {
+		if bvid == id {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	isisMode := []mode{"config-isis"}
+
+	registerConfig([]mode{modeConfig}, "spbm", func(sh *shell, _ []string) (string, error) {
+		sh.dev.isis.spbm = true
+		return "", nil
+	})
+	register([]mode{modeConfig}, "router isis", func(sh *shell, _ []string) (string, error) {
+		sh.mode = "config-isis"
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "router isis enable", func(sh *shell, _ []string) (string, error) {
+		if sh.dev.isis.manualArea == "" {
+			return "", errorf("IS-IS cannot be enabled without a manual area")
+		}
+		sh.dev.isis.enabled = true
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no router isis enable", func(sh *shell, _ []string) (string, error) {
+		sh.dev.isis.enabled = false
+		return "", nil
+	})
+
+	registerConfig(isisMode, "system-id <id>", func(sh *shell, args []string) (string, error) {
+		if err := sh.dev.isis.checkDisabled(); err != nil {
+			return "", err
+		}
+		if !systemIDRe.MatchString(args[0]) {
+			return "", errorf("Invalid system ID %q, expected xxxx.xxxx.xxxx", args[0])
+		}
+		sh.dev.isis.systemID = args[0]
+		return "", nil
+	})
+	registerConfig(isisMode, "default system-id", func(sh *shell, _ []string) (string, error) {
+		if err := sh.dev.isis.checkDisabled(); err != nil {
+			return "", err
+		}
+		sh.dev.isis.systemID = DefaultSystemID
+		return "", nil
+	})
+
+	registerConfig(isisMode, "manual-area <area>", func(sh *shell, args []string) (string, error) {
+		if err := sh.dev.isis.checkDisabled(); err != nil {
+			return "", err
+		}
+		if !manualAreaRe.MatchString(args[0]) {
+			return "", errorf("Invalid area address %q", args[0])
+		}
+		if sh.dev.isis.manualArea != "" {
+			return "", errorf("Manual area %s is already configured", sh.dev.isis.manualArea)
+		}
+		sh.dev.isis.manualArea = args[0]
+		return "", nil
+	})
+	registerConfig(isisMode, "no manual-area <area>", func(sh *shell, args []string) (string, error) {
+		if err := sh.dev.isis.checkDisabled(); err != nil {
+			return "", err
+		}
+		if sh.dev.isis.manualArea != args[0] {
+			return "", errorf("Manual area %s does not exist", args[0])
+		}
+		sh.dev.isis.manualArea = ""
+		return "", nil
+	})
+
+	registerConfig(isisMode, "spbm 1", func(sh *shell, _ []string) (string, error) {
+		if !sh.dev.isis.spbm {
+			return "", errorf("SPBM is not enabled, use the \"spbm\" command first")
+		}
+		sh.dev.isis.instance = true
+		return "", nil
+	})
+	registerConfig(isisMode, "no spbm 1", func(sh *shell, _ []string) (string, error) {
+		i := &sh.dev.isis
+		if err := i.checkDisabled(); err != nil {
+			return "", err
+		}
+		_, ifs := sh.dev.isisInterfaces()
+		for _, itf := range ifs {
+			if itf.spbm {
+				return "", errorf("SPBM instance 1 is configured on interfaces, remove it with \"no isis spbm 1\" first")
+			}
+		}
+		if i.multicast {
+			return "", errorf("SPB multicast is enabled, use \"no spbm 1 multicast enable\" first")
+		}
+		i.instance, i.nickName, i.bvids, i.primary = false, "", nil, 0
+		i.smltPeerSystemID, i.smltVirtualBMAC = "", ""
+		return "", nil
+	})
+
+	registerConfig(isisMode, "spbm 1 nick-name <nick>", func(sh *shell, args []string) (string, error) {
+		i := &sh.dev.isis
+		if err := i.checkInstance(); err != nil {
+			return "", err
+		}
+		if err := i.checkDisabled(); err != nil {
+			return "", err
+		}
+		if !nickNameRe.MatchString(args[0]) {
+			return "", errorf("Invalid nick-name %q, expected x.xx.xx", args[0])
+		}
+		i.nickName = args[0]
+		return "", nil
+	})
+	registerConfig(isisMode, "no spbm 1 nick-name", func(sh *shell, _ []string) (string, error) {
+		i := &sh.dev.isis
+		if err := i.checkDisabled(); err != nil {
+			return "", err
+		}
+		i.nickName = ""
+		return "", nil
+	})
+
+	bvid := func(sh *shell, list, primary string) (string, error) {
+		i := &sh.dev.isis
+		if err := i.checkInstance(); err != nil {
+			return "", err
+		}
+		if err := i.checkDisabled(); err != nil {
+			return "", err
+		}
+		if len(i.bvids) > 0 {
+			return "", errorf("B-VIDs %s are already configured", formatVLANList(i.bvids))
+		}
+		ids, err := parseVLANList(list)
+		if err != nil {
+			return "", err
+		}
+		if len(ids) > 2 {
+			return "", errorf("At most 2 B-VIDs can be configured")
+		}
+		for _, id := range ids {
+			if v, ok := sh.dev.vlans[id]; !ok || v.typ != vlanTypeBVLAN {
+				return "", errorf("VLAN %d is not an spbm-bvlan VLAN", id)
+			}
+		}
+		p := ids[0]
+		if primary != "" {
+			if p, err = parseInt(primary, "primary B-VID", 1, 4059); err != nil {
+				return "", err
+			}
+		}
+		i.bvids, i.primary = ids, p
+		return "", nil
+	}
+	registerConfig(isisMode, "spbm 1 b-vid <list>", func(sh *shell, args []string) (string, error) {
+		return bvid(sh, args[0], "")
+	})
+	registerConfig(isisMode, "spbm 1 b-vid <list> primary <vid>", func(sh *shell, args []string) (string, error) {
+		return bvid(sh, args[0], args[1])
+	})
+	registerConfig(isisMode, "no spbm 1 b-vid <list>", func(sh *shell, args []string) (string, error) {
+		i := &sh.dev.isis
+		if err := i.checkDisabled(); err != nil {
+			return "", err
+		}
+		i.bvids, i.primary = nil, 0
+		return "", nil
+	})
+
+	register(nil, "show isis", func(sh *shell, _ []string) (string, error) {
+		i := &sh.dev.isis
+		state := "disabled"
+		if i.enabled {
+			state = "enabled"
+		}
+		areas := 0
+		if i.manualArea != "" {
+			areas = 1
+		}
+		rule := strings.Repeat("=", 80)
+		return fmt.Sprintf("%s\n%48s\n%s\n"+
+			"%40s : %s\n%40s : %s\n%40s : %s\n%40s : %s\n%40s : %s\n%40s : %d\n%40s : %d\n",
+			rule, "ISIS General Info", rule,
+			"AdminState", state,
+			"RouterType", "Level 1",
+			"System ID", i.systemID,
+			"Metric", "wide",
+			"Router Name", sh.dev.hostname,
+			"Num of Interfaces", 0,
+			"Num of Area Addresses", areas), nil
+	})
+
+	register(nil, "show isis manual-area", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		if sh.dev.isis.manualArea != "" {
+			rows = append(rows, sh.dev.isis.manualArea)
+		}
+		return table("ISIS Manual Area Address", "AREA ADDRESS", rows), nil
+	})
+
+	register(nil, "show isis spbm", func(sh *shell, _ []string) (string, error) {
+		i := &sh.dev.isis
+		var rows, smlt []string
+		if i.instance {
+			splitBEB, bmac, peer := "--", "--", "--"
+			if i.smltPeerSystemID != "" {
+				splitBEB, peer = "primary", i.smltPeerSystemID
+				if i.systemID > i.smltPeerSystemID {
+					splitBEB = "secondary"
+				}
+			}
+			if i.smltVirtualBMAC != "" {
+				bmac = i.smltVirtualBMAC
+			}
+			smlt = append(smlt, fmt.Sprintf("%-11s %-16s %-19s %s", "1", splitBEB, bmac, peer))
+			bvids, primary, nick := formatVLANList(i.bvids), strconv.Itoa(i.primary), i.nickName
+			if len(i.bvids) == 0 {
+				bvids, primary = "--", "--"
+			}
+			if nick == "" {
+				nick = "--"
+			}
+			multicast := "disable"
+			if i.multicast {
+				multicast = "enable"
+			}
+			rows = append(rows, fmt.Sprintf("%-11s %-12s %-9s %-10s %-6s %-6s %-6s %s",
+				"1", bvids, primary, nick, "disable", "enable", "disable", multicast))
+		}
+		return table("ISIS SPBM Info",
+			"SPBM        B-VID        PRIMARY   NICK       LSDB   IP     IPV6   MULTICAST\n"+
+				"INSTANCE                 VLAN      NAME       TRAP", rows) + "\n" +
+			table("ISIS SPBM SMLT Info",
+				"SPBM        SMLT-SPLIT-BEB   SMLT-VIRTUAL-BMAC   SMLT-PEER-SYSTEM-ID\nINSTANCE", smlt), nil
+	})
+
+	registerSection(40, "ISIS SPBM CONFIGURATION", func(d *device) []string {
+		i := &d.isis
+		var lines []string
+		if i.spbm {
+			lines = append(lines, "spbm")
+		}
+		var isisLines []string
+		if i.systemID != DefaultSystemID {
+			isisLines = append(isisLines, "system-id "+i.systemID)
+		}
+		if i.manualArea != "" {
+			isisLines = append(isisLines, "manual-area "+i.manualArea)
+		}
+		if i.instance {
+			isisLines = append(isisLines, "spbm 1")
+			if i.nickName != "" {
+				isisLines = append(isisLines, "spbm 1 nick-name "+i.nickName)
+			}
+			if len(i.bvids) > 0 {
+				isisLines = append(isisLines, fmt.Sprintf("spbm 1 b-vid %s primary %d", formatVLANList(i.bvids), i.primary))
+			}
+			if i.smltPeerSystemID != "" {
+				isisLines = append(isisLines, "spbm 1 smlt-peer-system-id "+i.smltPeerSystemID)
+			}
+			if i.smltVirtualBMAC != "" {
+				isisLines = append(isisLines, "spbm 1 smlt-virt-bmac "+i.smltVirtualBMAC)
+			}
+			if i.multicast {
+				isisLines = append(isisLines, "spbm 1 multicast enable")
+			}
+		}
+		if len(isisLines) > 0 {
+			lines = append(append(append(lines, "router isis"), isisLines...), "exit")
+		}
+		if i.enabled {
+			lines = append(lines, "router isis enable")
+		}
+		return lines
+	})
+}
@@ -0,0 +1,204 @@
+package mockdevice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// isisInterface holds the IS-IS settings of a port or an MLT.
+type isisInterface struct {
+	spbm      bool
+	enabled   bool
+	metric    int
+	authType  string
+	authKey   string
+	authKeyID int
+}
+
+// lines returns the interface commands configuring i.
+func (i *isisInterface) lines() []string {
+	if i == nil {
+		return nil
+	}
+	lines := []string{"isis"}
+	if i.spbm {
+		lines = append(lines, "isis spbm 1")
+		if i.metric != defaultISISMetric {
+			lines = append(lines, fmt.Sprintf("isis spbm 1 l1-metric %d", i.metric))
+		}
+	}
+	if i.authType != "" {
+		line := fmt.Sprintf("isis hello-auth type %s key %s", i.authType, i.authKey)
+		if i.authKeyID != 0 {
+			line += fmt.Sprintf(" key-id %d", i.authKeyID)
+		}
+		lines = append(lines, line)
+	}
+	if i.enabled {
+		lines = append(lines, "isis enable")
+	}
+	return lines
+}
+
+// defaultISISMetric is the SPBM L1 metric of an interface.
+const defaultISISMetric = 10
+
+// isisInterfaceArg returns the IS-IS settings of the interface, which must
+// have been created with "isis".
+func isisInterfaceArg(i *iface) (*isisInterface, error) {
+	if i.isis == nil {
+		return nil, errorf("IS-IS is not configured on this interface")
+	}
+	return i.isis, nil
+}
+
+// isisInterfaces returns the interfaces with IS-IS configured, named as in
+// the IFIDX column of the show commands.
+func (d *device) isisInterfaces() ([]string, []*isisInterface) {
+	var names []string
+	var ifs []*isisInterface
+	for _, name := range d.portNames() {
+		if i := d.ports[name].isis; i != nil {
+			names, ifs = append(names, "Port"+name), append(ifs, i)
+		}
+	}
+	for _, m := range d.sortedMLTs() {
+		if m.isis != nil {
+			names, ifs = append(names, "Mlt"+strconv.Itoa(m.id)), append(ifs, m.isis)
+		}
+	}
+	return names, ifs
+}
+
+func init() {
+	ifModes := []mode{"config-if", "config-mlt"}
+
+	// update returns a command handler applying fn to the IS-IS settings of
+	// the selected interfaces.
+	update := func(fn func(i *isisInterface, args []string) error) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, args []string) (string, error) {
+			return "", sh.eachInterface(func(i *iface) error {
+				isis, err := isisInterfaceArg(i)
+				if err != nil {
+					return err
+				}
+				return fn(isis, args)
+			})
+		}
+	}
+
+	registerConfig(ifModes, "isis", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachInterface(func(i *iface) error {
+			if i.isis == nil {
+				i.isis = &isisInterface{metric: defaultISISMetric}
+			}
+			return nil
+		})
+	})
+	registerConfig(ifModes, "no isis", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachInterface(func(i *iface) error {
+			if i.isis != nil && i.isis.enabled {
+				return errorf("Disable IS-IS on the interface first")
+			}
+			i.isis = nil
+			return nil
+		})
+	})
+
+	registerConfig(ifModes, "isis spbm 1", func(sh *shell, _ []string) (string, error) {
+		if err := sh.dev.isis.checkInstance(); err != nil {
+			return "", err
+		}
+		return update(func(i *isisInterface, _ []string) error {
+			i.spbm = true
+			return nil
+		})(sh, nil)
+	})
+	registerConfig(ifModes, "no isis spbm 1", update(func(i *isisInterface, _ []string) error {
+		i.spbm, i.metric = false, defaultISISMetric
+		return nil
+	}))
+	registerConfig(ifModes, "isis spbm 1 l1-metric <metric>", update(func(i *isisInterface, args []string) error {
+		if !i.spbm {
+			return errorf("SPBM instance 1 is not configured on this interface")
+		}
+		metric, err := parseInt(args[0], "metric", 1, 16777215)
+		if err != nil {
+			return err
+		}
+		i.metric = metric
+		return nil
+	}))
+
+	registerConfig(ifModes, "isis enable", update(func(i *isisInterface, _ []string) error {
+		i.enabled = true
+		return nil
+	}))
+	registerConfig(ifModes, "no isis enable", update(func(i *isisInterface, _ []string) error {
+		i.enabled = false
+		return nil
+	}))
+
+	auth := func(i *isisInterface, typ, key, keyID string) error {
+		switch typ {
+		case "simple", "hmac-md5", "hmac-sha-256":
+		default:
+			return errorf("Invalid authentication type %q", typ)
+		}
+		id := 0
+		if keyID != "" {
+			var err error
+			if id, err = parseInt(keyID, "key-id", 1, 255); err != nil {
+				return err
+			}
+		}
+		if typ == "hmac-sha-256" && id == 0 {
+			return errorf("hmac-sha-256 authentication requires a key-id")
+		}
+		i.authType, i.authKey, i.authKeyID = typ, key, id
+		return nil
+	}
+	registerConfig(ifModes, "isis hello-auth type <type> key <key>", update(func(i *isisInterface, args []string) error {
+		return auth(i, args[0], args[1], "")
+	}))
+	registerConfig(ifModes, "isis hello-auth type <type> key <key> key-id <id>", update(func(i *isisInterface, args []string) error {
+		return auth(i, args[0], args[1], args[2])
+	}))
+	registerConfig(ifModes, "no isis hello-auth", update(func(i *isisInterface, _ []string) error {
+		i.authType, i.authKey, i.authKeyID = "", "", 0
+		return nil
+	}))
+
+	register(nil, "show isis interface", func(sh *shell, _ []string) (string, error) {
+		names, ifs := sh.dev.isisInterfaces()
+		var rows []string
+		for n, i := range ifs {
+			state, metric := "DOWN", "--"
+			if i.enabled {
+				state = "UP"
+			}
+			if i.spbm {
+				metric = strconv.Itoa(i.metric)
+			}
+			rows = append(rows, fmt.Sprintf("%-11s %-7s %-8s %-9s %-10s %-4d %-7d %s",
+				names[n], "pt-pt", "Level 1", state, state, 0, 0, metric))
+		}
+		return table("ISIS Interfaces",
+			"IFIDX       TYPE    LEVEL    OP-STATE  ADM-STATE  ADJ  UP-ADJ  SPBM-L1-METRIC", rows), nil
+	})
+
+	register(nil, "show isis int-auth", func(sh *shell, _ []string) (string, error) {
+		names, ifs := sh.dev.isisInterfaces()
+		var rows []string
+		for n, i := range ifs {
+			typ, keyID, key := "none", "0", ""
+			if i.authType != "" {
+				typ, keyID, key = i.authType, strconv.Itoa(i.authKeyID), strings.Repeat("*", 8)
+			}
+			rows = append(rows, fmt.Sprintf("%-11s %-13s %-10s %s", names[n], typ, keyID, key))
+		}
+		return table("ISIS Interface Auth",
+			"IFIDX       AUTH-TYPE     AUTH-KEYID AUTH-KEY", rows), nil
+	})
+}
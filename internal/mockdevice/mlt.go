@@ -0,0 +1,315 @@
+package mockdevice
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// mlt is a multi-link trunk.
+type mlt struct {
+	iface
+	id   int
+	name string
+	// tagged is set by "mlt <id> encapsulation dot1q".
+	tagged bool
+	// members are the static member ports added with "mlt <id> member".
+	members map[string]bool
+	// smlt is set by "smlt" in the MLT interface context.
+	smlt bool
+}
+
+// mltArg returns the existing MLT whose ID is s.
+func (d *device) mltArg(s string) (*mlt, error) {
+	id, err := parseInt(s, "MLT ID", 1, 512)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := d.mlts[id]
+	if !ok {
+		return nil, errorf("MLT %d does not exist", id)
+	}
+	return m, nil
+}
+
+func (d *device) sortedMLTs() []*mlt {
+	var mlts []*mlt
+	for _, m := range d.mlts {
+		mlts = append(mlts, m)
+	}
+	sort.Slice(mlts, func(i, j int) bool { return mlts[i].id < mlts[j].id })
+	return mlts
+}
+
+// mltOf returns the MLT the port is a static member of, or nil.
+func (d *device) mltOf(port string) *mlt {
+	for _, m := range d.mlts {
+		if m.members[port] {
+			return m
+		}
+	}
+	return nil
+}
+
+// activeMembers returns the static members of the MLT and, when LACP is
+// enabled on it, the ports aggregated with its LACP key.
+func (d *device) activeMembers(m *mlt) []string {
+	var ports []string
+	for name := range m.members {
+		ports = append(ports, name)
+	}
+	if m.lacp.enabled {
+		for name, p := range d.ports {
+			if p.lacp.enabled && p.lacp.aggregation && p.lacp.key == m.lacp.key {
+				ports = append(ports, name)
+			}
+		}
+	}
+	sortPorts(ports)
+	return ports
+}
+
+func init() {
+	registerConfig([]mode{modeConfig}, "mlt <id>", func(sh *shell, args []string) (string, error) {
+		id, err := parseInt(args[0], "MLT ID", 1, 512)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := sh.dev.mlts[id]; !ok {
+			sh.dev.mlts[id] = &mlt{id: id, name: fmt.Sprintf("MLT-%d", id), members: map[string]bool{}}
+		}
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no mlt <id>", func(sh *shell, args []string) (string, error) {
+		m, err := sh.dev.mltArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		if m.isis != nil {
+			return "", errorf("MLT %d has IS-IS configured", m.id)
+		}
+		if m.flexUNI {
+			return "", errorf("MLT %d has Flex UNI enabled", m.id)
+		}
+		delete(sh.dev.mlts, m.id)
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "mlt <id> name <name>", func(sh *shell, args []string) (string, error) {
+		m, err := sh.dev.mltArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		if len(args[1]) > 20 {
+			return "", errorf("MLT name %q is longer than 20 characters", args[1])
+		}
+		m.name = args[1]
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "mlt <id> encapsulation dot1q", func(sh *shell, args []string) (string, error) {
+		m, err := sh.dev.mltArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		m.tagged = true
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no mlt <id> encapsulation dot1q", func(sh *shell, args []string) (string, error) {
+		m, err := sh.dev.mltArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		m.tagged = false
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "mlt <id> member <ports>", func(sh *shell, args []string) (string, error) {
+		m, err := sh.dev.mltArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		if m.lacp.enabled {
+			return "", errorf("MLT %d has LACP enabled, its members are added with the LACP key", m.id)
+		}
+		ports, err := sh.dev.parsePorts(args[1])
+		if err != nil {
+			return "", err
+		}
+		for _, name := range ports {
+			if other := sh.dev.mltOf(name); other != nil && other != m {
+				return "", errorf("Port %s is already a member of MLT %d", name, other.id)
+			}
+			if sh.dev.ports[name].lacp.enabled {
+				return "", errorf("Port %s has LACP enabled", name)
+			}
+		}
+		for _, name := range ports {
+			m.members[name] = true
+		}
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no mlt <id> member <ports>", func(sh *shell, args []string) (string, error) {
+		m, err := sh.dev.mltArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		ports, err := sh.dev.parsePorts(args[1])
+		if err != nil {
+			return "", err
+		}
+		for _, name := range ports {
+			delete(m.members, name)
+		}
+		return "", nil
+	})
+	register([]mode{modeConfig}, "interface mlt <id>", func(sh *shell, args []string) (string, error) {
+		m, err := sh.dev.mltArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		sh.mode, sh.target = "config-mlt", strconv.Itoa(m.id)
+		return "", nil
+	})
+
+	lacpModes := []mode{"config-if", "config-mlt"}
+	registerConfig(lacpModes, "lacp key <key>", func(sh *shell, args []string) (string, error) {
+		key, err := parseInt(args[0], "LACP key", 1, 512)
+		if err != nil {
+			return "", err
+		}
+		if sh.mode == "config-mlt" {
+			for _, other := range sh.dev.mlts {
+				if other.lacp.key == key && strconv.Itoa(other.id) != sh.target {
+					return "", errorf("LACP key %d is already used by MLT %d", key, other.id)
+				}
+			}
+		}
+		return "", sh.eachInterface(func(i *iface) error {
+			if i.lacp.enabled && i.lacp.key != key {
+				return errorf("Disable LACP before changing the LACP key")
+			}
+			i.lacp.key = key
+			return nil
+		})
+	})
+	registerConfig(lacpModes, "no lacp key", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachInterface(func(i *iface) error {
+			if i.lacp.enabled {
+				return errorf("Disable LACP before removing the LACP key")
+			}
+			i.lacp.key = 0
+			return nil
+		})
+	})
+	registerConfig(lacpModes, "lacp enable", func(sh *shell, _ []string) (string, error) {
+		if sh.mode == "config-mlt" {
+			id, _ := strconv.Atoi(sh.target)
+			if len(sh.dev.mlts[id].members) > 0 {
+				return "", errorf("MLT %d has static members", id)
+			}
+		} else {
+			ports, err := sh.dev.parsePorts(sh.target)
+			if err != nil {
+				return "", err
+			}
+			for _, name := range ports {
+				if m := sh.dev.mltOf(name); m != nil {
+					return "", errorf("Port %s is a static member of MLT %d", name, m.id)
+				}
+			}
+		}
+		return "", sh.eachInterface(func(i *iface) error {
+			if i.lacp.key == 0 {
+				return errorf("Configure the LACP key before enabling LACP")
+			}
+			i.lacp.enabled = true
+			return nil
+		})
+	})
+	registerConfig(lacpModes, "no lacp enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachInterface(func(i *iface) error {
+			i.lacp.enabled = false
+			return nil
+		})
+	})
+	registerConfig([]mode{"config-if"}, "lacp aggregation enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachInterface(func(i *iface) error {
+			i.lacp.aggregation = true
+			return nil
+		})
+	})
+	registerConfig([]mode{"config-if"}, "no lacp aggregation enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachInterface(func(i *iface) error {
+			if i.lacp.enabled {
+				return errorf("Disable LACP before changing the aggregation")
+			}
+			i.lacp.aggregation = false
+			return nil
+		})
+	})
+
+	register(nil, "show mlt", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		for _, m := range sh.dev.sortedMLTs() {
+			typ := "access"
+			if m.tagged {
+				typ = "trunk"
+			}
+			members := formatPorts(sh.dev.activeMembers(m))
+			if members == "" {
+				members = "null"
+			}
+			mltType := "norm"
+			if m.smlt {
+				mltType = "smlt"
+			}
+			rows = append(rows, fmt.Sprintf("%-5d %-7d %-20s %-10s %-7s %-8s %-14s %s",
+				m.id, 6143+m.id, m.name, typ, mltType, mltType, members, "null"))
+		}
+		return table("Mlt Info",
+			"                                   PORT       MLT     MLT      PORT           VLAN\n"+
+				"MLTID IFINDEX NAME                 TYPE       ADMIN   CURRENT  MEMBERS        IDS", rows), nil
+	})
+
+	lacpStatus := func(l lacp) string {
+		if l.enabled {
+			return "enable"
+		}
+		return "disable"
+	}
+	register(nil, "show lacp interface mlt", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		for _, m := range sh.dev.sortedMLTs() {
+			rows = append(rows, fmt.Sprintf("%-6d %-6d %s", m.id, m.lacp.key, lacpStatus(m.lacp)))
+		}
+		return table("Mlt Lacp Info", "MLTID  ADMIN  LACP\n       KEY    STATUS", rows), nil
+	})
+
+	registerSection(25, "MLT CONFIGURATION", func(d *device) []string {
+		var lines []string
+		for _, m := range d.sortedMLTs() {
+			lines = append(lines, "mlt "+strconv.Itoa(m.id))
+			if m.name != fmt.Sprintf("MLT-%d", m.id) {
+				lines = append(lines, fmt.Sprintf("mlt %d name %q", m.id, m.name))
+			}
+			if m.tagged {
+				lines = append(lines, fmt.Sprintf("mlt %d encapsulation dot1q", m.id))
+			}
+			if len(m.members) > 0 {
+				var ports []string
+				for name := range m.members {
+					ports = append(ports, name)
+				}
+				lines = append(lines, fmt.Sprintf("mlt %d member %s", m.id, formatPorts(ports)))
+			}
+		}
+		for _, m := range d.sortedMLTs() {
+			cmds := m.iface.lines()
+			if m.smlt {
+				cmds = append(cmds, "smlt")
+			}
+			if len(cmds) > 0 {
+				lines = append(append(append(lines, "interface mlt "+strconv.Itoa(m.id)), cmds...), "exit")
+			}
+		}
+		return lines
+	})
+}
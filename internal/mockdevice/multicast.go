@@ -0,0 +1,224 @@
+package mockdevice
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+// defaultIGMPVersion is the IGMP version of an interface without
+// "ip igmp version".
+const defaultIGMPVersion = 2
+
+// igmp holds the IGMP settings of a VLAN interface.
+type igmp struct {
+	snooping bool
+	querier  bool
+	// querierAddr is set by "ip igmp snoop-querier-addr".
+	querierAddr netip.Addr
+	// version is 0 for the default version.
+	version int
+}
+
+// lines returns the interface commands configuring g.
+func (g igmp) lines() []string {
+	var lines []string
+	if g.version != 0 && g.version != defaultIGMPVersion {
+		lines = append(lines, "ip igmp version "+strconv.Itoa(g.version))
+	}
+	if g.snooping {
+		lines = append(lines, "ip igmp snooping")
+	}
+	if g.querierAddr.IsValid() {
+		lines = append(lines, "ip igmp snoop-querier-addr "+g.querierAddr.String())
+	}
+	if g.querier {
+		lines = append(lines, "ip igmp snoop-querier")
+	}
+	return lines
+}
+
+// multicast reports whether SPB multicast or any IGMP setting is configured
+// on the interface.
+func (i *ipInterface) multicast() bool {
+	return i.spbMulticast || len(i.igmp.lines()) > 0
+}
+
+// checkMulticastScope rejects SPB multicast on the VLAN interfaces of a VRF
+// without "mvpn enable", or of the global router without
+// "spbm 1 multicast enable".
+func (d *device) checkMulticastScope(vrf string) error {
+	if vrf == "" {
+		if !d.isis.multicast {
+			return errorf("SPB multicast is not enabled, use \"spbm 1 multicast enable\" first")
+		}
+		return nil
+	}
+	if !d.vrfs[vrf].mvpn {
+		return errorf("MVPN is not enabled on VRF %s, use \"mvpn enable\" first", vrf)
+	}
+	return nil
+}
+
+// multicastInterfaces returns the VLAN interfaces of the VRF, "" for the
+// global router, with multicast settings, in order.
+func (d *device) multicastInterfaces(vrf string) []*ipInterface {
+	var ifs []*ipInterface
+	for _, i := range d.sortedIPInterfaces() {
+		if i.kind == ipKindVLAN && i.vrf == vrf && i.multicast() {
+			ifs = append(ifs, i)
+		}
+	}
+	return ifs
+}
+
+func init() {
+	isisMode := []mode{"config-isis"}
+	registerConfig(isisMode, "spbm 1 multicast enable", func(sh *shell, _ []string) (string, error) {
+		if err := sh.dev.isis.checkInstance(); err != nil {
+			return "", err
+		}
+		sh.dev.isis.multicast = true
+		return "", nil
+	})
+	registerConfig(isisMode, "no spbm 1 multicast enable", func(sh *shell, _ []string) (string, error) {
+		for _, v := range sh.dev.sortedVRFs() {
+			if v.mvpn {
+				return "", errorf("MVPN is enabled on VRF %s, use \"no mvpn enable\" first", v.name)
+			}
+		}
+		for _, i := range sh.dev.multicastInterfaces("") {
+			if i.spbMulticast {
+				return "", errorf("SPB multicast is enabled on %s, remove it first", i.name())
+			}
+		}
+		sh.dev.isis.multicast = false
+		return "", nil
+	})
+
+	vrfMode := []mode{"config-vrf"}
+	registerConfig(vrfMode, "mvpn enable", func(sh *shell, _ []string) (string, error) {
+		if !sh.dev.isis.multicast {
+			return "", errorf("SPB multicast is not enabled, use \"spbm 1 multicast enable\" first")
+		}
+		sh.dev.vrfs[sh.target].mvpn = true
+		return "", nil
+	})
+	registerConfig(vrfMode, "no mvpn enable", func(sh *shell, _ []string) (string, error) {
+		for _, i := range sh.dev.multicastInterfaces(sh.target) {
+			if i.spbMulticast {
+				return "", errorf("SPB multicast is enabled on %s, remove it first", i.name())
+			}
+		}
+		sh.dev.vrfs[sh.target].mvpn = false
+		return "", nil
+	})
+
+	// update returns a command handler applying fn to the IGMP settings of
+	// the VLAN interface of the current sub-mode, which needs an IPv4
+	// address.
+	update := func(fn func(g *igmp, args []string) error) func(sh *shell, args []string) (string, error) {
+		return func(sh *shell, args []string) (string, error) {
+			i, ok := sh.dev.ipInterfaces[ipInterfaceName(ipKindVLAN, sh.target)]
+			if !ok || !i.primary.IsValid() {
+				return "", errorf("Vlan%s has no IPv4 address", sh.target)
+			}
+			return "", fn(&i.igmp, args)
+		}
+	}
+	vlanMode := []mode{"config-if-vlan"}
+	registerConfig(vlanMode, "ip igmp snooping", update(func(g *igmp, _ []string) error {
+		g.snooping = true
+		return nil
+	}))
+	registerConfig(vlanMode, "no ip igmp snooping", update(func(g *igmp, _ []string) error {
+		g.snooping = false
+		return nil
+	}))
+	registerConfig(vlanMode, "ip igmp snoop-querier", update(func(g *igmp, _ []string) error {
+		g.querier = true
+		return nil
+	}))
+	registerConfig(vlanMode, "no ip igmp snoop-querier", update(func(g *igmp, _ []string) error {
+		g.querier = false
+		return nil
+	}))
+	registerConfig(vlanMode, "ip igmp snoop-querier-addr <ip>", update(func(g *igmp, args []string) error {
+		addr, err := netip.ParseAddr(args[0])
+		if err != nil || !addr.Is4() {
+			return errorf("Invalid querier address %q", args[0])
+		}
+		g.querierAddr = addr
+		return nil
+	}))
+	registerConfig(vlanMode, "no ip igmp snoop-querier-addr", update(func(g *igmp, _ []string) error {
+		g.querierAddr = netip.Addr{}
+		return nil
+	}))
+	registerConfig(vlanMode, "ip igmp version <version>", update(func(g *igmp, args []string) error {
+		v, err := parseInt(args[0], "IGMP version", 1, 3)
+		if err != nil {
+			return err
+		}
+		g.version = v
+		return nil
+	}))
+
+	register(nil, "show isis spbm multicast", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		if i := &sh.dev.isis; i.instance {
+			multicast := "disable"
+			if i.multicast {
+				multicast = "enable"
+			}
+			rows = append(rows, fmt.Sprintf("%-11s %-11s %d", "1", multicast, 210))
+		}
+		return table("ISIS SPBM Multicast Info",
+			"SPBM        MULTICAST   FWD-CACHE-TIMEOUT(sec)\nINSTANCE", rows), nil
+	})
+
+	register(nil, "show ip vrf mvpn", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		for _, v := range sh.dev.sortedVRFs() {
+			mvpn := "disable"
+			if v.mvpn {
+				mvpn = "enable"
+			}
+			rows = append(rows, fmt.Sprintf("%-16s %-6d %-11s %d", v.name, v.id, mvpn, 210))
+		}
+		return table("VRF MVPN Info",
+			"VRF NAME         VRF ID MVPN        FWD-CACHE-TIMEOUT(sec)", rows), nil
+	})
+
+	showIGMP := func(sh *shell, vrf string) string {
+		var rows []string
+		for _, i := range sh.dev.multicastInterfaces(vrf) {
+			g := i.igmp
+			version, querier, spb := g.version, "0.0.0.0", "disable"
+			if version == 0 {
+				version = defaultIGMPVersion
+			}
+			if g.querierAddr.IsValid() {
+				querier = g.querierAddr.String()
+			}
+			if i.spbMulticast {
+				spb = "enable"
+			}
+			rows = append(rows, fmt.Sprintf("%-9s %-6d %-7s %-5d %-5d %-16s %-8t %-8t %s",
+				i.name(), 125, "activ", version, version, querier, g.snooping, g.querier, spb))
+		}
+		return table("Igmp Interface",
+			"IF        QUERY  STATUS  VERS. OPER  QUERIER          SNOOP    SNOOP    SPB\n"+
+				"          INTVL                VERS.                  ENABLE   QUERIER  MULTICAST", rows)
+	}
+	register(nil, "show ip igmp interface", func(sh *shell, _ []string) (string, error) {
+		return showIGMP(sh, ""), nil
+	})
+	register(nil, "show ip igmp interface vrf <name>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vrfArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		return showIGMP(sh, v.name), nil
+	})
+}
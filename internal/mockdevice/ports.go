@@ -0,0 +1,418 @@
+package mockdevice
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Number of slots of the emulated chassis and ports per slot.
+const (
+	slots        = 2
+	portsPerSlot = 42
+)
+
+// iface holds the settings shared by ports and MLTs.
+type iface struct {
+	// isis is set by the "isis" interface command.
+	isis *isisInterface
+	// flexUNI is set by "flex-uni enable".
+	flexUNI bool
+	lacp    lacp
+	// fa is set by "fa enable".
+	fa *faInterface
+}
+
+// lacp is the LACP configuration of a port or an MLT.
+type lacp struct {
+	// key is set by "lacp key", 0 if none.
+	key     int
+	enabled bool
+	// aggregation is set by "lacp aggregation enable" on a port.
+	aggregation bool
+}
+
+// lines returns the interface commands shared by ports and MLTs.
+func (i *iface) lines() []string {
+	var lines []string
+	if i.lacp.key != 0 {
+		lines = append(lines, "lacp key "+strconv.Itoa(i.lacp.key))
+	}
+	if i.lacp.aggregation {
+		lines = append(lines, "lacp aggregation enable")
+	}
+	if i.lacp.enabled {
+		lines = append(lines, "lacp enable")
+	}
+	if i.flexUNI {
+		lines = append(lines, "flex-uni enable")
+	}
+	lines = append(lines, i.fa.lines()...)
+	return append(lines, i.isis.lines()...)
+}
+
+// port holds the settings of a front panel port.
+type port struct {
+	iface
+	// tagged is set by "encapsulation dot1q".
+	tagged bool
+	// name is set by "name", empty if none.
+	name     string
+	shutdown bool
+	// autoNegotiate is cleared by "no auto-negotiate enable". The speed and
+	// duplex only apply while it is disabled.
+	autoNegotiate bool
+	speed         int
+	duplex        string
+	// defaultVLAN is set by "default-vlan-id".
+	defaultVLAN int
+	// untagDefaultVLAN is set by "untag-port-default-vlan enable".
+	untagDefaultVLAN bool
+	// autoSense is set by "auto-sense enable".
+	autoSense bool
+	// learning is cleared by "spanning-tree mstp learning disable".
+	learning bool
+}
+
+// newPort returns a port with the factory default settings.
+func newPort() *port {
+	return &port{autoNegotiate: true, speed: 10000, duplex: "full", defaultVLAN: 1, learning: true}
+}
+
+// portSpeeds are the speeds accepted by "speed", in Mb/s.
+var portSpeeds = map[string]bool{"10": true, "100": true, "1000": true, "10000": true, "25000": true, "40000": true, "100000": true}
+
+var portRe = regexp.MustCompile(`^(\d+)/(\d+)$`)
+
+// portKey orders ports by slot and port number.
+func portKey(name string) int {
+	m := portRe.FindStringSubmatch(name)
+	slot, _ := strconv.Atoi(m[1])
+	num, _ := strconv.Atoi(m[2])
+	return slot*1000 + num
+}
+
+func sortPorts(ports []string) {
+	sort.Slice(ports, func(i, j int) bool { return portKey(ports[i]) < portKey(ports[j]) })
+}
+
+// parsePorts expands a port list such as "1/1-1/4,2/1" and checks every
+// port exists.
+func (d *device) parsePorts(list string) ([]string, error) {
+	var ports []string
+	for _, item := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		if !portRe.MatchString(first) || !portRe.MatchString(last) {
+			return nil, errorf("Invalid port list %q", list)
+		}
+		from, to := portKey(first), portKey(last)
+		if from/1000 != to/1000 || from > to {
+			return nil, errorf("Invalid port range %q", item)
+		}
+		for k := from; k <= to; k++ {
+			name := fmt.Sprintf("%d/%d", k/1000, k%1000)
+			if _, ok := d.ports[name]; !ok {
+				return nil, errorf("Port %s does not exist", name)
+			}
+			ports = append(ports, name)
+		}
+	}
+	return ports, nil
+}
+
+// formatPorts prints ports the way the switch does, collapsing consecutive
+// ports into ranges.
+func formatPorts(ports []string) string {
+	ports = append([]string(nil), ports...)
+	sortPorts(ports)
+
+	var items []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && portKey(ports[j+1]) == portKey(ports[j])+1 {
+			j++
+		}
+		if i == j {
+			items = append(items, ports[i])
+		} else {
+			items = append(items, ports[i]+"-"+ports[j])
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
+
+// portNames returns the names of the ports of the chassis in order.
+func (d *device) portNames() []string {
+	names := make([]string, 0, len(d.ports))
+	for name := range d.ports {
+		names = append(names, name)
+	}
+	sortPorts(names)
+	return names
+}
+
+func init() {
+	register([]mode{modeConfig}, "interface gigabitEthernet <ports>", func(sh *shell, args []string) (string, error) {
+		if _, err := sh.dev.parsePorts(args[0]); err != nil {
+			return "", err
+		}
+		sh.mode, sh.target = "config-if", args[0]
+		return "", nil
+	})
+
+	registerSection(30, "PORT CONFIGURATION", func(d *device) []string {
+		var lines []string
+		for _, name := range d.portNames() {
+			p := d.ports[name]
+			var cmds []string
+			if p.name != "" {
+				cmds = append(cmds, fmt.Sprintf("name %q", p.name))
+			}
+			if !p.autoNegotiate {
+				cmds = append(cmds, "no auto-negotiate enable", "speed "+strconv.Itoa(p.speed), "duplex "+p.duplex)
+			}
+			if p.tagged {
+				cmds = append(cmds, "encapsulation dot1q")
+			}
+			if p.defaultVLAN != 1 {
+				cmds = append(cmds, "default-vlan-id "+strconv.Itoa(p.defaultVLAN))
+			}
+			if p.untagDefaultVLAN {
+				cmds = append(cmds, "untag-port-default-vlan enable")
+			}
+			if !p.learning {
+				cmds = append(cmds, "spanning-tree mstp learning disable")
+			}
+			cmds = append(cmds, p.iface.lines()...)
+			if p.autoSense {
+				cmds = append(cmds, "auto-sense enable")
+			}
+			if p.shutdown {
+				cmds = append(cmds, "shutdown")
+			}
+			if len(cmds) > 0 {
+				lines = append(append(append(lines, "interface gigabitEthernet "+name), cmds...), "exit")
+			}
+		}
+		return lines
+	})
+
+	registerConfig([]mode{"config-if"}, "encapsulation dot1q", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.tagged = true })
+	})
+	registerConfig([]mode{"config-if"}, "no encapsulation dot1q", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.tagged, p.untagDefaultVLAN = false, false })
+	})
+
+	ifMode := []mode{"config-if"}
+	registerConfig(ifMode, "name <name>", func(sh *shell, args []string) (string, error) {
+		if len(args[0]) > 64 {
+			return "", errorf("Port name %q is longer than 64 characters", args[0])
+		}
+		return "", sh.eachPort(func(p *port) { p.name = args[0] })
+	})
+	registerConfig(ifMode, "no name", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.name = "" })
+	})
+	registerConfig(ifMode, "shutdown", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.shutdown = true })
+	})
+	registerConfig(ifMode, "no shutdown", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.shutdown = false })
+	})
+	registerConfig(ifMode, "auto-negotiate enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.autoNegotiate = true })
+	})
+	registerConfig(ifMode, "no auto-negotiate enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.autoNegotiate = false })
+	})
+	registerConfig(ifMode, "speed <speed>", func(sh *shell, args []string) (string, error) {
+		if !portSpeeds[args[0]] {
+			return "", errorf("Invalid speed %q", args[0])
+		}
+		speed, _ := strconv.Atoi(args[0])
+		return "", sh.eachPortChecked(func(name string, p *port) error {
+			if p.autoNegotiate {
+				return errorf("Port %s has auto-negotiation enabled, disable it before setting the speed", name)
+			}
+			p.speed = speed
+			return nil
+		})
+	})
+	registerConfig(ifMode, "duplex <duplex>", func(sh *shell, args []string) (string, error) {
+		if args[0] != "full" && args[0] != "half" {
+			return "", errorf("Invalid duplex %q", args[0])
+		}
+		return "", sh.eachPortChecked(func(name string, p *port) error {
+			if p.autoNegotiate {
+				return errorf("Port %s has auto-negotiation enabled, disable it before setting the duplex", name)
+			}
+			p.duplex = args[0]
+			return nil
+		})
+	})
+	registerConfig(ifMode, "default-vlan-id <vid>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vlanArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		return "", sh.eachPort(func(p *port) { p.defaultVLAN = v.id })
+	})
+	registerConfig(ifMode, "untag-port-default-vlan enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPortChecked(func(name string, p *port) error {
+			if !p.tagged {
+				return errorf("Port %s is not tagged, configure encapsulation dot1q first", name)
+			}
+			p.untagDefaultVLAN = true
+			return nil
+		})
+	})
+	registerConfig(ifMode, "no untag-port-default-vlan enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.untagDefaultVLAN = false })
+	})
+	registerConfig(ifMode, "auto-sense enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPortChecked(func(name string, p *port) error {
+			if p.flexUNI {
+				return errorf("Port %s has Flex UNI enabled", name)
+			}
+			if m := sh.dev.mltOf(name); m != nil {
+				return errorf("Port %s is a member of MLT %d", name, m.id)
+			}
+			if p.fa != nil {
+				return errorf("Port %s has Fabric Attach enabled, use \"no fa enable\" first", name)
+			}
+			p.autoSense = true
+			return nil
+		})
+	})
+	registerConfig(ifMode, "no auto-sense enable", func(sh *shell, _ []string) (string, error) {
+		return "", sh.eachPort(func(p *port) { p.autoSense = false })
+	})
+	registerConfig(ifMode, "spanning-tree mstp learning <mode>", func(sh *shell, args []string) (string, error) {
+		if args[0] != "enable" && args[0] != "disable" {
+			return "", errorf("Invalid learning mode %q", args[0])
+		}
+		return "", sh.eachPort(func(p *port) { p.learning = args[0] == "enable" })
+	})
+
+	enabled := func(b bool) string {
+		if b {
+			return "enable"
+		}
+		return "disable"
+	}
+	showPorts := func(sh *shell, args []string) ([]string, error) {
+		if len(args) == 1 {
+			return sh.dev.parsePorts(args[0])
+		}
+		return sh.dev.portNames(), nil
+	}
+	showConfig := func(sh *shell, args []string) (string, error) {
+		names, err := showPorts(sh, args)
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, name := range names {
+			p := sh.dev.ports[name]
+			admin := "up"
+			if p.shutdown {
+				admin = "down"
+			}
+			rows = append(rows, fmt.Sprintf("%-8s %-7d %-6s %-8s %-6d %-6s %-7d %-8s %-8s %-8s %-8s %s",
+				name, 127+64*(portKey(name)/1000)+portKey(name)%1000, admin, enabled(p.autoNegotiate), p.speed, p.duplex,
+				p.defaultVLAN, enabled(p.tagged), enabled(p.untagDefaultVLAN), enabled(p.flexUNI), enabled(p.autoSense),
+				enabled(p.learning)))
+		}
+		return table("Port Config",
+			"PORT                     AUTO                   DEFAULT                    UNTAG    FLEX     AUTO     STP\n"+
+				"NUM      IFINDEX ADMIN  NEG      SPEED  DUPLEX VLAN    TAGGING  DEF-VLAN UNI      SENSE    LEARNING", rows), nil
+	}
+	register(nil, "show interfaces gigabitEthernet config", showConfig)
+	register(nil, "show interfaces gigabitEthernet config <ports>", showConfig)
+
+	showName := func(sh *shell, args []string) (string, error) {
+		names, err := showPorts(sh, args)
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, name := range names {
+			p := sh.dev.ports[name]
+			status, speed, vlan := "up", 10000, "Access"
+			if p.shutdown {
+				status = "down"
+			}
+			if !p.autoNegotiate {
+				speed = p.speed
+			}
+			if p.tagged {
+				vlan = "Trunk"
+			}
+			rows = append(rows, fmt.Sprintf("%-8s %-20s %-16s %-10s %-10s %-9d %s",
+				name, p.name, "10GbSFP+", status, p.duplex, speed, vlan))
+		}
+		return table("Port Name",
+			"PORT                                                    OPERATE    OPERATE    OPERATE\n"+
+				"NUM      NAME                 DESCRIPTION      STATUS     DUPLEX     SPEED     VLAN", rows), nil
+	}
+	register(nil, "show interfaces gigabitEthernet name", showName)
+	register(nil, "show interfaces gigabitEthernet name <ports>", showName)
+}
+
+// eachInterface applies fn to the ports or the MLT selected by the current
+// interface sub-mode.
+func (sh *shell) eachInterface(fn func(i *iface) error) error {
+	if sh.mode == "config-mlt" {
+		id, _ := strconv.Atoi(sh.target)
+		return fn(&sh.dev.mlts[id].iface)
+	}
+	ports, err := sh.dev.parsePorts(sh.target)
+	if err != nil {
+		return err
+	}
+	for _, name := range ports {
+		if err := fn(&sh.dev.ports[name].iface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachPort applies fn to the ports selected by "interface gigabitEthernet".
+func (sh *shell) eachPort(fn func(p *port)) error {
+	ports, err := sh.dev.parsePorts(sh.target)
+	if err != nil {
+		return err
+	}
+	for _, name := range ports {
+		fn(sh.dev.ports[name])
+	}
+	return nil
+}
+
+// eachPortChecked applies fn to the ports selected by
+// "interface gigabitEthernet". Every port is checked before any is changed,
+// so fn must return its error before changing p.
+func (sh *shell) eachPortChecked(fn func(name string, p *port) error) error {
+	ports, err := sh.dev.parsePorts(sh.target)
+	if err != nil {
+		return err
+	}
+	for _, name := range ports {
+		check := *sh.dev.ports[name]
+		if err := fn(name, &check); err != nil {
+			return err
+		}
+	}
+	for _, name := range ports {
+		fn(name, sh.dev.ports[name])
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package mockdevice
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// staticRouteKey identifies a static route: a next hop of a destination in a
+// VRF, "" for the global router.
+type staticRouteKey struct {
+	vrf     string
+	dest    netip.Prefix
+	nextHop netip.Addr
+}
+
+// staticRoute is a static route created with "ip route".
+type staticRoute struct {
+	staticRouteKey
+	weight       int
+	preference   int
+	enabled      bool
+	localNextHop bool
+}
+
+// Defaults of a static route created with "ip route ... weight".
+const defaultRoutePreference = 5
+
+// prefix returns the destination, mask and next hop of the route as given to
+// "ip route".
+func (r *staticRoute) prefix() string {
+	return fmt.Sprintf("%s %s %s", r.dest.Addr(), maskString(r.dest.Bits()), r.nextHop)
+}
+
+// lines returns the commands configuring the route.
+func (r *staticRoute) lines() []string {
+	p := r.prefix()
+	lines := []string{fmt.Sprintf("ip route %s weight %d", p, r.weight)}
+	if r.preference != defaultRoutePreference {
+		lines = append(lines, fmt.Sprintf("ip route %s preference %d", p, r.preference))
+	}
+	if !r.enabled {
+		lines = append(lines, "no ip route "+p+" enable")
+	}
+	if !r.localNextHop {
+		lines = append(lines, "no ip route "+p+" local-next-hop enable")
+	}
+	return lines
+}
+
+// active reports whether the route is enabled and, with local-next-hop,
+// whether its next hop is in the subnet of an interface of its VRF.
+func (d *device) active(r *staticRoute) bool {
+	if !r.enabled {
+		return false
+	}
+	if !r.localNextHop {
+		return true
+	}
+	for _, i := range d.ipInterfaces {
+		if i.vrf != r.vrf {
+			continue
+		}
+		for _, p := range i.prefixes() {
+			if p.Contains(r.nextHop) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortedStaticRoutes returns the static routes of the VRF, "" for the global
+// router, by destination then next hop.
+func (d *device) sortedStaticRoutes(vrf string) []*staticRoute {
+	var routes []*staticRoute
+	for _, r := range d.staticRoutes {
+		if r.vrf == vrf {
+			routes = append(routes, r)
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if c := routes[i].dest.Addr().Compare(routes[j].dest.Addr()); c != 0 {
+			return c < 0
+		}
+		if routes[i].dest.Bits() != routes[j].dest.Bits() {
+			return routes[i].dest.Bits() < routes[j].dest.Bits()
+		}
+		return routes[i].nextHop.Less(routes[j].nextHop)
+	})
+	return routes
+}
+
+// routeVRF returns the VRF configured by the current mode: the VRF of the
+// "router vrf" context, "" for the global router.
+func (sh *shell) routeVRF() string {
+	if sh.mode == "config-vrf" {
+		return sh.target
+	}
+	return ""
+}
+
+// parseRouteKey parses the destination, mask and next hop arguments of
+// "ip route" in the VRF of the current mode.
+func (sh *shell) parseRouteKey(args []string) (staticRouteKey, error) {
+	addr, err := netip.ParseAddr(args[0])
+	if err != nil || !addr.Is4() {
+		return staticRouteKey{}, errorf("Invalid destination %q", args[0])
+	}
+	mask, err := netip.ParseAddr(args[1])
+	if err != nil || !mask.Is4() {
+		return staticRouteKey{}, errorf("Invalid mask %q", args[1])
+	}
+	bits := -1
+	for n := 0; n <= 32; n++ {
+		if maskString(n) == mask.String() {
+			bits = n
+		}
+	}
+	if bits < 0 {
+		return staticRouteKey{}, errorf("Invalid mask %q", args[1])
+	}
+	dest := netip.PrefixFrom(addr, bits)
+	if dest.Masked() != dest {
+		return staticRouteKey{}, errorf("%s is not the network address of %s/%d", addr, dest.Masked().Addr(), bits)
+	}
+	nextHop, err := netip.ParseAddr(args[2])
+	if err != nil || !nextHop.Is4() {
+		return staticRouteKey{}, errorf("Invalid next hop %q", args[2])
+	}
+	return staticRouteKey{vrf: sh.routeVRF(), dest: dest, nextHop: nextHop}, nil
+}
+
+// staticRouteArg returns the existing static route named by the destination,
+// mask and next hop arguments.
+func (sh *shell) staticRouteArg(args []string) (*staticRoute, error) {
+	key, err := sh.parseRouteKey(args)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := sh.dev.staticRoutes[key]
+	if !ok {
+		return nil, errorf("Static route %s/%d via %s does not exist", key.dest.Addr(), key.dest.Bits(), key.nextHop)
+	}
+	return r, nil
+}
+
+func init() {
+	routeModes := []mode{modeConfig, "config-vrf"}
+
+	registerConfig(routeModes, "ip route <dest> <mask> <nh> weight <weight>", func(sh *shell, args []string) (string, error) {
+		key, err := sh.parseRouteKey(args)
+		if err != nil {
+			return "", err
+		}
+		weight, err := parseInt(args[3], "weight", 1, 65535)
+		if err != nil {
+			return "", err
+		}
+		if r, ok := sh.dev.staticRoutes[key]; ok {
+			r.weight = weight
+			return "", nil
+		}
+		sh.dev.staticRoutes[key] = &staticRoute{
+			staticRouteKey: key,
+			weight:         weight,
+			preference:     defaultRoutePreference,
+			enabled:        true,
+			localNextHop:   true,
+		}
+		return "", nil
+	})
+	registerConfig(routeModes, "ip route <dest> <mask> <nh> preference <pref>", func(sh *shell, args []string) (string, error) {
+		r, err := sh.staticRouteArg(args)
+		if err != nil {
+			return "", err
+		}
+		if r.preference, err = parseInt(args[3], "preference", 1, 255); err != nil {
+			return "", err
+		}
+		return "", nil
+	})
+	registerConfig(routeModes, "no ip route <dest> <mask> <nh>", func(sh *shell, args []string) (string, error) {
+		r, err := sh.staticRouteArg(args)
+		if err != nil {
+			return "", err
+		}
+		delete(sh.dev.staticRoutes, r.staticRouteKey)
+		return "", nil
+	})
+	for _, flag := range []string{"enable", "local-next-hop enable"} {
+		set := func(r *staticRoute, value bool) {
+			if flag == "enable" {
+				r.enabled = value
+			} else {
+				r.localNextHop = value
+			}
+		}
+		registerConfig(routeModes, "ip route <dest> <mask> <nh> "+flag, func(sh *shell, args []string) (string, error) {
+			r, err := sh.staticRouteArg(args)
+			if err != nil {
+				return "", err
+			}
+			set(r, true)
+			return "", nil
+		})
+		registerConfig(routeModes, "no ip route <dest> <mask> <nh> "+flag, func(sh *shell, args []string) (string, error) {
+			r, err := sh.staticRouteArg(args)
+			if err != nil {
+				return "", err
+			}
+			set(r, false)
+			return "", nil
+		})
+	}
+
+	showStatic := func(sh *shell, vrf string) string {
+		var rows []string
+		for _, r := range sh.dev.sortedStaticRoutes(vrf) {
+			status := "INACTV"
+			if sh.dev.active(r) {
+				status = "ACTIVE"
+			}
+			rows = append(rows, fmt.Sprintf("%-15s %-15s %-15s %-5d %-5d %-7s %-6s %s",
+				r.dest.Addr(), maskString(r.dest.Bits()), r.nextHop, r.weight, r.preference,
+				strings.ToUpper(fmt.Sprint(r.localNextHop)), status, strings.ToUpper(fmt.Sprint(r.enabled))))
+		}
+		name := vrf
+		if name == "" {
+			name = globalRouter
+		}
+		return table("IP Static Route - VRF "+name,
+			"DEST            MASK            NEXT            COST  PREF  LCLNHOP STATUS ENABLE", rows)
+	}
+	register(nil, "show ip route static", func(sh *shell, _ []string) (string, error) {
+		return showStatic(sh, ""), nil
+	})
+	register(nil, "show ip route static vrf <name>", func(sh *shell, args []string) (string, error) {
+		if args[0] == globalRouter {
+			return showStatic(sh, ""), nil
+		}
+		v, err := sh.dev.vrfArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		return showStatic(sh, v.name), nil
+	})
+
+	registerSection(55, "IP STATIC ROUTE CONFIGURATION", func(d *device) []string {
+		var lines []string
+		for _, r := range d.sortedStaticRoutes("") {
+			lines = append(lines, r.lines()...)
+		}
+		for _, v := range d.sortedVRFs() {
+			routes := d.sortedStaticRoutes(v.name)
+			if len(routes) == 0 {
+				continue
+			}
+			lines = append(lines, "router vrf "+v.name)
+			for _, r := range routes {
+				lines = append(lines, r.lines()...)
+			}
+			lines = append(lines, "exit")
+		}
+		return lines
+	})
+}
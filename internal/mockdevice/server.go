@@ -0,0 +1,212 @@
+// Package mockdevice emulates the SSH command line of a Fabric Engine (VOSS)
+// switch, so the provider can be tested without real hardware.
+//
+// A Server accepts any number of SSH connections and shares a single running
+// configuration between them. Each shell starts in user mode and supports the
+// privileged and configuration modes, the show commands of the emulated
+// features and the error replies of the real CLI.
+package mockdevice
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Credentials accepted by the server.
+const (
+	Username = "rwa"
+	Password = "rwa"
+)
+
+// Server is an in-process SSH server emulating a Fabric Engine switch.
+type Server struct {
+	listener net.Listener
+	key      ssh.PublicKey
+	config   *ssh.ServerConfig
+
+	mu       sync.Mutex
+	conns    []net.Conn
+	closed   bool
+	dev      *device
+	commands []string
+	rejects  map[string]string
+}
+
+// New starts a server listening on a random port of the loopback interface.
+func New() (*Server, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating host key: %w", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("generating host key: %w", err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listening: %w", err)
+	}
+
+	s := &Server{
+		listener: l,
+		key:      signer.PublicKey(),
+		dev:      newDevice(),
+		rejects:  map[string]string{},
+	}
+	s.config = &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == Username && string(pass) == Password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("access denied for %q", c.User())
+		},
+	}
+	s.config.AddHostKey(signer)
+
+	go s.accept()
+	return s, nil
+}
+
+// Host returns the address the server listens on.
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the TCP port the server listens on.
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// HostKey returns the host key of the server in authorized_keys format.
+func (s *Server) HostKey() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(s.key)))
+}
+
+// Close stops the server and closes every open connection.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+	return s.listener.Close()
+}
+
+// Commands returns every command line received so far, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Reject makes the server answer every command starting with prefix with
+// message instead of executing it, as the switch does when it refuses a
+// change.
+func (s *Server) Reject(prefix, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejects[prefix] = message
+}
+
+// Unsaved reports whether the running configuration changed since the last
+// "save config".
+func (s *Server) Unsaved() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dev.unsaved
+}
+
+// StartupConfig returns the running configuration as of the last
+// "save config".
+func (s *Server) StartupConfig() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dev.startupConfig
+}
+
+// RunningConfig returns the output of "show running-config".
+func (s *Server) RunningConfig() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dev.runningConfig()
+}
+
+// Exec runs cmds in a new shell in privileged mode, as "enable" would, and
+// returns what the CLI prints for each of them. Tests use it to set up
+// configuration the provider does not manage.
+func (s *Server) Exec(cmds ...string) []string {
+	sh := &shell{srv: s, dev: s.dev, mode: modePrivileged}
+	outputs := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		outputs[i] = sh.exec(cmd)
+	}
+	return outputs
+}
+
+func (s *Server) accept() {
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.Close()
+			return
+		}
+		s.conns = append(s.conns, c)
+		s.mu.Unlock()
+		go s.serve(c)
+	}
+}
+
+func (s *Server) serve(c net.Conn) {
+	conn, chans, reqs, err := ssh.NewServerConn(c, s.config)
+	if err != nil {
+		c.Close()
+		return
+	}
+	defer conn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			_ = nc.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range reqs {
+				switch req.Type {
+				case "pty-req":
+					_ = req.Reply(true, nil)
+				case "shell":
+					_ = req.Reply(true, nil)
+					go s.shell(ch)
+				default:
+					_ = req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
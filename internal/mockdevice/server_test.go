@@ -0,0 +1,170 @@
+package mockdevice
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+func newTestClient(t *testing.T) (*Server, *transport.Client) {
+	t.Helper()
+
+	srv, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	hostKey, err := transport.HostKeyConfig{PinnedKey: srv.HostKey()}.Callback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := transport.NewClient(transport.Config{
+		Host:            srv.Host(),
+		Port:            int32(srv.Port()),
+		Username:        Username,
+		Password:        Password,
+		HostKeyCallback: hostKey,
+	})
+	t.Cleanup(func() { c.Close() })
+	return srv, c
+}
+
+func TestHostname(t *testing.T) {
+	srv, c := newTestClient(t)
+	ctx := context.Background()
+
+	name, err := c.Hostname(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != DefaultHostname {
+		t.Errorf("got hostname %q, want %q", name, DefaultHostname)
+	}
+
+	if err := c.SetHostname(ctx, "LAB-VOSS01"); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := c.Hostname(ctx); name != "LAB-VOSS01" {
+		t.Errorf("got hostname %q after update", name)
+	}
+	if srv.Unsaved() {
+		t.Error("configuration not saved")
+	}
+	if cfg := srv.RunningConfig(); !strings.Contains(cfg, `sys name "LAB-VOSS01"`) {
+		t.Errorf("hostname missing from running-config:\n%s", cfg)
+	}
+
+	if err := c.ResetHostname(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if name := srv.Hostname(); name != DefaultHostname {
+		t.Errorf("got hostname %q after reset", name)
+	}
+}
+
+func TestModes(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	s, err := c.Open(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	steps := []struct {
+		cmd, prompt string
+	}{
+		{"configure terminal", DefaultHostname + ":1(config)#"},
+		{"exit", DefaultHostname + ":1#"},
+		{"disable", DefaultHostname + ":1>"},
+		{"enable", DefaultHostname + ":1#"},
+	}
+	for _, step := range steps {
+		if _, err := s.Exec(ctx, step.cmd); err != nil {
+			t.Fatalf("%s: %v", step.cmd, err)
+		}
+		if s.Prompt() != step.prompt {
+			t.Errorf("got prompt %q after %q, want %q", s.Prompt(), step.cmd, step.prompt)
+		}
+	}
+
+	// Configuration commands are not available in privileged mode.
+	if _, err := s.Exec(ctx, "sys name LAB"); err == nil {
+		t.Error("sys name accepted outside of configuration mode")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv, c := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		cmd, message string
+	}{
+		{"show bogus", "% Invalid input detected at '^' marker."},
+		{"sys name", "% Incomplete command."},
+		{"sys name bad/name", `Error: Invalid system name "bad/name"`},
+		{"sys name LOCKED", "% Permission denied"},
+	}
+	srv.Reject("sys name LOCKED", "% Permission denied")
+	for _, tt := range tests {
+		err := c.Configure(ctx, tt.cmd)
+		var cmdErr *transport.CommandError
+		if !errors.As(err, &cmdErr) {
+			t.Errorf("%s: got %v, want *CommandError", tt.cmd, err)
+			continue
+		}
+		if cmdErr.Message != tt.message {
+			t.Errorf("%s: got message %q, want %q", tt.cmd, cmdErr.Message, tt.message)
+		}
+	}
+	if name := srv.Hostname(); name != DefaultHostname {
+		t.Errorf("hostname changed to %q by a rejected command", name)
+	}
+}
+
+func TestExec(t *testing.T) {
+	srv, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	outputs := srv.Exec("configure terminal", "mlt 2", "mlt 0", "end")
+	if outputs[1] != "" {
+		t.Errorf("mlt 2: got %q", outputs[1])
+	}
+	if !strings.HasPrefix(outputs[2], "Error: ") {
+		t.Errorf("mlt 0: got %q, want an error", outputs[2])
+	}
+	if !strings.Contains(srv.RunningConfig(), "mlt 2\n") {
+		t.Errorf("mlt 2 missing from the running configuration:\n%s", srv.RunningConfig())
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize(`sys name  "core switch" now`)
+	want := []string{"sys", "name", "core switch", "now"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPipe(t *testing.T) {
+	out := "a: 1\nb: 2\na: 3\n"
+	if got, _ := pipe(out, "include a:"); got != "a: 1\na: 3\n" {
+		t.Errorf("include: got %q", got)
+	}
+	if got, _ := pipe(out, "exclude a:"); got != "b: 2\n" {
+		t.Errorf("exclude: got %q", got)
+	}
+	if _, err := pipe(out, "sort"); err == nil {
+		t.Error("incomplete modifier accepted")
+	}
+}
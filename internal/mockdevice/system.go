@@ -0,0 +1,54 @@
+package mockdevice
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// hostnameRe is the set of system names the switch accepts.
+var hostnameRe = regexp.MustCompile(`^[\w.\-]{1,255}$`)
+
+// Hostname returns the system name of the emulated switch.
+func (s *Server) Hostname() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dev.hostname
+}
+
+// SetHostname changes the system name of the emulated switch, as if it had
+// been configured out of band.
+func (s *Server) SetHostname(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dev.hostname = name
+}
+
+func init() {
+	registerConfig([]mode{modeConfig}, "sys name <name>", func(sh *shell, args []string) (string, error) {
+		if !hostnameRe.MatchString(args[0]) {
+			return "", errorf("Invalid system name %q", args[0])
+		}
+		sh.dev.hostname = args[0]
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "default sys name", func(sh *shell, _ []string) (string, error) {
+		sh.dev.hostname = DefaultHostname
+		return "", nil
+	})
+
+	register(nil, "show sys-info", func(sh *shell, _ []string) (string, error) {
+		return fmt.Sprintf("General Info :\n\n"+
+			"\tSysDescr     : VSP-8284XSQ (8.10.0.0)\n"+
+			"\tSysName      : %s\n"+
+			"\tSysUpTime    : 12 day(s), 04:27:09\n"+
+			"\tSysContact   : http://www.extremenetworks.com/contact/\n"+
+			"\tSysLocation  : \n", sh.dev.hostname), nil
+	})
+
+	registerSection(10, "SYSTEM CONFIGURATION", func(d *device) []string {
+		if d.hostname == DefaultHostname {
+			return nil
+		}
+		return []string{fmt.Sprintf("sys name %q", d.hostname)}
+	})
+}
@@ -0,0 +1,116 @@
+package mockdevice
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+)
+
+var bmacRe = regexp.MustCompile(`^[0-9a-f]{2}(:[0-9a-f]{2}){5}$`)
+
+// vist is the virtual IST of an SMLT cluster, configured with
+// "virtual-ist peer-ip".
+type vist struct {
+	peerIP netip.Addr
+	vlan   int
+}
+
+func init() {
+	registerConfig([]mode{modeConfig}, "virtual-ist peer-ip <ip> vlan <vid>", func(sh *shell, args []string) (string, error) {
+		peer, err := netip.ParseAddr(args[0])
+		if err != nil || !peer.Is4() {
+			return "", errorf("Invalid peer IP address %q", args[0])
+		}
+		v, err := sh.dev.vlanArg(args[1])
+		if err != nil {
+			return "", err
+		}
+		if sh.dev.vist.vlan != 0 {
+			if sh.dev.vist.peerIP == peer && sh.dev.vist.vlan == v.id {
+				return "", nil
+			}
+			return "", errorf("Virtual IST is already configured, use \"no virtual-ist peer-ip\" first")
+		}
+		i, ok := sh.dev.ipInterfaces[ipInterfaceName(ipKindVLAN, strconv.Itoa(v.id))]
+		if !ok || i.vrf != "" || !i.primary.IsValid() || !i.primary.Masked().Contains(peer) || i.primary.Addr() == peer {
+			return "", errorf("Peer IP %s is not in the subnet of VLAN %d in the global router", peer, v.id)
+		}
+		sh.dev.vist = vist{peerIP: peer, vlan: v.id}
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no virtual-ist peer-ip", func(sh *shell, _ []string) (string, error) {
+		for _, m := range sh.dev.mlts {
+			if m.smlt {
+				return "", errorf("MLT %d is an SMLT, remove it with \"no smlt\" first", m.id)
+			}
+		}
+		sh.dev.vist = vist{}
+		return "", nil
+	})
+
+	registerConfig([]mode{"config-mlt"}, "smlt", func(sh *shell, _ []string) (string, error) {
+		if sh.dev.vist.vlan == 0 {
+			return "", errorf("Virtual IST is not configured")
+		}
+		id, _ := strconv.Atoi(sh.target)
+		sh.dev.mlts[id].smlt = true
+		return "", nil
+	})
+	registerConfig([]mode{"config-mlt"}, "no smlt", func(sh *shell, _ []string) (string, error) {
+		id, _ := strconv.Atoi(sh.target)
+		sh.dev.mlts[id].smlt = false
+		return "", nil
+	})
+
+	isisMode := []mode{"config-isis"}
+	registerConfig(isisMode, "spbm 1 smlt-peer-system-id <id>", func(sh *shell, args []string) (string, error) {
+		i := &sh.dev.isis
+		if err := i.checkInstance(); err != nil {
+			return "", err
+		}
+		if !systemIDRe.MatchString(args[0]) {
+			return "", errorf("Invalid system ID %q, expected xxxx.xxxx.xxxx", args[0])
+		}
+		if args[0] == i.systemID {
+			return "", errorf("The SMLT peer system ID cannot be the system ID of this switch")
+		}
+		i.smltPeerSystemID = args[0]
+		return "", nil
+	})
+	registerConfig(isisMode, "no spbm 1 smlt-peer-system-id", func(sh *shell, _ []string) (string, error) {
+		sh.dev.isis.smltPeerSystemID = ""
+		return "", nil
+	})
+	registerConfig(isisMode, "spbm 1 smlt-virt-bmac <mac>", func(sh *shell, args []string) (string, error) {
+		i := &sh.dev.isis
+		if err := i.checkInstance(); err != nil {
+			return "", err
+		}
+		if !bmacRe.MatchString(args[0]) {
+			return "", errorf("Invalid MAC address %q, expected xx:xx:xx:xx:xx:xx", args[0])
+		}
+		i.smltVirtualBMAC = args[0]
+		return "", nil
+	})
+	registerConfig(isisMode, "no spbm 1 smlt-virt-bmac", func(sh *shell, _ []string) (string, error) {
+		sh.dev.isis.smltVirtualBMAC = ""
+		return "", nil
+	})
+
+	register(nil, "show virtual-ist", func(sh *shell, _ []string) (string, error) {
+		var rows []string
+		if v := sh.dev.vist; v.vlan != 0 {
+			rows = append(rows, fmt.Sprintf("%-15s %-7d %-7s %s", v.peerIP, v.vlan, "enable", "down"))
+		}
+		return table("IST Info",
+			"PEER-IP         VLAN    ENABLE  IST\nADDRESS         ID      IST     STATUS", rows), nil
+	})
+
+	registerSection(47, "VIRTUAL IST CONFIGURATION", func(d *device) []string {
+		if d.vist.vlan == 0 {
+			return nil
+		}
+		return []string{fmt.Sprintf("virtual-ist peer-ip %s vlan %d", d.vist.peerIP, d.vist.vlan)}
+	})
+}
@@ -0,0 +1,332 @@
+package mockdevice
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VLAN types as given to "vlan create".
+const (
+	vlanTypePort  = "port-mstprstp"
+	vlanTypeBVLAN = "spbm-bvlan"
+)
+
+// bvlanSTG is the spanning tree instance the switch assigns to B-VLANs.
+const bvlanSTG = 62
+
+type vlan struct {
+	id   int
+	name string
+	typ  string
+	stg  int
+	isid int
+	// members are the ports added with "vlan members add".
+	members map[string]bool
+}
+
+// parseInt parses the numeric argument what and checks it is in [min, max].
+func parseInt(s, what string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || n > max {
+		return 0, errorf("Invalid %s %q, the range is %d to %d", what, s, min, max)
+	}
+	return n, nil
+}
+
+// vlanArg returns the existing VLAN whose ID is s.
+func (d *device) vlanArg(s string) (*vlan, error) {
+	id, err := parseInt(s, "VLAN ID", 1, 4059)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := d.vlans[id]
+	if !ok {
+		return nil, errorf("VLAN %d does not exist", id)
+	}
+	return v, nil
+}
+
+func (d *device) createVLAN(id, name, typ, stg string) error {
+	vid, err := parseInt(id, "VLAN ID", 2, 4059)
+	if err != nil {
+		return err
+	}
+	if _, ok := d.vlans[vid]; ok {
+		return errorf("VLAN %d already exists", vid)
+	}
+	v := &vlan{id: vid, name: name, typ: typ, members: map[string]bool{}}
+	if v.name == "" {
+		v.name = fmt.Sprintf("VLAN-%d", vid)
+	}
+	if typ == vlanTypeBVLAN {
+		v.stg = bvlanSTG
+	} else if v.stg, err = parseInt(stg, "STG", 0, 63); err != nil {
+		return err
+	}
+	d.vlans[vid] = v
+	return nil
+}
+
+// sortedVLANs returns the VLANs selected by the optional ID argument of the
+// show commands.
+func (d *device) sortedVLANs(args []string) ([]*vlan, error) {
+	if len(args) == 1 {
+		v, err := d.vlanArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*vlan{v}, nil
+	}
+	var vlans []*vlan
+	for _, v := range d.vlans {
+		vlans = append(vlans, v)
+	}
+	sort.Slice(vlans, func(i, j int) bool { return vlans[i].id < vlans[j].id })
+	return vlans, nil
+}
+
+func (v *vlan) memberList() []string {
+	var ports []string
+	for name := range v.members {
+		ports = append(ports, name)
+	}
+	return ports
+}
+
+// table prints a show command output with the switch's banners.
+func table(title, header string, rows []string) string {
+	rule := strings.Repeat("=", 80)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%*s\n%s\n%s\n%s\n", rule, 40+len(title)/2, title, rule, header, strings.Repeat("-", 80))
+	for _, row := range rows {
+		b.WriteString(row + "\n")
+	}
+	fmt.Fprintf(&b, "\nAll %d out of %d Total Num of Entries displayed\n", len(rows), len(rows))
+	return b.String()
+}
+
+func init() {
+	create := func(sh *shell, id, name, typ, stg string) (string, error) {
+		return "", sh.dev.createVLAN(id, name, typ, stg)
+	}
+	registerConfig([]mode{modeConfig}, "vlan create <id> type port-mstprstp <stg>", func(sh *shell, args []string) (string, error) {
+		return create(sh, args[0], "", vlanTypePort, args[1])
+	})
+	registerConfig([]mode{modeConfig}, "vlan create <id> name <name> type port-mstprstp <stg>", func(sh *shell, args []string) (string, error) {
+		return create(sh, args[0], args[1], vlanTypePort, args[2])
+	})
+	registerConfig([]mode{modeConfig}, "vlan create <id> type spbm-bvlan", func(sh *shell, args []string) (string, error) {
+		return create(sh, args[0], "", vlanTypeBVLAN, "")
+	})
+	registerConfig([]mode{modeConfig}, "vlan create <id> name <name> type spbm-bvlan", func(sh *shell, args []string) (string, error) {
+		return create(sh, args[0], args[1], vlanTypeBVLAN, "")
+	})
+
+	registerConfig([]mode{modeConfig}, "vlan delete <id>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vlanArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		if v.id == 1 {
+			return "", errorf("Cannot delete the default VLAN")
+		}
+		if sh.dev.isis.isBVID(v.id) {
+			return "", errorf("VLAN %d is a B-VID of SPBM instance 1", v.id)
+		}
+		if sh.dev.vist.vlan == v.id {
+			return "", errorf("VLAN %d is the virtual IST VLAN", v.id)
+		}
+		delete(sh.dev.vlans, v.id)
+		delete(sh.dev.ipInterfaces, ipInterfaceName(ipKindVLAN, strconv.Itoa(v.id)))
+		return "", nil
+	})
+
+	registerConfig([]mode{modeConfig}, "vlan name <id> <name>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vlanArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		v.name = args[1]
+		return "", nil
+	})
+
+	registerConfig([]mode{modeConfig}, "vlan i-sid <id> <isid>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vlanArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		isid, err := parseInt(args[1], "I-SID", 1, 15999999)
+		if err != nil {
+			return "", err
+		}
+		if v.isid != 0 {
+			return "", errorf("VLAN %d is already mapped to I-SID %d", v.id, v.isid)
+		}
+		for _, other := range sh.dev.vlans {
+			if other.isid == isid {
+				return "", errorf("I-SID %d is already mapped to VLAN %d", isid, other.id)
+			}
+		}
+		if _, ok := sh.dev.elans[isid]; ok {
+			return "", errorf("I-SID %d is already used by an ELAN service", isid)
+		}
+		if user := sh.dev.isidUser(isid); user != "" {
+			return "", errorf("I-SID %d is already used by %s", isid, user)
+		}
+		v.isid = isid
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no vlan i-sid <id>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vlanArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		v.isid = 0
+		return "", nil
+	})
+
+	members := func(sh *shell, args []string, add bool) (string, error) {
+		v, err := sh.dev.vlanArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		ports, err := sh.dev.parsePorts(args[1])
+		if err != nil {
+			return "", err
+		}
+		for _, name := range ports {
+			if add && sh.dev.ports[name].flexUNI {
+				return "", errorf("Port %s has Flex UNI enabled", name)
+			}
+		}
+		for _, name := range ports {
+			if add {
+				v.members[name] = true
+			} else {
+				delete(v.members, name)
+			}
+		}
+		return "", nil
+	}
+	registerConfig([]mode{modeConfig}, "vlan members add <id> <ports>", func(sh *shell, args []string) (string, error) {
+		return members(sh, args, true)
+	})
+	registerConfig([]mode{modeConfig}, "vlan members remove <id> <ports>", func(sh *shell, args []string) (string, error) {
+		return members(sh, args, false)
+	})
+
+	showBasic := func(sh *shell, args []string) (string, error) {
+		vlans, err := sh.dev.sortedVLANs(args)
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, v := range vlans {
+			typ := "byPort"
+			if v.typ == vlanTypeBVLAN {
+				typ = vlanTypeBVLAN
+			}
+			rows = append(rows, fmt.Sprintf("%-5d %-16s %-16s %-7d %-12s %-15s %-15s %s",
+				v.id, v.name, typ, v.stg, "none", "N/A", "N/A", sh.dev.vrfName(v.id)))
+		}
+		return table("Vlan Basic",
+			"VLAN                                    MSTP\n"+
+				"ID    NAME             TYPE             INST_ID PROTOCOLID   SUBNETADDR      SUBNETMASK      VRFNAME", rows), nil
+	}
+	register(nil, "show vlan basic", showBasic)
+	register(nil, "show vlan basic <id>", showBasic)
+
+	showISID := func(sh *shell, args []string) (string, error) {
+		vlans, err := sh.dev.sortedVLANs(args)
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, v := range vlans {
+			rows = append(rows, fmt.Sprintf("%-10d %d", v.id, v.isid))
+		}
+		return table("Vlan I-SID", "VLAN_ID    I-SID", rows), nil
+	}
+	register(nil, "show vlan i-sid", showISID)
+	register(nil, "show vlan i-sid <id>", showISID)
+
+	showMembers := func(sh *shell, args []string) (string, error) {
+		vlans, err := sh.dev.sortedVLANs(args)
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, v := range vlans {
+			ports := formatPorts(v.memberList())
+			rows = append(rows, strings.TrimSpace(fmt.Sprintf("%-7d %-20s %-20s %-20s", v.id, ports, ports, ports)))
+		}
+		return table("Vlan Port",
+			"VLAN    PORT                 ACTIVE               STATIC               NOT_ALLOW\n"+
+				"ID      MEMBER               MEMBER               MEMBER               MEMBER", rows), nil
+	}
+	register(nil, "show vlan members", showMembers)
+	register(nil, "show vlan members <id>", showMembers)
+
+	showPortVLANs := func(sh *shell, args []string) (string, error) {
+		ports := sh.dev.portNames()
+		if len(args) == 1 {
+			var err error
+			if ports, err = sh.dev.parsePorts(args[0]); err != nil {
+				return "", err
+			}
+		}
+		var rows []string
+		for _, name := range ports {
+			tagging := "disable"
+			if sh.dev.ports[name].tagged {
+				tagging = "enable"
+			}
+			var ids []string
+			for _, v := range sh.dev.sortedVLANsOf(name) {
+				ids = append(ids, strconv.Itoa(v.id))
+			}
+			rows = append(rows, fmt.Sprintf("%-7s %-9s %-7s %s", name, tagging, "false", strings.Join(ids, ",")))
+		}
+		return table("Port Vlans",
+			"PORT              DISCARD\n"+
+				"NUM     TAGGING   TAGGED  VLANIDS", rows), nil
+	}
+	register(nil, "show interfaces gigabitEthernet vlan", showPortVLANs)
+	register(nil, "show interfaces gigabitEthernet vlan <ports>", showPortVLANs)
+
+	registerSection(20, "VLAN CONFIGURATION", func(d *device) []string {
+		vlans, _ := d.sortedVLANs(nil)
+		var lines []string
+		for _, v := range vlans {
+			if v.id == 1 {
+				continue
+			}
+			if v.typ == vlanTypeBVLAN {
+				lines = append(lines, fmt.Sprintf("vlan create %d name %q type %s", v.id, v.name, v.typ))
+			} else {
+				lines = append(lines, fmt.Sprintf("vlan create %d name %q type %s %d", v.id, v.name, v.typ, v.stg))
+			}
+			if len(v.members) > 0 {
+				lines = append(lines, fmt.Sprintf("vlan members add %d %s", v.id, formatPorts(v.memberList())))
+			}
+			if v.isid != 0 {
+				lines = append(lines, fmt.Sprintf("vlan i-sid %d %d", v.id, v.isid))
+			}
+		}
+		return lines
+	})
+}
+
+// sortedVLANsOf returns the VLANs port is a member of.
+func (d *device) sortedVLANsOf(port string) []*vlan {
+	vlans, _ := d.sortedVLANs(nil)
+	var of []*vlan
+	for _, v := range vlans {
+		if v.members[port] {
+			of = append(of, v)
+		}
+	}
+	return of
+}
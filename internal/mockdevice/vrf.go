@@ -0,0 +1,301 @@
+package mockdevice
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// globalRouter is the name of the VRF of the global routing table, VRF 0.
+const globalRouter = "GlobalRouter"
+
+var vrfNameRe = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,16}$`)
+
+// Sources of the routes redistributed into IS-IS by an L3 VSN.
+var redistributeSources = []string{"direct", "static"}
+
+// vrf is a VRF created with "ip vrf", with the IP VPN configuration of its
+// "router vrf" context.
+type vrf struct {
+	name string
+	id   int
+	// ipvpn is set by "ipvpn" in the router VRF context.
+	ipvpn        bool
+	isid         int
+	ipvpnEnabled bool
+	// redistribute maps the sources given to "isis redistribute" to whether
+	// the redistribution is enabled.
+	redistribute map[string]bool
+	// mvpn is set by "mvpn enable" in the router VRF context.
+	mvpn bool
+}
+
+// vrfArg returns the existing VRF whose name is s.
+func (d *device) vrfArg(s string) (*vrf, error) {
+	v, ok := d.vrfs[s]
+	if !ok {
+		return nil, errorf("VRF %s does not exist", s)
+	}
+	return v, nil
+}
+
+func (d *device) sortedVRFs() []*vrf {
+	var vrfs []*vrf
+	for _, v := range d.vrfs {
+		vrfs = append(vrfs, v)
+	}
+	sort.Slice(vrfs, func(i, j int) bool { return vrfs[i].id < vrfs[j].id })
+	return vrfs
+}
+
+// isidUser describes what already uses the I-SID, or returns "" if it is
+// free.
+func (d *device) isidUser(isid int) string {
+	for _, v := range d.vlans {
+		if v.isid == isid {
+			return fmt.Sprintf("VLAN %d", v.id)
+		}
+	}
+	if _, ok := d.elans[isid]; ok {
+		return "an ELAN service"
+	}
+	for _, v := range d.vrfs {
+		if v.isid == isid {
+			return "VRF " + v.name
+		}
+	}
+	return d.autoSense.isidUser(isid)
+}
+
+func init() {
+	vrfMode := []mode{"config-vrf"}
+
+	registerConfig([]mode{modeConfig}, "ip vrf <name> vrfid <id>", func(sh *shell, args []string) (string, error) {
+		name := args[0]
+		if !vrfNameRe.MatchString(name) || name == globalRouter {
+			return "", errorf("Invalid VRF name %q", name)
+		}
+		id, err := parseInt(args[1], "VRF ID", 1, 511)
+		if err != nil {
+			return "", err
+		}
+		if v, ok := sh.dev.vrfs[name]; ok {
+			if v.id != id {
+				return "", errorf("VRF %s already exists with VRF ID %d", name, v.id)
+			}
+			return "", nil
+		}
+		for _, v := range sh.dev.vrfs {
+			if v.id == id {
+				return "", errorf("VRF ID %d is already used by VRF %s", id, v.name)
+			}
+		}
+		sh.dev.vrfs[name] = &vrf{name: name, id: id, redistribute: map[string]bool{}}
+		return "", nil
+	})
+	registerConfig([]mode{modeConfig}, "no ip vrf <name>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vrfArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		n := 0
+		for _, count := range sh.dev.vrfInterfaces(v.name) {
+			n += count
+		}
+		if n > 0 {
+			return "", errorf("VRF %s has %d interfaces, remove them from the VRF first", v.name, n)
+		}
+		delete(sh.dev.vrfs, v.name)
+		for key := range sh.dev.staticRoutes {
+			if key.vrf == v.name {
+				delete(sh.dev.staticRoutes, key)
+			}
+		}
+		return "", nil
+	})
+
+	register([]mode{modeConfig}, "router vrf <name>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vrfArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		sh.mode, sh.target = "config-vrf", v.name
+		return "", nil
+	})
+	registerConfig(vrfMode, "ipvpn", func(sh *shell, _ []string) (string, error) {
+		sh.dev.vrfs[sh.target].ipvpn = true
+		return "", nil
+	})
+	registerConfig(vrfMode, "no ipvpn", func(sh *shell, _ []string) (string, error) {
+		v := sh.dev.vrfs[sh.target]
+		if v.ipvpnEnabled {
+			return "", errorf("IP VPN is enabled on VRF %s, use \"no ipvpn enable\" first", v.name)
+		}
+		v.ipvpn, v.isid = false, 0
+		return "", nil
+	})
+	registerConfig(vrfMode, "i-sid <isid>", func(sh *shell, args []string) (string, error) {
+		v := sh.dev.vrfs[sh.target]
+		if !v.ipvpn {
+			return "", errorf("IP VPN is not configured on VRF %s", v.name)
+		}
+		if v.ipvpnEnabled {
+			return "", errorf("Cannot change the I-SID while IP VPN is enabled")
+		}
+		isid, err := parseInt(args[0], "I-SID", 1, 15999999)
+		if err != nil {
+			return "", err
+		}
+		if user := sh.dev.isidUser(isid); user != "" && isid != v.isid {
+			return "", errorf("I-SID %d is already used by %s", isid, user)
+		}
+		v.isid = isid
+		return "", nil
+	})
+	registerConfig(vrfMode, "no i-sid", func(sh *shell, _ []string) (string, error) {
+		v := sh.dev.vrfs[sh.target]
+		if v.ipvpnEnabled {
+			return "", errorf("Cannot change the I-SID while IP VPN is enabled")
+		}
+		v.isid = 0
+		return "", nil
+	})
+	registerConfig(vrfMode, "ipvpn enable", func(sh *shell, _ []string) (string, error) {
+		v := sh.dev.vrfs[sh.target]
+		if !v.ipvpn || v.isid == 0 {
+			return "", errorf("IP VPN cannot be enabled on VRF %s without an I-SID", v.name)
+		}
+		v.ipvpnEnabled = true
+		return "", nil
+	})
+	registerConfig(vrfMode, "no ipvpn enable", func(sh *shell, _ []string) (string, error) {
+		sh.dev.vrfs[sh.target].ipvpnEnabled = false
+		return "", nil
+	})
+
+	for _, source := range redistributeSources {
+		registerConfig(vrfMode, "isis redistribute "+source, func(sh *shell, _ []string) (string, error) {
+			v := sh.dev.vrfs[sh.target]
+			if _, ok := v.redistribute[source]; !ok {
+				v.redistribute[source] = false
+			}
+			return "", nil
+		})
+		registerConfig(vrfMode, "no isis redistribute "+source, func(sh *shell, _ []string) (string, error) {
+			delete(sh.dev.vrfs[sh.target].redistribute, source)
+			return "", nil
+		})
+		registerConfig(vrfMode, "isis redistribute "+source+" enable", func(sh *shell, _ []string) (string, error) {
+			v := sh.dev.vrfs[sh.target]
+			if _, ok := v.redistribute[source]; !ok {
+				return "", errorf("Redistribution of %s routes is not configured", source)
+			}
+			v.redistribute[source] = true
+			return "", nil
+		})
+		registerConfig(vrfMode, "no isis redistribute "+source+" enable", func(sh *shell, _ []string) (string, error) {
+			v := sh.dev.vrfs[sh.target]
+			if _, ok := v.redistribute[source]; ok {
+				v.redistribute[source] = false
+			}
+			return "", nil
+		})
+		register([]mode{modeConfig}, "isis apply redistribute "+source+" vrf <name>", func(sh *shell, args []string) (string, error) {
+			_, err := sh.dev.vrfArg(args[0])
+			return "", err
+		})
+	}
+
+	register(nil, "show ip vrf", func(sh *shell, _ []string) (string, error) {
+		grt := sh.dev.vrfInterfaces("")
+		grt[ipKindVLAN] = 0
+		for _, v := range sh.dev.vlans {
+			if sh.dev.vrfName(v.id) == globalRouter {
+				grt[ipKindVLAN]++
+			}
+		}
+		rows := []string{vrfSummary(globalRouter, 0, grt)}
+		for _, v := range sh.dev.sortedVRFs() {
+			rows = append(rows, vrfSummary(v.name, v.id, sh.dev.vrfInterfaces(v.name)))
+		}
+		return table("VRF INFORMATION",
+			"VRF              VRF     TRAP     VLAN   BROUTER  LOOPBACK\n"+
+				"NAME             ID               COUNT  COUNT    COUNT", rows), nil
+	})
+
+	register(nil, "show ip ipvpn", func(sh *shell, _ []string) (string, error) {
+		rule := strings.Repeat("=", 80)
+		var b strings.Builder
+		for _, v := range sh.dev.sortedVRFs() {
+			if !v.ipvpn {
+				continue
+			}
+			state := "disabled"
+			if v.ipvpnEnabled {
+				state = "enabled"
+			}
+			fmt.Fprintf(&b, "%s\n%36s : %s\n%s\n%36s : %s\n%36s : %s\n%36s : %d\n\n",
+				rule, "VRF Name", v.name, rule,
+				"Ipv4 Ipvpn-state", state,
+				"Ipv6 Ipvpn-state", "disabled",
+				"I-sid", v.isid)
+		}
+		return b.String(), nil
+	})
+
+	register(nil, "show ip isis redistribute vrf <name>", func(sh *shell, args []string) (string, error) {
+		v, err := sh.dev.vrfArg(args[0])
+		if err != nil {
+			return "", err
+		}
+		var rows []string
+		for _, source := range redistributeSources {
+			if enabled, ok := v.redistribute[source]; ok {
+				rows = append(rows, fmt.Sprintf("%-10s %-7s %-7s %s", strings.ToUpper(source), "0", "internal", strings.ToUpper(strconv.FormatBool(enabled))))
+			}
+		}
+		return table("ISIS Redistribute List - VRF "+v.name,
+			"SOURCE     MET     MTYPE    ENABLE", rows), nil
+	})
+
+	registerSection(15, "VRF CONFIGURATION", func(d *device) []string {
+		var lines []string
+		for _, v := range d.sortedVRFs() {
+			lines = append(lines, fmt.Sprintf("ip vrf %s vrfid %d", v.name, v.id))
+		}
+		return lines
+	})
+
+	registerSection(50, "IPVPN CONFIGURATION", func(d *device) []string {
+		var lines []string
+		for _, v := range d.sortedVRFs() {
+			var cmds []string
+			if v.ipvpn {
+				cmds = append(cmds, "ipvpn")
+				if v.isid != 0 {
+					cmds = append(cmds, fmt.Sprintf("i-sid %d", v.isid))
+				}
+				if v.ipvpnEnabled {
+					cmds = append(cmds, "ipvpn enable")
+				}
+			}
+			for _, source := range redistributeSources {
+				if enabled, ok := v.redistribute[source]; ok {
+					cmds = append(cmds, "isis redistribute "+source)
+					if enabled {
+						cmds = append(cmds, "isis redistribute "+source+" enable")
+					}
+				}
+			}
+			if v.mvpn {
+				cmds = append(cmds, "mvpn enable")
+			}
+			if len(cmds) > 0 {
+				lines = append(append(append(lines, "router vrf "+v.name), cmds...), "exit")
+			}
+		}
+		return lines
+	})
+}
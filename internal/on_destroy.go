@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Policies applied by singleton resources, which manage a setting that exists
+// once per device, when they are destroyed.
+const (
+	onDestroyRetain          = "retain"
+	onDestroyResetToDefault  = "reset_to_default"
+	onDestroyRestoreOriginal = "restore_original"
+)
+
+// privateOriginalKey is the private state key under which singleton resources
+// store the value the device had before Terraform managed it.
+const privateOriginalKey = "original"
+
+// onDestroyAttribute returns the schema of the on_destroy attribute shared by
+// singleton resources.
+func onDestroyAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		MarkdownDescription: "What to do with the device setting when the resource is destroyed: " +
+			"`retain` (default) leaves it unchanged, `reset_to_default` restores the factory default and " +
+			"`restore_original` restores the value the device had before Terraform managed it.",
+		Optional: true,
+		Computed: true,
+		Default:  stringdefault.StaticString(onDestroyRetain),
+		Validators: []validator.String{
+			stringOneOf(onDestroyRetain, onDestroyResetToDefault, onDestroyRestoreOriginal),
+		},
+	}
+}
+
+// privateState is implemented by the Private field of the resource responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// saveOriginal records value, which must be JSON encodable, as the
+// pre-Terraform value of the setting.
+func saveOriginal(ctx context.Context, private privateState, value any) diag.Diagnostics {
+	data, err := json.Marshal(value)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to record original value", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, privateOriginalKey, data)
+}
+
+// loadOriginal decodes the pre-Terraform value of the setting into value. It
+// returns false if no value was recorded.
+func loadOriginal(ctx context.Context, private privateState, value any) (bool, diag.Diagnostics) {
+	data, diags := private.GetKey(ctx, privateOriginalKey)
+	if diags.HasError() || len(data) == 0 {
+		return false, diags
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		diags.AddError("Unable to read original value", err.Error())
+		return false, diags
+	}
+	return true, diags
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+// portsAttribute returns the schema of a set of ports. Ports are listed
+// individually, e.g. ["1/1", "1/2"], and an unset attribute manages an empty
+// set.
+func portsAttribute(description string) schema.SetAttribute {
+	return schema.SetAttribute{
+		MarkdownDescription: description + " Ports are given individually, e.g. `[\"1/1\", \"1/2\"]`.",
+		ElementType:         types.StringType,
+		Optional:            true,
+		Computed:            true,
+		Default:             setdefault.StaticValue(types.SetValueMust(types.StringType, []attr.Value{})),
+		Validators:          []validator.Set{portSetValidator{}},
+	}
+}
+
+// portsFromSet returns the ports of a set attribute, sorted.
+func portsFromSet(ctx context.Context, set types.Set, diags *diag.Diagnostics) []string {
+	var ports []string
+	diags.Append(set.ElementsAs(ctx, &ports, false)...)
+	transport.SortPorts(ports)
+	return ports
+}
+
+// portsToSet returns ports as a set attribute value, which is empty rather
+// than null when there are no ports.
+func portsToSet(ctx context.Context, ports []string, diags *diag.Diagnostics) types.Set {
+	if ports == nil {
+		ports = []string{}
+	}
+	set, d := types.SetValueFrom(ctx, types.StringType, ports)
+	diags.Append(d...)
+	return set
+}
+
+// portSet returns ports as a set.
+func portSet(ports []string) map[string]bool {
+	set := make(map[string]bool, len(ports))
+	for _, port := range ports {
+		set[port] = true
+	}
+	return set
+}
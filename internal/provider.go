@@ -2,15 +2,22 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+	"golang.org/x/crypto/ssh"
 )
 
 var _ provider.Provider = &ExtrmFabricEngineProvider{}
@@ -26,6 +33,27 @@ type ExtrmFabricEngineModel struct {
 	Port     types.Int32  `tfsdk:"port"`
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
+
+	PrivateKey           types.String `tfsdk:"private_key"`
+	PrivateKeyFile       types.String `tfsdk:"private_key_file"`
+	PrivateKeyPassphrase types.String `tfsdk:"private_key_passphrase"`
+	SSHAgent             types.Bool   `tfsdk:"ssh_agent"`
+	KeyboardInteractive  types.Bool   `tfsdk:"keyboard_interactive"`
+
+	MaxSessions types.Int32 `tfsdk:"max_sessions"`
+
+	Devices types.Map `tfsdk:"devices"`
+
+	Transport types.String `tfsdk:"transport"`
+	RESTPort  types.Int32  `tfsdk:"rest_port"`
+	CAFile    types.String `tfsdk:"ca_file"`
+	Insecure  types.Bool   `tfsdk:"insecure"`
+
+	NETCONFPort types.Int32 `tfsdk:"netconf_port"`
+
+	KnownHostsFile  types.String `tfsdk:"known_hosts_file"`
+	HostKey         types.String `tfsdk:"host_key"`
+	TrustOnFirstUse types.Bool   `tfsdk:"trust_on_first_use"`
 }
 
 type ExtrmFabricEngineClient struct {
@@ -33,6 +61,10 @@ type ExtrmFabricEngineClient struct {
 	Port     int32
 	Username string
 	Password string
+
+	// devices holds the devices resources can target, keyed by name. The
+	// default target, if any, is stored under the empty name.
+	devices map[string]*Device
 }
 
 func (p *ExtrmFabricEngineProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -61,6 +93,66 @@ func (p *ExtrmFabricEngineProvider) Schema(ctx context.Context, req provider.Sch
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"private_key": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key used for public key authentication.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"private_key_file": schema.StringAttribute{
+				MarkdownDescription: "Path of a PEM encoded private key used for public key authentication.",
+				Optional:            true,
+			},
+			"private_key_passphrase": schema.StringAttribute{
+				MarkdownDescription: "Passphrase protecting `private_key` or `private_key_file`.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"ssh_agent": schema.BoolAttribute{
+				MarkdownDescription: "Authenticate with the keys of the SSH agent listening on `SSH_AUTH_SOCK`.",
+				Optional:            true,
+			},
+			"keyboard_interactive": schema.BoolAttribute{
+				MarkdownDescription: "Answer keyboard-interactive prompts with `password`, for devices that require it.",
+				Optional:            true,
+			},
+			"max_sessions": schema.Int32Attribute{
+				MarkdownDescription: "Maximum number of SSH sessions the provider keeps open on the Fabric Engine device. Sessions are shared by all resources. Defaults to 2.",
+				Optional:            true,
+			},
+			"known_hosts_file": schema.StringAttribute{
+				MarkdownDescription: "Path of an OpenSSH known_hosts file used to verify the host key of the Fabric Engine device.",
+				Optional:            true,
+			},
+			"host_key": schema.StringAttribute{
+				MarkdownDescription: "Expected host key of the Fabric Engine device, either as a public key in authorized_keys format or as a `SHA256:` fingerprint.",
+				Optional:            true,
+			},
+			"devices": devicesAttribute(),
+			"transport": schema.StringAttribute{
+				MarkdownDescription: "Transport used by resources that have a structured representation, such as the hostname: `ssh` (default) scrapes the CLI, " +
+					"`rest` uses the REST API and `netconf` commits each change atomically through the candidate datastore. Other resources always use SSH.",
+				Optional: true,
+			},
+			"rest_port": schema.Int32Attribute{
+				MarkdownDescription: "HTTPS port of the REST API. Defaults to 443.",
+				Optional:            true,
+			},
+			"ca_file": schema.StringAttribute{
+				MarkdownDescription: "Path of a PEM bundle of the certificate authorities trusted for the REST API. Defaults to the system roots.",
+				Optional:            true,
+			},
+			"insecure": schema.BoolAttribute{
+				MarkdownDescription: "Skip TLS certificate verification of the REST API.",
+				Optional:            true,
+			},
+			"netconf_port": schema.Int32Attribute{
+				MarkdownDescription: "Port of the NETCONF SSH subsystem. Defaults to 830.",
+				Optional:            true,
+			},
+			"trust_on_first_use": schema.BoolAttribute{
+				MarkdownDescription: "Record the host key of a device missing from `known_hosts_file` instead of rejecting it.",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -88,7 +180,16 @@ func (p *ExtrmFabricEngineProvider) Configure(ctx context.Context, req provider.
 		host = config.Host.ValueString()
 	}
 
-	if host == "" {
+	// DEVICES
+	if config.Devices.IsUnknown() {
+		resp.Diagnostics.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as devices")
+		return
+	}
+
+	// Without a host, the provider only manages the devices of the devices map.
+	if host == "" && len(config.Devices.Elements()) == 0 {
 		resp.Diagnostics.AddError(
 			"Unable to find host",
 			"Host cannot be an empty string")
@@ -105,15 +206,17 @@ func (p *ExtrmFabricEngineProvider) Configure(ctx context.Context, req provider.
 	}
 
 	if config.Port.IsNull() {
-		resp.Diagnostics.AddError(
-			"Unable to find port",
-			"Port cannot be an null integer")
-		return
+		if host != "" {
+			resp.Diagnostics.AddError(
+				"Unable to find port",
+				"Port cannot be an null integer")
+			return
+		}
 	} else {
 		port = config.Port.ValueInt32()
 	}
 
-	if port == 0 {
+	if host != "" && port == 0 {
 		resp.Diagnostics.AddError(
 			"Unable to find port",
 			"Port cannot be an empty integer")
@@ -135,7 +238,7 @@ func (p *ExtrmFabricEngineProvider) Configure(ctx context.Context, req provider.
 		username = config.Username.ValueString()
 	}
 
-	if username == "" {
+	if host != "" && username == "" {
 		resp.Diagnostics.AddError(
 			"Unable to find host",
 			"Username cannot be an empty string")
@@ -157,10 +260,86 @@ func (p *ExtrmFabricEngineProvider) Configure(ctx context.Context, req provider.
 		password = config.Password.ValueString()
 	}
 
-	if password == "" {
+	// Public key authentication
+	signers, ok := privateKeySigners(config, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+	if config.SSHAgent.IsUnknown() || config.KeyboardInteractive.IsUnknown() {
+		resp.Diagnostics.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as authentication method")
+		return
+	}
+
+	auth := transport.Config{
+		Password:            password,
+		Signers:             signers,
+		UseAgent:            config.SSHAgent.ValueBool(),
+		KeyboardInteractive: config.KeyboardInteractive.ValueBool(),
+	}
+	if host != "" && !auth.HasAuth() {
 		resp.Diagnostics.AddError(
-			"Unable to find host",
-			"Password cannot be an empty string")
+			"Unable to find credentials",
+			"One of password, private_key, private_key_file or ssh_agent must be set")
+		return
+	}
+	if auth.KeyboardInteractive && password == "" {
+		resp.Diagnostics.AddError(
+			"Unable to find credentials",
+			"keyboard_interactive requires password to be set")
+		return
+	}
+
+	// Host key verification
+	hostKey, ok := hostKeyConfig(config, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+	hostKeyCallback, err := hostKey.Callback()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid host key verification settings",
+			err.Error())
+		return
+	}
+	if hostKey.Insecure() {
+		resp.Diagnostics.AddWarning(
+			"Host key verification disabled",
+			"Neither known_hosts_file nor host_key is set, the identity of the device will not be verified.")
+	}
+
+	if config.MaxSessions.IsUnknown() {
+		resp.Diagnostics.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as max_sessions")
+		return
+	}
+	if !config.MaxSessions.IsNull() && config.MaxSessions.ValueInt32() < 1 {
+		resp.Diagnostics.AddError(
+			"Invalid max_sessions",
+			"max_sessions must be at least 1")
+		return
+	}
+
+	base := transport.Config{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+
+		Signers:             auth.Signers,
+		UseAgent:            auth.UseAgent,
+		KeyboardInteractive: auth.KeyboardInteractive,
+
+		MaxSessions: int(config.MaxSessions.ValueInt32()),
+
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	// Transport
+	ts, ok := transportConfig(config, &resp.Diagnostics)
+	if !ok {
 		return
 	}
 
@@ -169,14 +348,154 @@ func (p *ExtrmFabricEngineProvider) Configure(ctx context.Context, req provider.
 		Port:     port,
 		Username: username,
 		Password: password,
+		devices:  map[string]*Device{},
 	}
+	if host != "" {
+		if err := client.addDevice("", base, ts); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to create client",
+				err.Error())
+			return
+		}
+	}
+	configureDevices(ctx, config.Devices, base, hostKey, ts, client, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.DataSourceData = client
 	resp.ResourceData = client
 }
 
+// privateKeySigners loads the private key given inline or by path, falling
+// back to the EXTRM_FE_PRIVATE_KEY_FILE environment variable.
+func privateKeySigners(config ExtrmFabricEngineModel, diags *diag.Diagnostics) ([]ssh.Signer, bool) {
+	if config.PrivateKey.IsUnknown() || config.PrivateKeyFile.IsUnknown() || config.PrivateKeyPassphrase.IsUnknown() {
+		diags.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as private key")
+		return nil, false
+	}
+
+	path := config.PrivateKeyFile.ValueString()
+	if config.PrivateKeyFile.IsNull() {
+		path = os.Getenv("EXTRM_FE_PRIVATE_KEY_FILE")
+	}
+	if config.PrivateKey.IsNull() && path == "" {
+		return nil, true
+	}
+
+	signer, err := loadPrivateKey(config.PrivateKey.ValueString(), path, config.PrivateKeyPassphrase.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Invalid private key",
+			err.Error())
+		return nil, false
+	}
+	return []ssh.Signer{signer}, true
+}
+
+// loadPrivateKey parses the private key given inline as pemKey or stored at
+// path. Exactly one of them must be set.
+func loadPrivateKey(pemKey, path, passphrase string) (ssh.Signer, error) {
+	if pemKey != "" && path != "" {
+		return nil, errors.New("only one of private_key and private_key_file can be set")
+	}
+
+	pemBytes := []byte(pemKey)
+	if path != "" {
+		var err error
+		pemBytes, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading private key: %w", err)
+		}
+	}
+	return transport.ParsePrivateKey(pemBytes, passphrase)
+}
+
+// transportConfig reads the transport selection and the REST API and NETCONF
+// settings from config.
+func transportConfig(config ExtrmFabricEngineModel, diags *diag.Diagnostics) (transportSettings, bool) {
+	var ts transportSettings
+	if config.Transport.IsUnknown() || config.RESTPort.IsUnknown() || config.CAFile.IsUnknown() || config.Insecure.IsUnknown() ||
+		config.NETCONFPort.IsUnknown() {
+		diags.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as transport settings")
+		return ts, false
+	}
+
+	ts.kind = config.Transport.ValueString()
+	if config.Transport.IsNull() {
+		ts.kind = "ssh"
+	}
+	if ts.kind != "ssh" && ts.kind != "rest" && ts.kind != "netconf" {
+		diags.AddError(
+			"Invalid transport",
+			fmt.Sprintf("transport must be \"ssh\", \"rest\" or \"netconf\", got %q", ts.kind))
+		return ts, false
+	}
+
+	ts.netconfPort = config.NETCONFPort.ValueInt32()
+	ts.restPort = config.RESTPort.ValueInt32()
+	ts.insecure = config.Insecure.ValueBool()
+	if path := config.CAFile.ValueString(); path != "" {
+		var err error
+		ts.caBundle, err = os.ReadFile(path)
+		if err != nil {
+			diags.AddError(
+				"Unable to read CA bundle",
+				err.Error())
+			return ts, false
+		}
+	}
+	return ts, true
+}
+
+// hostKeyConfig reads the host key verification settings from config, falling
+// back to the EXTRM_FE_KNOWN_HOSTS and EXTRM_FE_HOST_KEY environment variables.
+func hostKeyConfig(config ExtrmFabricEngineModel, diags *diag.Diagnostics) (transport.HostKeyConfig, bool) {
+	var hostKey transport.HostKeyConfig
+	if config.KnownHostsFile.IsUnknown() || config.HostKey.IsUnknown() || config.TrustOnFirstUse.IsUnknown() {
+		diags.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value for host key verification")
+		return hostKey, false
+	}
+
+	if config.KnownHostsFile.IsNull() {
+		hostKey.KnownHostsFile = os.Getenv("EXTRM_FE_KNOWN_HOSTS")
+	} else {
+		hostKey.KnownHostsFile = config.KnownHostsFile.ValueString()
+	}
+
+	if config.HostKey.IsNull() {
+		hostKey.PinnedKey = os.Getenv("EXTRM_FE_HOST_KEY")
+	} else {
+		hostKey.PinnedKey = config.HostKey.ValueString()
+	}
+
+	hostKey.TrustOnFirstUse = config.TrustOnFirstUse.ValueBool()
+	return hostKey, true
+}
+
 func (p *ExtrmFabricEngineProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewFabricEngineHostnameResource,
+		NewFabricEngineVLANResource,
+		NewFabricEngineSPBMResource,
+		NewFabricEngineISISInterfaceResource,
+		NewFabricEngineL2VSNISIDResource,
+		NewFabricEngineVRFResource,
+		NewFabricEngineIPInterfaceResource,
+		NewFabricEngineStaticRouteResource,
+		NewFabricEngineMLTResource,
+		NewFabricEngineVISTResource,
+		NewFabricEnginePortResource,
+		NewFabricEngineAutoSenseResource,
+		NewFabricEngineFAPortResource,
+		NewFabricEngineDVRResource,
+		NewFabricEngineIPMulticastResource,
 	}
 }
 
@@ -185,13 +504,39 @@ func (p *ExtrmFabricEngineProvider) EphemeralResources(ctx context.Context) []fu
 }
 
 func (p *ExtrmFabricEngineProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewFabricEngineFADataSource,
+	}
 }
 
 func (p *ExtrmFabricEngineProvider) Functions(ctx context.Context) []func() function.Function {
 	return []func() function.Function{}
 }
 
+// openClients holds the transport clients created by Configure so their
+// connections can be closed when the provider process exits.
+var openClients struct {
+	sync.Mutex
+	clients []io.Closer
+}
+
+func trackClient(c io.Closer) {
+	openClients.Lock()
+	defer openClients.Unlock()
+	openClients.clients = append(openClients.clients, c)
+}
+
+// Shutdown closes the connections opened by every configured provider
+// instance. It is called once the provider server has stopped.
+func Shutdown() {
+	openClients.Lock()
+	defer openClients.Unlock()
+	for _, c := range openClients.clients {
+		c.Close()
+	}
+	openClients.clients = nil
+}
+
 func New(version string) func() provider.Provider {
 	return func() provider.Provider {
 		return &ExtrmFabricEngineProvider{
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineAutoSenseResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_auto_sense" "test" {
+  onboarding_i_sid = 15999000
+  voice_i_sid      = 20000
+  voice_c_vid      = 20
+  data = [
+    { i_sid = 30000, c_vid = 30 },
+    { i_sid = 30001, c_vid = 31 },
+  ]
+  on_destroy = "reset_to_default"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_auto_sense.test", "id", "default"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_auto_sense.test", "data.#", "2"),
+					testCheckRunningConfig(srv, "auto-sense onboarding i-sid 15999000", true),
+					testCheckRunningConfig(srv, "auto-sense voice i-sid 20000 c-vid 20", true),
+					testCheckRunningConfig(srv, "auto-sense data i-sid 30001 c-vid 31", true),
+				),
+			},
+			{
+				// The voice I-SID becomes a data I-SID and the C-VIDs of the
+				// data I-SIDs are swapped.
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_auto_sense" "test" {
+  data = [
+    { i_sid = 20000, c_vid = 20 },
+    { i_sid = 30000, c_vid = 31 },
+    { i_sid = 30001, c_vid = 30 },
+  ]
+  on_destroy = "reset_to_default"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_auto_sense.test", "onboarding_i_sid", "15999999"),
+					resource.TestCheckNoResourceAttr("extrm-fabric-engine_auto_sense.test", "voice_i_sid"),
+					testCheckRunningConfig(srv, "auto-sense onboarding i-sid 15999000", false),
+					testCheckRunningConfig(srv, "auto-sense data i-sid 20000 c-vid 20", true),
+					testCheckRunningConfig(srv, "auto-sense data i-sid 30000 c-vid 31", true),
+				),
+			},
+			{
+				ResourceName:            "extrm-fabric-engine_auto_sense.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"on_destroy"},
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "auto-sense data i-sid 20000 c-vid 20", false),
+	})
+}
+
+func TestAccFabricEngineAutoSenseResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_auto_sense" "test" {
+  voice_i_sid = 20000
+}
+`,
+				ExpectError: regexp.MustCompile("voice_i_sid and voice_c_vid must be set together"),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_auto_sense" "test" {
+  voice_i_sid = 20000
+  voice_c_vid = 20
+  data        = [{ i_sid = 30000, c_vid = 20 }]
+}
+`,
+				ExpectError: regexp.MustCompile("C-VID 20 is used by several auto-sense services"),
+			},
+		},
+	})
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineDVRResource(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal",
+		"vlan create 10 type port-mstprstp 0", "vlan i-sid 10 10010", "interface vlan 10", "ip address 10.0.10.2/24", "exit",
+		"vlan create 20 type port-mstprstp 0", "interface vlan 20", "ip address 10.0.20.2/24", "exit", "end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_dvr" "test" {
+  role      = "controller"
+  domain_id = 1
+  interfaces = [
+    { vlan_id = 10, gateway_ipv4 = "10.0.10.1" },
+    { vlan_id = 20, gateway_ipv4 = "10.0.20.1", enabled = false },
+  ]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_dvr.test", "id", "default"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_dvr.test", "injection", "true"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_dvr.test", "inject_default_route", "true"),
+					testCheckRunningConfig(srv, "dvr controller 1", true),
+					testCheckRunningConfig(srv, "dvr gw-ipv4 10.0.10.1\ndvr enable\nexit", true),
+					testCheckRunningConfig(srv, "dvr gw-ipv4 10.0.20.1\nexit", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_dvr" "test" {
+  role      = "controller"
+  domain_id = 1
+  injection = false
+  interfaces = [
+    { vlan_id = 10, gateway_ipv4 = "10.0.10.254" },
+  ]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_dvr.test", "interfaces.#", "1"),
+					testCheckRunningConfig(srv, "no dvr injection", true),
+					testCheckRunningConfig(srv, "dvr gw-ipv4 10.0.10.254", true),
+					testCheckRunningConfig(srv, "dvr gw-ipv4 10.0.20.1", false),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_dvr.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "dvr", false),
+	})
+}
+
+func TestAccFabricEngineDVRResource_leaf(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_dvr" "test" {
+  role      = "leaf"
+  domain_id = 1
+  injection = false
+}
+`,
+				ExpectError: regexp.MustCompile(`injection is only supported when role is "controller"`),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_dvr" "test" {
+  role      = "leaf"
+  domain_id = 1
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr("extrm-fabric-engine_dvr.test", "injection"),
+					testCheckRunningConfig(srv, "dvr leaf 1", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_dvr.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "dvr leaf 1", false),
+	})
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/mockdevice"
+)
+
+func TestAccFabricEngineFADataSource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	// Elements are discovered over LLDP, so the mock device is told about
+	// them directly.
+	srv.Exec("configure terminal", "interface gigabitEthernet 1/5", "auto-sense enable", "exit", "end")
+	err := srv.DiscoverFAElement(mockdevice.FAElement{
+		Port:     "1/5",
+		Type:     "clientWapType1",
+		VLAN:     4048,
+		SystemID: "f4:ce:46:aa:bb:cc:00:00:00:01",
+		Trusted:  true,
+		Assignments: []mockdevice.FAAssignment{
+			{ISID: 10100, VLAN: 100, State: "active"},
+			{ISID: 10200, VLAN: 200, State: "rejected"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+data "extrm-fabric-engine_fa" "test" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "id", "default"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "elements.#", "1"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "elements.0.port", "1/5"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "elements.0.type", "clientWapType1"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "elements.0.system_id", "f4:ce:46:aa:bb:cc:00:00:00:01"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "elements.0.state", "trusted"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "assignments.#", "2"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "assignments.1.i_sid", "10200"),
+					resource.TestCheckResourceAttr("data.extrm-fabric-engine_fa.test", "assignments.1.state", "rejected"),
+				),
+			},
+		},
+	})
+}
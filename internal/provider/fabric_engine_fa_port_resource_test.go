@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineFAPortResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	config := func(msgAuth bool) string {
+		return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "extrm-fabric-engine_fa_port" "test" {
+  port                   = "1/5"
+  message_authentication = %t
+  authentication_key     = "s3cret"
+}
+`, msgAuth)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_fa_port.test", "id", "fa_port/port/1/5"),
+					testCheckRunningConfig(srv, "fa enable", true),
+					testCheckRunningConfig(srv, "fa authentication-key s3cret", true),
+				),
+			},
+			{
+				Config: config(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_fa_port.test", "message_authentication", "false"),
+					testCheckRunningConfig(srv, "no fa message-authentication", true),
+				),
+			},
+			{
+				ResourceName:            "extrm-fabric-engine_fa_port.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"authentication_key"},
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "fa enable", false),
+	})
+}
+
+func TestAccFabricEngineFAPortResource_mlt(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_mlt" "test" {
+  mlt_id = 2
+}
+
+resource "extrm-fabric-engine_fa_port" "test" {
+  mlt_id = extrm-fabric-engine_mlt.test.mlt_id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_fa_port.test", "id", "fa_port/mlt/2"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_fa_port.test", "message_authentication", "true"),
+					testCheckRunningConfig(srv, "interface mlt 2", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_fa_port.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "fa enable", false),
+	})
+}
+
+func TestAccFabricEngineFAPortResource_autoSense(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					srv.Exec("configure terminal", "interface gigabitEthernet 1/5", "auto-sense enable", "exit", "end")
+				},
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_fa_port" "test" {
+  port = "1/5"
+}
+`,
+				ExpectError: regexp.MustCompile("auto-sense enabled"),
+			},
+		},
+	})
+}
+
+func TestAccFabricEngineFAPortResource_rejectedKey(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Reject("fa authentication-key", "Error: Invalid key s3cret")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_fa_port" "test" {
+  port               = "1/5"
+  authentication_key = "s3cret"
+}
+`,
+				// The key is masked in the command and in the device response.
+				ExpectError: regexp.MustCompile(`authentication-key\s+\*\*\*\*"(.|\n)*Invalid\s+key\s+\*\*\*\*`),
+			},
+		},
+	})
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/hashicorp/terraform-plugin-testing/tfversion"
-	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/mockdevice"
 )
 
 func TestExampleFunction_Known(t *testing.T) {
@@ -37,66 +39,208 @@ func TestExampleFunction_Known(t *testing.T) {
 }
 
 func TestAccFabricEngineHostnameResource(t *testing.T) {
-	host := os.Getenv("EXTRM_FE_HOST")
-	port := os.Getenv("EXTRM_FE_PORT")
-	user := os.Getenv("EXTRM_FE_USERNAME")
-	pass := os.Getenv("EXTRM_FE_PASSWORD")
-	if host == "" || user == "" || pass == "" {
-		t.Skip("Les variables d’environnement EXTRM_FE_HOST, EXTRM_FE_USERNAME et EXTRM_FE_PASSWORD doivent être définies")
-	}
-	if port == "" {
-		port = "22"
+	srv := newMockDevice(t)
+
+	config := func(hostname string) string {
+		return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "extrm-fabric-engine_hostname" "test" {
+  hostname = %q
+}
+`, hostname)
 	}
 
-	configCreate := fmt.Sprintf(`
-provider "extrm_fabric_engine" {
-  host     = "%s"
-  port     = %s
-  username = "%s"
-  password = "%s"
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// Les tests d’acceptation doivent être exécutés avec TF_ACC=1.
+		Steps: []resource.TestStep{
+			{
+				// Étape 1 : création initiale du hostname
+				Config: config("LAB-VOSS01"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_hostname.test", "hostname", "LAB-VOSS01"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_hostname.test", "id", "default"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_hostname.test", "on_destroy", "retain"),
+					testCheckHostname(srv, "LAB-VOSS01"),
+				),
+			},
+			{
+				// Étape 2 : mise à jour du hostname
+				Config: config("LAB-VOSS02"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_hostname.test", "hostname", "LAB-VOSS02"),
+					testCheckHostname(srv, "LAB-VOSS02"),
+				),
+			},
+			{
+				// Étape 3 : import pour valider l’état
+				ResourceName:      "extrm-fabric-engine_hostname.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		// Avec on_destroy = "retain", le hostname reste en place.
+		CheckDestroy: testCheckHostname(srv, "LAB-VOSS02"),
+	})
 }
 
-resource "extrm_fabric_engine_hostname" "test" {
-  hostname = "LAB-VOSS01"
+func TestAccFabricEngineHostnameResource_onDestroy(t *testing.T) {
+	tests := []struct {
+		onDestroy string
+		want      string
+	}{
+		{"reset_to_default", mockdevice.DefaultHostname},
+		{"restore_original", "ORIGINAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.onDestroy, func(t *testing.T) {
+			srv := newMockDevice(t)
+			srv.SetHostname("ORIGINAL")
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "extrm-fabric-engine_hostname" "test" {
+  hostname   = "LAB-VOSS01"
+  on_destroy = %q
 }
-`, host, port, user, pass)
-
-	configUpdate := fmt.Sprintf(`
-provider "extrm_fabric_engine" {
-  host     = "%s"
-  port     = %s
-  username = "%s"
-  password = "%s"
+`, tt.onDestroy),
+						Check: testCheckHostname(srv, "LAB-VOSS01"),
+					},
+				},
+				CheckDestroy: testCheckHostname(srv, tt.want),
+			})
+		})
+	}
 }
 
-resource "extrm_fabric_engine_hostname" "test" {
-  hostname = "LAB-VOSS02"
+func TestAccFabricEngineHostnameResource_onDestroyUpdate(t *testing.T) {
+	srv := newMockDevice(t)
+
+	config := func(onDestroy string) string {
+		return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "extrm-fabric-engine_hostname" "test" {
+  hostname   = "LAB-VOSS01"
+  on_destroy = %q
 }
-`, host, port, user, pass)
+`, onDestroy)
+	}
 
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
-		// Les tests d’acceptation doivent être exécutés avec TF_ACC=1.
 		Steps: []resource.TestStep{
 			{
-				// Étape 1 : création initiale du hostname
-				Config: configCreate,
+				Config: config("retain"),
+				Check:  testCheckHostname(srv, "LAB-VOSS01"),
+			},
+			{
+				// Only the policy changes: it must be saved so that destroy
+				// applies it.
+				Config: config("reset_to_default"),
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("extrm_fabric_engine_hostname.test", "hostname", "LAB-VOSS01"),
-					resource.TestCheckResourceAttrSet("extrm_fabric_engine_hostname.test", "id"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_hostname.test", "on_destroy", "reset_to_default"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_hostname.test", "id", "default"),
 				),
 			},
+		},
+		CheckDestroy: testCheckHostname(srv, mockdevice.DefaultHostname),
+	})
+}
+
+func TestAccFabricEngineHostnameResource_device(t *testing.T) {
+	srv := newMockDevice(t)
+	spine := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
 			{
-				// Étape 2 : mise à jour du hostname
-				Config: configUpdate,
-				Check:  resource.TestCheckResourceAttr("extrm_fabric_engine_hostname.test", "hostname", "LAB-VOSS02"),
+				Config: fmt.Sprintf(`
+provider "extrm-fabric-engine" {
+  username = %q
+  password = %q
+
+  devices = {
+    leaf1  = { host = %q, port = %d, host_key = %q }
+    spine1 = { host = %q, port = %d, host_key = %q }
+  }
+}
+
+resource "extrm-fabric-engine_hostname" "test" {
+  device   = "spine1"
+  hostname = "SPINE1"
+}
+`, mockdevice.Username, mockdevice.Password,
+					srv.Host(), srv.Port(), srv.HostKey(),
+					spine.Host(), spine.Port(), spine.HostKey()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_hostname.test", "id", "spine1"),
+					testCheckHostname(spine, "SPINE1"),
+					testCheckHostname(srv, mockdevice.DefaultHostname),
+				),
 			},
 			{
-				// Étape 3 : import pour valider l’état
-				ResourceName:      "extrm_fabric_engine_hostname.test",
+				ResourceName:      "extrm-fabric-engine_hostname.test",
 				ImportState:       true,
+				ImportStateId:     "spine1",
 				ImportStateVerify: true,
 			},
 		},
 	})
 }
+
+func TestAccFabricEngineHostnameResource_deviceHostKeys(t *testing.T) {
+	leaf := newMockDevice(t)
+	spine := newMockDevice(t)
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+
+	// The top-level host_key only pins the key of leaf: spine presents
+	// another key and is verified with known_hosts_file instead.
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "extrm-fabric-engine" {
+  host               = %q
+  port               = %d
+  username           = %q
+  password           = %q
+  host_key           = %q
+  known_hosts_file   = %q
+  trust_on_first_use = true
+
+  devices = {
+    spine1 = { host = %q, port = %d }
+  }
+}
+
+resource "extrm-fabric-engine_hostname" "leaf" {
+  hostname = "LEAF1"
+}
+
+resource "extrm-fabric-engine_hostname" "spine" {
+  device   = "spine1"
+  hostname = "SPINE1"
+}
+`, leaf.Host(), leaf.Port(), mockdevice.Username, mockdevice.Password, leaf.HostKey(), knownHosts,
+					spine.Host(), spine.Port()),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckHostname(leaf, "LEAF1"),
+					testCheckHostname(spine, "SPINE1"),
+				),
+			},
+		},
+	})
+}
+
+// testCheckHostname checks the system name configured on srv.
+func testCheckHostname(srv *mockdevice.Server, want string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if got := srv.Hostname(); got != want {
+			return fmt.Errorf("device hostname is %q, want %q", got, want)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineIPInterfaceResource(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal", "vlan create 10 type port-mstprstp 0", "end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_interface" "test" {
+  vlan_id      = 10
+  ipv4_address = "10.0.10.1/24"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_ip_interface.test", "id", "ip_interface/GlobalRouter/vlan/10"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_ip_interface.test", "ipv6_addresses.#", "0"),
+					testCheckRunningConfig(srv, "ip address 10.0.10.1/24", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_interface" "test" {
+  vlan_id                  = 10
+  ipv4_address             = "10.0.10.1/24"
+  ipv4_secondary_addresses = ["10.0.11.1/24"]
+  ipv6_addresses           = ["2001:db8::1/64"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemAttr("extrm-fabric-engine_ip_interface.test", "ipv4_secondary_addresses.*", "10.0.11.1/24"),
+					resource.TestCheckTypeSetElemAttr("extrm-fabric-engine_ip_interface.test", "ipv6_addresses.*", "2001:db8::1/64"),
+					testCheckRunningConfig(srv, "ip address 10.0.11.1/24 secondary", true),
+					testCheckRunningConfig(srv, "ipv6 interface address 2001:db8::1/64", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_ip_interface.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "ip address 10.0.10.1/24", false),
+	})
+}
+
+func TestAccFabricEngineIPInterfaceResource_loopbackAndBrouter(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal", "ip vrf red vrfid 1", "end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_interface" "clip" {
+  loopback_id   = 1
+  vrf           = "red"
+  ipv4_address  = "1.1.1.1/32"
+  spb_multicast = true
+}
+
+resource "extrm-fabric-engine_ip_interface" "brouter" {
+  port            = "1/5"
+  brouter_vlan_id = 2001
+  ipv4_address    = "10.1.1.1/30"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_ip_interface.clip", "id", "ip_interface/red/loopback/1"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_ip_interface.brouter", "id", "ip_interface/GlobalRouter/port/1/5"),
+					testCheckRunningConfig(srv, "ip spb-multicast enable", true),
+					testCheckRunningConfig(srv, "brouter port 1/5 vlan 2001 subnet 10.1.1.1/30", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_ip_interface.clip",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_ip_interface.brouter",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckRunningConfig(srv, "interface loopback 1", false),
+			testCheckRunningConfig(srv, "brouter port 1/5 vlan 2001 subnet 10.1.1.1/30", false),
+		),
+	})
+}
+
+func TestAccFabricEngineIPInterfaceResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_interface" "test" {
+  vlan_id      = 10
+  ipv4_address = "10.0.10.1/33"
+}
+`,
+				ExpectError: regexp.MustCompile("is not an IPv4 address with its prefix length"),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_interface" "test" {
+  loopback_id  = 1
+  ipv4_address = "1.1.1.1/24"
+}
+`,
+				ExpectError: regexp.MustCompile("must have a /32 prefix length"),
+			},
+		},
+	})
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineIPMulticastResource(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal", "spbm", "router isis", "spbm 1", "exit",
+		"vlan create 10 type port-mstprstp 0", "interface vlan 10", "ip address 10.0.10.1/24", "exit",
+		"vlan create 20 type port-mstprstp 0", "interface vlan 20", "ip address 10.0.20.1/24", "exit",
+		"ip vrf red vrfid 1",
+		"vlan create 30 type port-mstprstp 0", "interface vlan 30", "vrf red", "ip address 10.0.30.1/24", "exit",
+		"end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_multicast" "grt" {
+  interfaces = [
+    { vlan_id = 10, igmp_snooping = true, igmp_version = 3 },
+    { vlan_id = 20, spb_multicast = false, igmp_snoop_querier = true, igmp_snoop_querier_address = "10.0.20.254" },
+  ]
+}
+
+resource "extrm-fabric-engine_ip_multicast" "red" {
+  vrf        = "red"
+  interfaces = [{ vlan_id = 30 }]
+
+  depends_on = [extrm-fabric-engine_ip_multicast.grt]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_ip_multicast.grt", "id", "ip_multicast/GlobalRouter"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_ip_multicast.red", "id", "ip_multicast/red"),
+					testCheckRunningConfig(srv, "spbm 1 multicast enable", true),
+					testCheckRunningConfig(srv, "router vrf red\nmvpn enable\nexit", true),
+					testCheckRunningConfig(srv, "ip spb-multicast enable\nip igmp version 3\nip igmp snooping", true),
+					testCheckRunningConfig(srv, "ip igmp snoop-querier-addr 10.0.20.254\nip igmp snoop-querier", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_multicast" "grt" {
+  interfaces = [{ vlan_id = 10 }]
+}
+
+resource "extrm-fabric-engine_ip_multicast" "red" {
+  vrf        = "red"
+  interfaces = [{ vlan_id = 30 }]
+
+  depends_on = [extrm-fabric-engine_ip_multicast.grt]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_ip_multicast.grt", "interfaces.#", "1"),
+					testCheckRunningConfig(srv, "ip igmp", false),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_ip_multicast.grt",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_ip_multicast.red",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "multicast", false),
+	})
+}
+
+func TestAccFabricEngineIPMulticastResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_multicast" "test" {
+  interfaces = [{ vlan_id = 10, spb_multicast = false }]
+}
+`,
+				ExpectError: regexp.MustCompile("VLAN 10 has only default multicast settings"),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_ip_multicast" "test" {
+  interfaces = [{ vlan_id = 10, igmp_snoop_querier_address = "10.0.010.1" }]
+}
+`,
+				ExpectError: regexp.MustCompile("is not an IPv4 address"),
+			},
+		},
+	})
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// testAccSPBMConfig configures SPBM instance 1, which IS-IS interfaces require.
+const testAccSPBMConfig = `
+resource "extrm-fabric-engine_vlan" "bvlan" {
+  vlan_id = 4051
+  type    = "spbm-bvlan"
+}
+
+resource "extrm-fabric-engine_spbm" "spbm" {
+  manual_area = "49.0001"
+  nick_name   = "0.00.01"
+  b_vlans     = [extrm-fabric-engine_vlan.bvlan.vlan_id]
+  on_destroy  = "reset_to_default"
+}
+`
+
+func TestAccFabricEngineISISInterfaceResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	config := func(metric int, auth string) string {
+		return testAccProviderConfig(srv) + testAccSPBMConfig + fmt.Sprintf(`
+resource "extrm-fabric-engine_isis_interface" "test" {
+  port   = "1/1"
+  metric = %d
+  %s
+
+  depends_on = [extrm-fabric-engine_spbm.spbm]
+}
+`, metric, auth)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(10, `
+  hello_auth_type   = "hmac-sha-256"
+  hello_auth_key    = "s3cret"
+  hello_auth_key_id = 2`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_isis_interface.test", "id", "isis_interface/port/1/1"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_isis_interface.test", "enabled", "true"),
+					testCheckRunningConfig(srv, "isis spbm 1", true),
+					testCheckRunningConfig(srv, "isis hello-auth type hmac-sha-256 key s3cret key-id 2", true),
+					testCheckRunningConfig(srv, "isis enable", true),
+				),
+			},
+			{
+				Config: config(50, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_isis_interface.test", "metric", "50"),
+					resource.TestCheckNoResourceAttr("extrm-fabric-engine_isis_interface.test", "hello_auth_type"),
+					testCheckRunningConfig(srv, "isis spbm 1 l1-metric 50", true),
+					testCheckRunningConfig(srv, "isis hello-auth type hmac-sha-256 key s3cret key-id 2", false),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_isis_interface.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "isis spbm 1 l1-metric 50", false),
+	})
+}
+
+func TestAccFabricEngineISISInterfaceResource_mlt(t *testing.T) {
+	srv := newMockDevice(t)
+
+	// MLTs are not managed by the provider yet.
+	srv.Exec("configure terminal", "mlt 2", "end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + testAccSPBMConfig + `
+resource "extrm-fabric-engine_isis_interface" "test" {
+  mlt_id  = 2
+  enabled = false
+
+  depends_on = [extrm-fabric-engine_spbm.spbm]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_isis_interface.test", "id", "isis_interface/mlt/2"),
+					testCheckRunningConfig(srv, "interface mlt 2", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_isis_interface.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "interface mlt 2", false),
+	})
+}
+
+func TestAccFabricEngineISISInterfaceResource_rejectedKey(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal", "spbm", "router isis", "spbm 1", "exit", "end")
+	srv.Reject("isis hello-auth", "Error: Invalid key s3cret")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_isis_interface" "test" {
+  port              = "1/1"
+  hello_auth_type   = "hmac-sha-256"
+  hello_auth_key    = "s3cret"
+  hello_auth_key_id = 2
+}
+`,
+				// The key is masked in the command and in the device response.
+				ExpectError: regexp.MustCompile(`key\s+\*\*\*\*\s+key-id\s+2"(.|\n)*Invalid\s+key\s+\*\*\*\*`),
+			},
+		},
+	})
+}
+
+func TestAccFabricEngineISISInterfaceResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_isis_interface" "test" {
+  port   = "1/1"
+  mlt_id = 2
+}
+`,
+				ExpectError: regexp.MustCompile("Exactly one of port and mlt_id"),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_isis_interface" "test" {
+  port = "1/1/1/1"
+}
+`,
+				ExpectError: regexp.MustCompile(`expected\s+<slot>/<port>\s+or\s+<slot>/<port>/<sub-port>`),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_isis_interface" "test" {
+  port            = "1/1"
+  hello_auth_type = "hmac-sha-256"
+  hello_auth_key  = "s3cret"
+}
+`,
+				ExpectError: regexp.MustCompile("hello_auth_key_id is required"),
+			},
+		},
+	})
+}
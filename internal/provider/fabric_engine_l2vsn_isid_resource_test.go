@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineL2VSNISIDResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	// Flex UNI is enabled on ports out of every VLAN, the port resource does
+	// not manage it yet.
+	srv.Exec("configure terminal", "vlan members remove 1 1/1-1/4", "mlt 2",
+		"interface gigabitEthernet 1/1-1/4", "flex-uni enable", "exit",
+		"interface mlt 2", "flex-uni enable", "exit", "end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_l2vsn_isid" "test" {
+  i_sid = 20000
+
+  endpoints = [
+    { port = "1/1", c_vid = 100 },
+    { port = "1/2" },
+    { mlt_id = 2, c_vid = 100 },
+  ]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_l2vsn_isid.test", "id", "l2vsn/20000"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_l2vsn_isid.test", "name", "ISID-20000"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_l2vsn_isid.test", "endpoints.#", "3"),
+					testCheckRunningConfig(srv, "c-vid 100 port 1/1", true),
+					testCheckRunningConfig(srv, "untagged-traffic port 1/2", true),
+					testCheckRunningConfig(srv, "c-vid 100 mlt 2", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_l2vsn_isid" "test" {
+  i_sid = 20000
+  name  = "Campus"
+
+  endpoints = [
+    { port = "1/1", c_vid = 100 },
+    { port = "1/3", c_vid = 200 },
+  ]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_l2vsn_isid.test", "name", "Campus"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_l2vsn_isid.test", "endpoints.#", "2"),
+					testCheckRunningConfig(srv, "c-vid 200 port 1/3", true),
+					testCheckRunningConfig(srv, "untagged-traffic port 1/2", false),
+					testCheckRunningConfig(srv, "c-vid 100 mlt 2", false),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_l2vsn_isid.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "i-sid 20000 elan", false),
+	})
+}
+
+func TestAccFabricEngineL2VSNISIDResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_l2vsn_isid" "test" {
+  i_sid     = 20000
+  endpoints = [{ port = "1/1", mlt_id = 2 }]
+}
+`,
+				ExpectError: regexp.MustCompile("Exactly one of port and mlt_id"),
+			},
+			{
+				// Flex UNI is not enabled on the port.
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_l2vsn_isid" "test" {
+  i_sid     = 20000
+  endpoints = [{ port = "1/9", c_vid = 10 }]
+}
+`,
+				ExpectError: regexp.MustCompile("Flex UNI is not enabled on port 1/9"),
+			},
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineMLTResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_mlt" "test" {
+  mlt_id  = 10
+  members = ["1/1", "1/2"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_mlt.test", "id", "mlt/10"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_mlt.test", "name", "MLT-10"),
+					testCheckRunningConfig(srv, "mlt 10 member 1/1-1/2", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_mlt" "test" {
+  mlt_id              = 10
+  name                = "core uplink"
+  encapsulation_dot1q = true
+  members             = ["1/2", "1/3"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_mlt.test", "name", "core uplink"),
+					testCheckRunningConfig(srv, "mlt 10 encapsulation dot1q", true),
+					testCheckRunningConfig(srv, "mlt 10 member 1/2-1/3", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_mlt.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "mlt 10", false),
+	})
+}
+
+func TestAccFabricEngineMLTResource_lacp(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_mlt" "test" {
+  mlt_id   = 20
+  members  = ["2/1", "2/2"]
+  lacp_key = 20
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_mlt.test", "lacp_key", "20"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_mlt.test", "members.#", "2"),
+					testCheckRunningConfig(srv, "interface mlt 20\nlacp key 20\nlacp enable\nexit", true),
+					testCheckRunningConfig(srv, "interface gigabitEthernet 2/1\nlacp key 20\nlacp aggregation enable\nlacp enable\nexit", true),
+				),
+			},
+			{
+				// A member leaving the bundle outside of Terraform is added back.
+				PreConfig: func() {
+					srv.Exec("configure terminal", "interface gigabitEthernet 2/2", "no lacp enable", "exit", "end")
+				},
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_mlt" "test" {
+  mlt_id   = 20
+  members  = ["2/1", "2/2", "2/3"]
+  lacp_key = 20
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_mlt.test", "members.#", "3"),
+					testCheckRunningConfig(srv, "interface gigabitEthernet 2/2\nlacp key 20\nlacp aggregation enable\nlacp enable\nexit", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_mlt.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "lacp key 20", false),
+	})
+}
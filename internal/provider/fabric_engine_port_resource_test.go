@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEnginePortResource(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal", "vlan create 10 type port-mstprstp 0", "end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_port" "test" {
+  port                    = "1/5"
+  name                    = "uplink to core"
+  auto_negotiation        = false
+  speed                   = 1000
+  duplex                  = "full"
+  default_vlan_id         = 10
+  encapsulation_dot1q     = true
+  untag_port_default_vlan = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_port.test", "id", "port/1/5"),
+					testCheckRunningConfig(srv, "interface gigabitEthernet 1/5\nname \"uplink to core\"\n"+
+						"no auto-negotiate enable\nspeed 1000\nduplex full\nencapsulation dot1q\n"+
+						"default-vlan-id 10\nuntag-port-default-vlan enable\nexit", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_port" "test" {
+  port                = "1/5"
+  shutdown            = true
+  encapsulation_dot1q = false
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr("extrm-fabric-engine_port.test", "name"),
+					resource.TestCheckNoResourceAttr("extrm-fabric-engine_port.test", "speed"),
+					testCheckRunningConfig(srv, "interface gigabitEthernet 1/5\nshutdown\nexit", true),
+				),
+			},
+			{
+				ResourceName:            "extrm-fabric-engine_port.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"encapsulation_dot1q"},
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "interface gigabitEthernet 1/5", false),
+	})
+}
+
+func TestAccFabricEnginePortResource_range(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal", "vlan members remove 1 1/1-1/4", "end")
+
+	config := testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_port" "test" {
+  port_range             = "1/1-1/4"
+  flex_uni               = true
+  spanning_tree_learning = false
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_port.test", "id", "port/1/1-1/4"),
+					testCheckRunningConfig(srv, "interface gigabitEthernet 1/1\nspanning-tree mstp learning disable\nflex-uni enable\nexit", true),
+					testCheckRunningConfig(srv, "interface gigabitEthernet 1/4\nspanning-tree mstp learning disable\nflex-uni enable\nexit", true),
+				),
+			},
+			{
+				// A port of the range changed outside of Terraform is
+				// configured again.
+				PreConfig: func() {
+					srv.Exec("configure terminal", "interface gigabitEthernet 1/3", "spanning-tree mstp learning enable", "exit", "end")
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_port.test", "spanning_tree_learning", "false"),
+					testCheckRunningConfig(srv, "interface gigabitEthernet 1/3\nspanning-tree mstp learning disable\nflex-uni enable\nexit", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_port.test",
+				ImportState:       true,
+				ImportStateId:     "port/1/1-1/4",
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "flex-uni enable", false),
+	})
+}
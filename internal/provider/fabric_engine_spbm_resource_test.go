@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineSPBMResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	config := func(systemID, nickName string) string {
+		return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "extrm-fabric-engine_vlan" "bvlan1" {
+  vlan_id = 4051
+  type    = "spbm-bvlan"
+}
+
+resource "extrm-fabric-engine_vlan" "bvlan2" {
+  vlan_id = 4052
+  type    = "spbm-bvlan"
+}
+
+resource "extrm-fabric-engine_spbm" "test" {
+  system_id   = %q
+  manual_area = "49.0001"
+  nick_name   = %q
+  b_vlans     = [extrm-fabric-engine_vlan.bvlan1.vlan_id, extrm-fabric-engine_vlan.bvlan2.vlan_id]
+
+  # The B-VLANs can only be deleted once SPBM no longer uses them.
+  on_destroy = "reset_to_default"
+}
+`, systemID, nickName)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("0200.0000.0001", "0.00.01"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_spbm.test", "id", "default"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_spbm.test", "enabled", "true"),
+					testCheckRunningConfig(srv, "system-id 0200.0000.0001", true),
+					testCheckRunningConfig(srv, "spbm 1 b-vid 4051-4052 primary 4051", true),
+					testCheckRunningConfig(srv, "router isis enable", true),
+				),
+			},
+			{
+				// Changing the system ID disables IS-IS around the change.
+				Config: config("0200.0000.0002", "0.00.02"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_spbm.test", "system_id", "0200.0000.0002"),
+					testCheckRunningConfig(srv, "spbm 1 nick-name 0.00.02", true),
+					testCheckRunningConfig(srv, "router isis enable", true),
+				),
+			},
+			{
+				ResourceName:            "extrm-fabric-engine_spbm.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"on_destroy"},
+			},
+		},
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckRunningConfig(srv, "router isis enable", false),
+			testCheckRunningConfig(srv, "system-id 0200.0000.0002", false),
+			testCheckRunningConfig(srv, "spbm 1", false),
+		),
+	})
+}
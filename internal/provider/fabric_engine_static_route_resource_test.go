@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineStaticRouteResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_static_route" "test" {
+  destination = "10.1.0.0/16"
+  next_hops = [
+    { address = "192.168.1.1", weight = 1 },
+    { address = "192.168.2.1", weight = 10 },
+  ]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_static_route.test", "id", "static_route/GlobalRouter/10.1.0.0/16"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_static_route.test", "preference", "5"),
+					testCheckRunningConfig(srv, "ip route 10.1.0.0 255.255.0.0 192.168.1.1 weight 1", true),
+					testCheckRunningConfig(srv, "ip route 10.1.0.0 255.255.0.0 192.168.2.1 weight 10", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_static_route.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_static_route" "test" {
+  destination    = "10.1.0.0/16"
+  next_hops      = [{ address = "192.168.2.1", weight = 10 }]
+  preference     = 20
+  local_next_hop = false
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRunningConfig(srv, "ip route 10.1.0.0 255.255.0.0 192.168.1.1 weight 1", false),
+					testCheckRunningConfig(srv, "ip route 10.1.0.0 255.255.0.0 192.168.2.1 preference 20", true),
+					testCheckRunningConfig(srv, "no ip route 10.1.0.0 255.255.0.0 192.168.2.1 local-next-hop enable", true),
+				),
+			},
+			{
+				// A route disabled outside of Terraform is detected as drift
+				// and enabled again.
+				PreConfig: func() {
+					srv.Exec("configure terminal", "no ip route 10.1.0.0 255.255.0.0 192.168.2.1 enable", "end")
+				},
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_static_route" "test" {
+  destination    = "10.1.0.0/16"
+  next_hops      = [{ address = "192.168.2.1", weight = 10 }]
+  preference     = 20
+  local_next_hop = false
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_static_route.test", "enabled", "true"),
+					testCheckRunningConfig(srv, "no ip route 10.1.0.0 255.255.0.0 192.168.2.1 enable", false),
+				),
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "ip route 10.1.0.0 255.255.0.0 192.168.2.1 weight 10", false),
+	})
+}
+
+func TestAccFabricEngineStaticRouteResource_vrf(t *testing.T) {
+	srv := newMockDevice(t)
+	srv.Exec("configure terminal", "ip vrf red vrfid 1", "end")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_static_route" "test" {
+  destination = "0.0.0.0/0"
+  vrf         = "red"
+  next_hops   = [{ address = "10.0.0.1", weight = 1 }]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_static_route.test", "id", "static_route/red/0.0.0.0/0"),
+					testCheckRunningConfig(srv, "router vrf red\nip route 0.0.0.0 0.0.0.0 10.0.0.1 weight 1", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_static_route.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "ip route 0.0.0.0 0.0.0.0 10.0.0.1 weight 1", false),
+	})
+}
+
+func TestAccFabricEngineStaticRouteResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_static_route" "test" {
+  destination = "10.1.0.1/16"
+  next_hops   = [{ address = "192.168.1.1", weight = 1 }]
+}
+`,
+				ExpectError: regexp.MustCompile("is not a network address"),
+			},
+		},
+	})
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/mockdevice"
+)
+
+// testAccVISTSetup creates the vIST VLAN with its IP interface, MLT 1 and
+// SPBM instance 1 on srv.
+func testAccVISTSetup(srv *mockdevice.Server, systemID, address string) {
+	srv.Exec("configure terminal",
+		"vlan create 4000 type port-mstprstp 0", "interface vlan 4000", "ip address "+address, "exit",
+		"mlt 1", "spbm", "router isis", "system-id "+systemID, "spbm 1", "exit", "end")
+}
+
+func TestAccFabricEngineVISTResource(t *testing.T) {
+	srv := newMockDevice(t)
+	testAccVISTSetup(srv, "0200.0000.0001", "10.255.0.1/30")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vist" "test" {
+  peer_ip             = "10.255.0.2"
+  vlan_id             = 4000
+  smlt_peer_system_id = "0200.0000.0002"
+  smlt_virtual_bmac   = "00:00:be:ef:00:01"
+  smlt_mlt_ids        = [1]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vist.test", "id", "default"),
+					testCheckRunningConfig(srv, "virtual-ist peer-ip 10.255.0.2 vlan 4000", true),
+					testCheckRunningConfig(srv, "spbm 1 smlt-peer-system-id 0200.0000.0002", true),
+					testCheckRunningConfig(srv, "spbm 1 smlt-virt-bmac 00:00:be:ef:00:01", true),
+					testCheckRunningConfig(srv, "interface mlt 1\nsmlt\nexit", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vist" "test" {
+  peer_ip             = "10.255.0.2"
+  vlan_id             = 4000
+  smlt_peer_system_id = "0200.0000.0002"
+  smlt_virtual_bmac   = "00:00:be:ef:00:02"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vist.test", "smlt_mlt_ids.#", "0"),
+					testCheckRunningConfig(srv, "spbm 1 smlt-virt-bmac 00:00:be:ef:00:02", true),
+					testCheckRunningConfig(srv, "smlt", false),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_vist.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "virtual-ist peer-ip 10.255.0.2 vlan 4000", false),
+	})
+}
+
+func TestAccFabricEngineVISTResource_peer(t *testing.T) {
+	leaf1 := newMockDevice(t)
+	leaf2 := newMockDevice(t)
+	testAccVISTSetup(leaf1, "0200.0000.0001", "10.255.0.1/30")
+	testAccVISTSetup(leaf2, "0200.0000.0002", "10.255.0.2/30")
+
+	config := func(bmac1, bmac2 string) string {
+		return fmt.Sprintf(`
+provider "extrm-fabric-engine" {
+  username = %q
+  password = %q
+
+  devices = {
+    leaf1 = { host = %q, port = %d, host_key = %q }
+    leaf2 = { host = %q, port = %d, host_key = %q }
+  }
+}
+
+resource "extrm-fabric-engine_vist" "leaf1" {
+  device            = "leaf1"
+  peer_device       = "leaf2"
+  peer_ip           = "10.255.0.2"
+  vlan_id           = 4000
+  smlt_virtual_bmac = %q
+  smlt_mlt_ids      = [1]
+}
+
+resource "extrm-fabric-engine_vist" "leaf2" {
+  device            = "leaf2"
+  peer_device       = "leaf1"
+  peer_ip           = "10.255.0.1"
+  vlan_id           = 4000
+  smlt_virtual_bmac = %q
+  smlt_mlt_ids      = [1]
+
+  depends_on = [extrm-fabric-engine_vist.leaf1]
+}
+`, mockdevice.Username, mockdevice.Password,
+			leaf1.Host(), leaf1.Port(), leaf1.HostKey(),
+			leaf2.Host(), leaf2.Port(), leaf2.HostKey(), bmac1, bmac2)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("00:00:be:ef:00:01", "00:00:be:ef:00:01"),
+				Check: resource.ComposeTestCheckFunc(
+					// The peer system IDs default to the system ID of the other switch.
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vist.leaf1", "smlt_peer_system_id", "0200.0000.0002"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vist.leaf2", "smlt_peer_system_id", "0200.0000.0001"),
+					testCheckRunningConfig(leaf1, "virtual-ist peer-ip 10.255.0.2 vlan 4000", true),
+					testCheckRunningConfig(leaf2, "virtual-ist peer-ip 10.255.0.1 vlan 4000", true),
+					testCheckRunningConfig(leaf2, "spbm 1 smlt-virt-bmac 00:00:be:ef:00:01", true),
+				),
+			},
+			{
+				// Both switches of the cluster move to another virtual BMAC
+				// in the same run.
+				Config: config("00:00:be:ef:00:02", "00:00:be:ef:00:02"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRunningConfig(leaf1, "spbm 1 smlt-virt-bmac 00:00:be:ef:00:02", true),
+					testCheckRunningConfig(leaf2, "spbm 1 smlt-virt-bmac 00:00:be:ef:00:02", true),
+				),
+			},
+			{
+				ResourceName:            "extrm-fabric-engine_vist.leaf2",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"peer_device"},
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(leaf2, "virtual-ist peer-ip 10.255.0.1 vlan 4000", false),
+	})
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineVLANResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vlan" "test" {
+  vlan_id        = 10
+  i_sid          = 10010
+  tagged_ports   = ["1/1", "1/2"]
+  untagged_ports = ["1/5"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vlan.test", "id", "vlan/10"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vlan.test", "name", "VLAN-10"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vlan.test", "type", "port-mstprstp"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vlan.test", "stg", "0"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vlan.test", "tagged_ports.#", "2"),
+					testCheckRunningConfig(srv, `vlan create 10 name "VLAN-10" type port-mstprstp 0`, true),
+					testCheckRunningConfig(srv, "vlan members add 10 1/1-1/2,1/5", true),
+					testCheckRunningConfig(srv, "vlan i-sid 10 10010", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vlan" "test" {
+  vlan_id      = 10
+  name         = "Voice"
+  i_sid        = 10020
+  tagged_ports = ["1/2", "1/3"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vlan.test", "name", "Voice"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vlan.test", "untagged_ports.#", "0"),
+					testCheckRunningConfig(srv, "vlan members add 10 1/2-1/3", true),
+					testCheckRunningConfig(srv, "vlan i-sid 10 10020", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_vlan.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, `vlan create 10 name "Voice" type port-mstprstp 0`, false),
+	})
+}
+
+func TestAccFabricEngineVLANResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vlan" "test" {
+  vlan_id        = 10
+  tagged_ports   = ["1/1"]
+  untagged_ports = ["1/1"]
+}
+`,
+				ExpectError: regexp.MustCompile("Conflicting port tagging"),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vlan" "test" {
+  vlan_id      = 10
+  tagged_ports = ["9/1"]
+}
+`,
+				ExpectError: regexp.MustCompile("Port 9/1 does not exist"),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vlan" "test" {
+  vlan_id = 10
+  name    = "say \"hi\""
+}
+`,
+				ExpectError: regexp.MustCompile("Invalid attribute value"),
+			},
+		},
+	})
+}
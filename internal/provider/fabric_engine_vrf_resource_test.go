@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFabricEngineVRFResource(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vrf" "test" {
+  name   = "red"
+  vrf_id = 1
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vrf.test", "id", "vrf/red"),
+					resource.TestCheckNoResourceAttr("extrm-fabric-engine_vrf.test", "i_sid"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vrf.test", "ipvpn_enabled", "false"),
+					testCheckRunningConfig(srv, "ip vrf red vrfid 1", true),
+				),
+			},
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vrf" "test" {
+  name                = "red"
+  vrf_id              = 1
+  i_sid               = 30001
+  redistribute_direct = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vrf.test", "i_sid", "30001"),
+					resource.TestCheckResourceAttr("extrm-fabric-engine_vrf.test", "ipvpn_enabled", "true"),
+					testCheckRunningConfig(srv, "i-sid 30001", true),
+					testCheckRunningConfig(srv, "ipvpn enable", true),
+					testCheckRunningConfig(srv, "isis redistribute direct enable", true),
+				),
+			},
+			{
+				ResourceName:      "extrm-fabric-engine_vrf.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// A VLAN interface attached to the VRF outside of Terraform is
+				// reported when planning and blocks its deletion.
+				PreConfig: func() {
+					srv.Exec("configure terminal", "vlan create 10 type port-mstprstp 0",
+						"interface vlan 10", "vrf red", "end")
+				},
+				Config:      testAccProviderConfig(srv),
+				ExpectError: regexp.MustCompile("VRF red cannot be deleted while interfaces are attached"),
+			},
+			{
+				PreConfig: func() {
+					srv.Exec("configure terminal", "interface vlan 10", "no vrf", "end")
+				},
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vrf" "test" {
+  name   = "red"
+  vrf_id = 1
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRunningConfig(srv, "ipvpn", false),
+					testCheckRunningConfig(srv, "isis redistribute direct", false),
+				),
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "ip vrf red vrfid 1", false),
+	})
+}
+
+func TestAccFabricEngineVRFResource_interfaces(t *testing.T) {
+	srv := newMockDevice(t)
+
+	// The VRF is destroyed together with its interface, which Terraform
+	// deletes first.
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vrf" "test" {
+  name   = "red"
+  vrf_id = 1
+}
+
+resource "extrm-fabric-engine_vlan" "test" {
+  vlan_id = 10
+}
+
+resource "extrm-fabric-engine_ip_interface" "test" {
+  vlan_id      = extrm-fabric-engine_vlan.test.vlan_id
+  vrf          = extrm-fabric-engine_vrf.test.name
+  ipv4_address = "10.0.10.1/24"
+}
+`,
+				Check: testCheckRunningConfig(srv, "vrf red", true),
+			},
+		},
+		CheckDestroy: testCheckRunningConfig(srv, "ip vrf red vrfid 1", false),
+	})
+}
+
+func TestAccFabricEngineVRFResource_invalid(t *testing.T) {
+	srv := newMockDevice(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "extrm-fabric-engine_vrf" "test" {
+  name          = "red"
+  vrf_id        = 1
+  ipvpn_enabled = true
+}
+`,
+				ExpectError: regexp.MustCompile("i_sid is required to enable IP VPN"),
+			},
+		},
+	})
+}
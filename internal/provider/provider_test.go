@@ -1,11 +1,59 @@
 package provider
 
 import (
+	"fmt"
+	"strings"
+	"testing"
+
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	provider2 "github.com/tchevalleraud/extrm-fabric-engine/internal"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/mockdevice"
 )
 
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"xtrm-fabric-engine": providerserver.NewProtocol6WithError(provider2.New("test")()),
+	"extrm-fabric-engine": providerserver.NewProtocol6WithError(provider2.New("test")()),
+}
+
+// newMockDevice starts an emulated Fabric Engine switch for the duration of
+// the test.
+func newMockDevice(t *testing.T) *mockdevice.Server {
+	t.Helper()
+
+	srv, err := mockdevice.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+// testAccProviderConfig returns a provider block targeting srv.
+func testAccProviderConfig(srv *mockdevice.Server) string {
+	return fmt.Sprintf(`
+provider "extrm-fabric-engine" {
+  host     = %q
+  port     = %d
+  username = %q
+  password = %q
+  host_key = %q
+}
+`, srv.Host(), srv.Port(), mockdevice.Username, mockdevice.Password, srv.HostKey())
+}
+
+// testCheckRunningConfig checks whether the running configuration of srv
+// contains line.
+func testCheckRunningConfig(srv *mockdevice.Server, line string, present bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		cfg := srv.RunningConfig()
+		if found := strings.Contains(cfg, line+"\n"); found != present {
+			if present {
+				return fmt.Errorf("line %q missing from the running configuration:\n%s", line, cfg)
+			}
+			return fmt.Errorf("unexpected line %q in the running configuration:\n%s", line, cfg)
+		}
+		return nil
+	}
 }
@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ParsePrivateKey parses a PEM encoded private key, decrypting it with
+// passphrase when it is protected.
+func ParsePrivateKey(pemBytes []byte, passphrase string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(pemBytes)
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == "" {
+			return nil, errors.New("private key is protected by a passphrase but none was given")
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	return signer, nil
+}
+
+// HasAuth reports whether c enables at least one authentication method.
+func (c Config) HasAuth() bool {
+	return c.Password != "" || len(c.Signers) > 0 || c.UseAgent
+}
+
+// authMethods returns the SSH authentication methods enabled by c, in the
+// order they are tried. The returned function releases the agent connection
+// and must be called once the handshake is complete.
+func (c Config) authMethods() ([]ssh.AuthMethod, func(), error) {
+	var methods []ssh.AuthMethod
+	release := func() {}
+
+	signers := c.Signers
+	if c.UseAgent {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, release, errors.New("SSH agent requested but SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil, release, fmt.Errorf("connecting to SSH agent: %w", err)
+		}
+		release = func() { conn.Close() }
+
+		agentSigners, err := agent.NewClient(conn).Signers()
+		if err != nil {
+			release()
+			return nil, func() {}, fmt.Errorf("listing SSH agent keys: %w", err)
+		}
+		signers = append(append([]ssh.Signer{}, signers...), agentSigners...)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	if c.Password != "" {
+		methods = append(methods, ssh.Password(c.Password))
+		if c.KeyboardInteractive {
+			methods = append(methods, ssh.KeyboardInteractive(c.answerChallenge))
+		}
+	}
+	return methods, release, nil
+}
+
+// answerChallenge answers every keyboard-interactive question with the
+// password, which is what Fabric Engine asks for.
+func (c Config) answerChallenge(_, _ string, questions []string, _ []bool) ([]string, error) {
+	answers := make([]string, len(questions))
+	for i := range questions {
+		answers[i] = c.Password
+	}
+	return answers, nil
+}
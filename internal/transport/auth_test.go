@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func newTestPEM(t *testing.T, passphrase string) ([]byte, ed25519.PrivateKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block), priv
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	plain, _ := newTestPEM(t, "")
+	if _, err := ParsePrivateKey(plain, ""); err != nil {
+		t.Errorf("plain key: %v", err)
+	}
+
+	protected, _ := newTestPEM(t, "s3cret")
+	if _, err := ParsePrivateKey(protected, "s3cret"); err != nil {
+		t.Errorf("protected key: %v", err)
+	}
+	if _, err := ParsePrivateKey(protected, ""); err == nil {
+		t.Error("protected key without passphrase should fail")
+	}
+	if _, err := ParsePrivateKey(protected, "wrong"); err == nil {
+		t.Error("protected key with wrong passphrase should fail")
+	}
+	if _, err := ParsePrivateKey([]byte("garbage"), ""); err == nil {
+		t.Error("garbage should fail")
+	}
+}
+
+func TestAuthMethods(t *testing.T) {
+	if (Config{}).HasAuth() {
+		t.Error("empty config should have no authentication method")
+	}
+
+	methods, release, err := Config{Password: "pw", KeyboardInteractive: true}.authMethods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if len(methods) != 2 {
+		t.Errorf("got %d methods, want password and keyboard-interactive", len(methods))
+	}
+
+	answers, err := Config{Password: "pw"}.answerChallenge("", "", []string{"Password:", "Again:"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answers) != 2 || answers[0] != "pw" || answers[1] != "pw" {
+		t.Errorf("unexpected answers %q", answers)
+	}
+}
+
+func TestAuthMethodsAgent(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer l.Close()
+
+	_, priv := newTestPEM(t, "")
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: priv}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = agent.ServeAgent(keyring, c)
+				c.Close()
+			}()
+		}
+	}()
+
+	t.Setenv("SSH_AUTH_SOCK", sock)
+	methods, release, err := Config{UseAgent: true}.authMethods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if len(methods) != 1 {
+		t.Errorf("got %d methods, want public key only", len(methods))
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if _, _, err := (Config{UseAgent: true}).authMethods(); err == nil {
+		t.Error("agent without SSH_AUTH_SOCK should fail")
+	}
+}
@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// DefaultOnboardingISID is the auto-sense onboarding I-SID of a device
+// without "auto-sense onboarding i-sid".
+const DefaultOnboardingISID = 15999999
+
+// DefaultAutoSense is the auto-sense configuration of a device with factory
+// default settings.
+var DefaultAutoSense = AutoSense{OnboardingISID: DefaultOnboardingISID}
+
+// AutoSenseService is an I-SID and the C-VID auto-sense ports map to it.
+type AutoSenseService struct {
+	ISID int32
+	CVID int32
+}
+
+// AutoSense is the global configuration of the auto-sense ports.
+type AutoSense struct {
+	// OnboardingISID is set with "auto-sense onboarding i-sid".
+	OnboardingISID int32
+	// Voice is set with "auto-sense voice i-sid", nil if none.
+	Voice *AutoSenseService
+	// Data are set with "auto-sense data i-sid", sorted by I-SID.
+	Data []AutoSenseService
+}
+
+// autoSenseRe matches a row of "show auto-sense".
+var autoSenseRe = regexp.MustCompile(`(?m)^(onboarding|voice|data)[ \t]+(\d+)[ \t]+(\d+|--)[ \t]*$`)
+
+// AutoSense reads the global auto-sense configuration from
+// "show auto-sense".
+func (c *Client) AutoSense(ctx context.Context) (*AutoSense, error) {
+	outputs, err := c.Run(ctx, "show auto-sense")
+	if err != nil {
+		return nil, err
+	}
+
+	a := &AutoSense{}
+	for _, m := range autoSenseRe.FindAllStringSubmatch(outputs[0], -1) {
+		isid, _ := strconv.Atoi(m[2])
+		cvid, _ := strconv.Atoi(m[3])
+		s := AutoSenseService{ISID: int32(isid), CVID: int32(cvid)}
+		switch m[1] {
+		case "onboarding":
+			a.OnboardingISID = s.ISID
+		case "voice":
+			a.Voice = &s
+		case "data":
+			a.Data = append(a.Data, s)
+		}
+	}
+	if a.OnboardingISID == 0 {
+		return nil, fmt.Errorf("could not find the onboarding I-SID in the output of \"show auto-sense\":\n%s", outputs[0])
+	}
+	sort.Slice(a.Data, func(i, j int) bool { return a.Data[i].ISID < a.Data[j].ISID })
+	return a, nil
+}
+
+// ApplyAutoSense changes the global auto-sense configuration from old to a.
+// The services that change are removed before any is added, so I-SIDs and
+// C-VIDs can move from one service to another.
+func (c *Client) ApplyAutoSense(ctx context.Context, old, a AutoSense) error {
+	var cmds []string
+	voiceChanged := (old.Voice == nil) != (a.Voice == nil) || (a.Voice != nil && *a.Voice != *old.Voice)
+	if voiceChanged && old.Voice != nil {
+		cmds = append(cmds, "no auto-sense voice i-sid")
+	}
+	for _, s := range old.Data {
+		if !hasAutoSenseService(a.Data, s) {
+			cmds = append(cmds, fmt.Sprintf("no auto-sense data i-sid %d", s.ISID))
+		}
+	}
+
+	if a.OnboardingISID != old.OnboardingISID {
+		cmds = append(cmds, fmt.Sprintf("auto-sense onboarding i-sid %d", a.OnboardingISID))
+	}
+	if voiceChanged && a.Voice != nil {
+		cmds = append(cmds, fmt.Sprintf("auto-sense voice i-sid %d c-vid %d", a.Voice.ISID, a.Voice.CVID))
+	}
+	for _, s := range a.Data {
+		if !hasAutoSenseService(old.Data, s) {
+			cmds = append(cmds, fmt.Sprintf("auto-sense data i-sid %d c-vid %d", s.ISID, s.CVID))
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+func hasAutoSenseService(services []AutoSenseService, s AutoSenseService) bool {
+	for _, other := range services {
+		if other == s {
+			return true
+		}
+	}
+	return false
+}
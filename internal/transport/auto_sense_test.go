@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAutoSense(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	got, err := c.AutoSense(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, DefaultAutoSense) {
+		t.Fatalf("got %+v, want the default %+v", *got, DefaultAutoSense)
+	}
+
+	want := AutoSense{
+		OnboardingISID: 15999000,
+		Voice:          &AutoSenseService{ISID: 20000, CVID: 20},
+		Data:           []AutoSenseService{{ISID: 30000, CVID: 30}, {ISID: 30001, CVID: 31}},
+	}
+	if err := c.ApplyAutoSense(ctx, *got, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = c.AutoSense(ctx); err != nil || !reflect.DeepEqual(*got, want) {
+		t.Fatalf("got %+v, %v, want %+v", got, err, want)
+	}
+	if !strings.Contains(srv.RunningConfig(), "auto-sense voice i-sid 20000 c-vid 20\n") {
+		t.Errorf("voice I-SID missing from the running configuration:\n%s", srv.RunningConfig())
+	}
+
+	// The C-VIDs of the data I-SIDs are swapped and the voice I-SID becomes a
+	// data I-SID, which requires removing the old services first.
+	updated := AutoSense{
+		OnboardingISID: 15999000,
+		Data:           []AutoSenseService{{ISID: 20000, CVID: 20}, {ISID: 30000, CVID: 31}, {ISID: 30001, CVID: 30}},
+	}
+	if err := c.ApplyAutoSense(ctx, want, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.AutoSense(ctx); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+
+	// An I-SID used by another service is rejected by the device.
+	if err := c.CreateVLAN(ctx, VLAN{ID: 10, Type: VLANTypePort, ISID: 40000}); err != nil {
+		t.Fatal(err)
+	}
+	conflict := updated
+	conflict.Voice = &AutoSenseService{ISID: 40000, CVID: 40}
+	if err := c.ApplyAutoSense(ctx, updated, conflict); err == nil {
+		t.Error("no error using the I-SID of VLAN 10")
+	}
+
+	if err := c.ApplyAutoSense(ctx, updated, DefaultAutoSense); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := srv.RunningConfig(); strings.Contains(cfg, "auto-sense") {
+		t.Errorf("auto-sense configuration left:\n%s", cfg)
+	}
+}
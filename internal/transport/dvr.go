@@ -0,0 +1,165 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DVR roles, as given to "dvr controller" and "dvr leaf".
+const (
+	DVRRoleController = "controller"
+	DVRRoleLeaf       = "leaf"
+)
+
+// DVRInterface is the Distributed Virtual Routing configuration of a VLAN
+// interface of a DVR controller.
+type DVRInterface struct {
+	VLAN int32
+	// GatewayIPv4 is the gateway shared by the controllers of the domain, set
+	// with "dvr gw-ipv4".
+	GatewayIPv4 string
+	// Enabled reports whether "dvr enable" is set.
+	Enabled bool
+}
+
+// DVR is the Distributed Virtual Routing configuration of the device.
+type DVR struct {
+	// Role is DVRRoleController or DVRRoleLeaf.
+	Role     string
+	DomainID int32
+	// Injection and InjectDefaultRoute are only supported on a controller,
+	// where they are enabled by default. They are false on a leaf.
+	Injection          bool
+	InjectDefaultRoute bool
+	// Interfaces are the VLAN interfaces with a DVR gateway, sorted by VLAN.
+	// Only a controller has any.
+	Interfaces []DVRInterface
+}
+
+var (
+	// dvrRoleRe, dvrDomainRe, dvrInjectionRe and dvrDefaultRouteRe match the
+	// fields of "show dvr".
+	dvrRoleRe         = regexp.MustCompile(`(?m)^\s*Role\s*:\s*(\S+)`)
+	dvrDomainRe       = regexp.MustCompile(`(?m)^\s*Domain ID\s*:\s*(\d+)`)
+	dvrInjectionRe    = regexp.MustCompile(`(?m)^\s*Injection\s*:\s*(\S+)`)
+	dvrDefaultRouteRe = regexp.MustCompile(`(?m)^\s*Inject Default Route\s*:\s*(\S+)`)
+	// dvrInterfaceRe matches a row of "show dvr interfaces".
+	dvrInterfaceRe = regexp.MustCompile(`(?m)^Vlan(\d+)[ \t]+\S+[ \t]+\S+[ \t]+(\d+\.\d+\.\d+\.\d+)[ \t]+(enable|disable)[ \t]*$`)
+)
+
+// DVR reads the DVR configuration from "show dvr" and "show dvr interfaces".
+// It returns nil if the device is neither a controller nor a leaf.
+func (c *Client) DVR(ctx context.Context) (*DVR, error) {
+	outputs, err := c.Run(ctx, "show dvr", "show dvr interfaces")
+	if err != nil {
+		return nil, err
+	}
+
+	m := dvrRoleRe.FindStringSubmatch(outputs[0])
+	if m == nil {
+		return nil, fmt.Errorf("could not find the DVR role in the output of \"show dvr\":\n%s", outputs[0])
+	}
+	role := strings.ToLower(m[1])
+	if role != DVRRoleController && role != DVRRoleLeaf {
+		return nil, nil
+	}
+	d := &DVR{Role: role}
+	if m := dvrDomainRe.FindStringSubmatch(outputs[0]); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		d.DomainID = int32(id)
+	}
+	if m := dvrInjectionRe.FindStringSubmatch(outputs[0]); m != nil {
+		d.Injection = m[1] == "enabled"
+	}
+	if m := dvrDefaultRouteRe.FindStringSubmatch(outputs[0]); m != nil {
+		d.InjectDefaultRoute = m[1] == "enabled"
+	}
+	for _, m := range dvrInterfaceRe.FindAllStringSubmatch(outputs[1], -1) {
+		vlan, _ := strconv.Atoi(m[1])
+		d.Interfaces = append(d.Interfaces, DVRInterface{VLAN: int32(vlan), GatewayIPv4: m[2], Enabled: m[3] == "enable"})
+	}
+	sort.Slice(d.Interfaces, func(i, j int) bool { return d.Interfaces[i].VLAN < d.Interfaces[j].VLAN })
+	return d, nil
+}
+
+// ApplyDVR changes the DVR configuration from old to d, or creates it if old
+// is nil. The role and domain ID cannot be changed in place.
+func (c *Client) ApplyDVR(ctx context.Context, old *DVR, d DVR) error {
+	var cmds []string
+	if old == nil {
+		old = &DVR{Role: d.Role, DomainID: d.DomainID}
+		cmds = append(cmds, fmt.Sprintf("dvr %s %d", d.Role, d.DomainID))
+		if d.Role == DVRRoleController {
+			old.Injection, old.InjectDefaultRoute = true, true
+		}
+	}
+	if d.Injection != old.Injection {
+		cmds = append(cmds, negate(!d.Injection, "dvr injection"))
+	}
+	if d.InjectDefaultRoute != old.InjectDefaultRoute {
+		cmds = append(cmds, negate(!d.InjectDefaultRoute, "dvr inject-default-route"))
+	}
+	cmds = append(cmds, dvrInterfaceCommands(old.Interfaces, d.Interfaces)...)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// DeleteDVR removes DVR from the VLAN interfaces, then the role of the
+// device.
+func (c *Client) DeleteDVR(ctx context.Context, d DVR) error {
+	cmds := dvrInterfaceCommands(d.Interfaces, nil)
+	return c.Configure(ctx, append(cmds, "no dvr "+d.Role)...)
+}
+
+// dvrInterfaceCommands returns the commands changing the DVR settings of the
+// VLAN interfaces from old to ifs. DVR is disabled on an interface before its
+// gateway changes.
+func dvrInterfaceCommands(old, ifs []DVRInterface) []string {
+	existing := map[int32]DVRInterface{}
+	for _, i := range old {
+		existing[i.VLAN] = i
+	}
+	wanted := map[int32]DVRInterface{}
+	var vlans []int32
+	for _, i := range ifs {
+		wanted[i.VLAN] = i
+		vlans = append(vlans, i.VLAN)
+	}
+	for _, i := range old {
+		if _, ok := wanted[i.VLAN]; !ok {
+			vlans = append(vlans, i.VLAN)
+		}
+	}
+	sort.Slice(vlans, func(i, j int) bool { return vlans[i] < vlans[j] })
+
+	var cmds []string
+	for _, vlan := range vlans {
+		o, hadDVR := existing[vlan]
+		n, wantDVR := wanted[vlan]
+		gatewayChanged := !hadDVR || !wantDVR || o.GatewayIPv4 != n.GatewayIPv4
+
+		var sub []string
+		if o.Enabled && (!n.Enabled || gatewayChanged) {
+			sub = append(sub, "no dvr enable")
+		}
+		switch {
+		case !wantDVR:
+			sub = append(sub, "no dvr gw-ipv4")
+		case gatewayChanged:
+			sub = append(sub, "dvr gw-ipv4 "+n.GatewayIPv4)
+		}
+		if n.Enabled && (!o.Enabled || gatewayChanged) {
+			sub = append(sub, "dvr enable")
+		}
+		if len(sub) > 0 {
+			cmds = append(append(append(cmds, fmt.Sprintf("interface vlan %d", vlan)), sub...), "exit")
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDVR(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+	srv.Exec("configure terminal",
+		"vlan create 10 type port-mstprstp 0", "vlan i-sid 10 10010", "interface vlan 10", "ip address 10.0.10.2/24", "exit",
+		"vlan create 20 type port-mstprstp 0", "vlan i-sid 20 10020", "interface vlan 20", "ip address 10.0.20.2/24", "exit",
+		"end")
+
+	if d, err := c.DVR(ctx); err != nil || d != nil {
+		t.Fatalf("got %v, %v without DVR", d, err)
+	}
+
+	want := DVR{
+		Role:               DVRRoleController,
+		DomainID:           5,
+		Injection:          false,
+		InjectDefaultRoute: true,
+		Interfaces: []DVRInterface{
+			{VLAN: 10, GatewayIPv4: "10.0.10.1", Enabled: true},
+			{VLAN: 20, GatewayIPv4: "10.0.20.1"},
+		},
+	}
+	if err := c.ApplyDVR(ctx, nil, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.DVR(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+	cfg := srv.RunningConfig()
+	for _, line := range []string{"dvr controller 5\n", "no dvr injection\n", "dvr gw-ipv4 10.0.10.1\n", "dvr enable\n"} {
+		if !strings.Contains(cfg, line) {
+			t.Errorf("missing %q in:\n%s", line, cfg)
+		}
+	}
+
+	// The gateway of an enabled interface changes, the other interface is
+	// removed.
+	updated := want
+	updated.Injection = true
+	updated.Interfaces = []DVRInterface{{VLAN: 10, GatewayIPv4: "10.0.10.254", Enabled: true}}
+	if err := c.ApplyDVR(ctx, &want, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.DVR(ctx); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+
+	if err := c.DeleteDVR(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := c.DVR(ctx); err != nil || d != nil {
+		t.Errorf("got %v, %v after deleting DVR", d, err)
+	}
+	if cfg := srv.RunningConfig(); strings.Contains(cfg, "dvr") {
+		t.Errorf("DVR configuration left:\n%s", cfg)
+	}
+
+	leaf := DVR{Role: DVRRoleLeaf, DomainID: 5}
+	if err := c.ApplyDVR(ctx, nil, leaf); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.DVR(ctx); !reflect.DeepEqual(*got, leaf) {
+		t.Errorf("got %+v, want %+v", *got, leaf)
+	}
+}
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// errorMarkers are the prefixes Fabric Engine prints on a line of its own when
+// it rejects a command.
+var errorMarkers = []string{
+	"% Invalid input detected",
+	"% Incomplete command",
+	"% Ambiguous command",
+	"% Unrecognized command",
+	"% Permission denied",
+	"% Cannot",
+	"% Not allowed",
+	"% Bad ",
+	"% Error",
+	"Error:",
+	"Error :",
+}
+
+// secretRes match the commands carrying a secret, captured by the first
+// submatch.
+var secretRes = []*regexp.Regexp{
+	regexp.MustCompile(`^isis hello-auth type \S+ key (\S+)`),
+	regexp.MustCompile(`^fa authentication-key (\S+)`),
+}
+
+// redact returns text with the secrets carried by cmd masked, so that errors
+// can quote the command and the device response without leaking them.
+func redact(cmd, text string) string {
+	for _, re := range secretRes {
+		if m := re.FindStringSubmatch(cmd); m != nil {
+			text = strings.ReplaceAll(text, m[1], "****")
+		}
+	}
+	return text
+}
+
+// CommandError is returned when the device answers a command with an error
+// message instead of executing it. Secrets such as authentication keys are
+// masked in all its fields.
+type CommandError struct {
+	// Command is the command that was rejected.
+	Command string
+	// Output is the full response of the device to the command.
+	Output string
+	// Message is the line of Output that identified the error.
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("command %q rejected by device: %s", e.Command, e.Message)
+}
+
+// checkOutput returns a *CommandError if output contains one of the Fabric
+// Engine error markers.
+func checkOutput(cmd, output string) error {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, marker := range errorMarkers {
+			if strings.HasPrefix(line, marker) {
+				return &CommandError{
+					Command: redact(cmd, cmd),
+					Output:  redact(cmd, output),
+					Message: redact(cmd, line),
+				}
+			}
+		}
+	}
+	return nil
+}
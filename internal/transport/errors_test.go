@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckOutput(t *testing.T) {
+	rejected := map[string]string{
+		"sys nam LAB":      "                  ^\n% Invalid input detected at '^' marker.",
+		"vlan create":      "% Incomplete command.",
+		"vlan delete 4095": "Error: VLAN 4095 does not exist",
+	}
+	for cmd, output := range rejected {
+		err := checkOutput(cmd, output)
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) {
+			t.Errorf("%q: got %v, want *CommandError", cmd, err)
+			continue
+		}
+		if cmdErr.Command != cmd || cmdErr.Output != output {
+			t.Errorf("%q: unexpected error %+v", cmd, cmdErr)
+		}
+		if !strings.Contains(err.Error(), cmd) {
+			t.Errorf("%q: error %q does not name the command", cmd, err)
+		}
+	}
+
+	accepted := []string{
+		"",
+		"SysName          : LAB-VOSS01",
+		"Save config to file /intflash/config.cfg successful.",
+		"WARNING: Card not present",
+	}
+	for _, output := range accepted {
+		if err := checkOutput("cmd", output); err != nil {
+			t.Errorf("%q: unexpected error %v", output, err)
+		}
+	}
+}
+
+// scriptedWriter answers every line written to it with the next reply.
+type scriptedWriter struct {
+	out     *shellBuffer
+	replies []string
+}
+
+func (w *scriptedWriter) Write(p []byte) (int, error) {
+	reply := w.replies[0]
+	w.replies = w.replies[1:]
+	_, _ = w.out.Write([]byte(strings.TrimSuffix(string(p), "\n") + "\r\n" + reply))
+	return len(p), nil
+}
+
+func (w *scriptedWriter) Close() error { return nil }
+
+func TestSessionConfigureRejected(t *testing.T) {
+	out := newShellBuffer()
+	w := &scriptedWriter{out: out, replies: []string{
+		"LAB:1(config)#",
+		"% Invalid input detected at '^' marker.\r\nLAB:1(config)#",
+		"LAB:1#",
+	}}
+	s := &Session{out: out, stdin: w, timeout: time.Second}
+
+	err := s.Configure(context.Background(), "sys nam LAB2")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("got %v, want *CommandError", err)
+	}
+	if cmdErr.Command != "sys nam LAB2" {
+		t.Errorf("unexpected command %q", cmdErr.Command)
+	}
+	if s.Prompt() != "LAB:1#" {
+		t.Errorf("session left in %q, want privileged mode", s.Prompt())
+	}
+	if len(w.replies) != 0 {
+		t.Errorf("%d replies left unused; configuration should not be saved", len(w.replies))
+	}
+}
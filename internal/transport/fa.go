@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+)
+
+// FAInterface is the Fabric Attach configuration of a port or an MLT.
+type FAInterface struct {
+	Interface
+	// MessageAuthentication reports whether "fa message-authentication" is
+	// set.
+	MessageAuthentication bool
+	// AuthKey is set with "fa authentication-key", empty for the default key.
+	// The device does not show it, so it is never read back.
+	AuthKey string
+}
+
+// faInterfaceRe matches a row of "show fa interface".
+var faInterfaceRe = regexp.MustCompile(`(?m)^(\S+)[ \t]+(?:enabled|disabled)[ \t]+(?:enabled|disabled)[ \t]+(enabled|disabled)`)
+
+// FAInterface reads the Fabric Attach configuration of iface from
+// "show fa interface". It returns nil if Fabric Attach is not enabled on the
+// interface.
+func (c *Client) FAInterface(ctx context.Context, iface Interface) (*FAInterface, error) {
+	outputs, err := c.Run(ctx, "show fa interface")
+	if err != nil {
+		return nil, err
+	}
+
+	name := iface.ifIndexName()
+	for _, m := range faInterfaceRe.FindAllStringSubmatch(outputs[0], -1) {
+		if m[1] == name {
+			return &FAInterface{Interface: iface, MessageAuthentication: m[2] == "enabled"}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ApplyFAInterface changes the Fabric Attach configuration of the interface
+// from old to f. A nil old enables Fabric Attach on the interface.
+func (c *Client) ApplyFAInterface(ctx context.Context, old *FAInterface, f FAInterface) error {
+	var cmds []string
+	if old == nil {
+		old = &FAInterface{Interface: f.Interface, MessageAuthentication: true}
+		cmds = append(cmds, "fa enable")
+	}
+	if f.AuthKey != old.AuthKey {
+		if f.AuthKey == "" {
+			cmds = append(cmds, "no fa authentication-key")
+		} else {
+			cmds = append(cmds, "fa authentication-key "+f.AuthKey)
+		}
+	}
+	if f.MessageAuthentication != old.MessageAuthentication {
+		cmds = append(cmds, negate(!f.MessageAuthentication, "fa message-authentication"))
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	cmds = append([]string{f.command()}, cmds...)
+	return c.Configure(ctx, append(cmds, "exit")...)
+}
+
+// DeleteFAInterface disables Fabric Attach on the interface, which also
+// removes its settings.
+func (c *Client) DeleteFAInterface(ctx context.Context, iface Interface) error {
+	return c.Configure(ctx, iface.command(), "no fa enable", "exit")
+}
+
+// FAElement is a Fabric Attach client or proxy discovered on a port.
+type FAElement struct {
+	Port string
+	// Type is the element type, e.g. "proxy" or "clientWapType1".
+	Type string
+	// VLAN is the management VLAN of the element.
+	VLAN     int32
+	SystemID string
+	// State is "trusted" or "untrusted", depending on the message
+	// authentication of the element.
+	State string
+}
+
+// FAAssignment is an I-SID/VLAN binding requested by a Fabric Attach element.
+type FAAssignment struct {
+	Port string
+	ISID int32
+	VLAN int32
+	// State is "active", "pending" or "rejected".
+	State string
+}
+
+// FADiscovery holds the Fabric Attach elements connected to the device and
+// the assignments they requested.
+type FADiscovery struct {
+	Elements    []FAElement
+	Assignments []FAAssignment
+}
+
+var (
+	// faElementRe matches a row of "show fa elements".
+	faElementRe = regexp.MustCompile(`(?m)^(\d+/\d+(?:/\d+)?)[ \t]+(\S+)[ \t]+(\d+)[ \t]+([0-9a-fA-F:]+)[ \t]+(trusted|untrusted)[ \t]*$`)
+	// faAssignmentRe matches a row of "show fa assignment".
+	faAssignmentRe = regexp.MustCompile(`(?m)^(\d+/\d+(?:/\d+)?)[ \t]+(\d+)[ \t]+(\d+)[ \t]+(active|pending|rejected)[ \t]*$`)
+)
+
+// FADiscovery reads the discovered elements and their assignments from
+// "show fa elements" and "show fa assignment".
+func (c *Client) FADiscovery(ctx context.Context) (*FADiscovery, error) {
+	outputs, err := c.Run(ctx, "show fa elements", "show fa assignment")
+	if err != nil {
+		return nil, err
+	}
+
+	d := &FADiscovery{}
+	for _, m := range faElementRe.FindAllStringSubmatch(outputs[0], -1) {
+		vlan, _ := strconv.Atoi(m[3])
+		d.Elements = append(d.Elements, FAElement{Port: m[1], Type: m[2], VLAN: int32(vlan), SystemID: m[4], State: m[5]})
+	}
+	for _, m := range faAssignmentRe.FindAllStringSubmatch(outputs[1], -1) {
+		isid, _ := strconv.Atoi(m[2])
+		vlan, _ := strconv.Atoi(m[3])
+		d.Assignments = append(d.Assignments, FAAssignment{Port: m[1], ISID: int32(isid), VLAN: int32(vlan), State: m[4]})
+	}
+	return d, nil
+}
@@ -0,0 +1,140 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/mockdevice"
+)
+
+func TestFAInterface(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+	if err := c.Configure(ctx, "mlt 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, iface := range []Interface{{Port: "1/1"}, {MLT: 2}} {
+		if got, err := c.FAInterface(ctx, iface); err != nil || got != nil {
+			t.Fatalf("%s: got %+v, %v before enabling Fabric Attach", iface, got, err)
+		}
+
+		want := FAInterface{Interface: iface, MessageAuthentication: true, AuthKey: "secret"}
+		if err := c.ApplyFAInterface(ctx, nil, want); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.FAInterface(ctx, iface)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The key is never shown by the device.
+		want.AuthKey = ""
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("%s: got %+v, want %+v", iface, *got, want)
+		}
+
+		updated := FAInterface{Interface: iface}
+		if err := c.ApplyFAInterface(ctx, &FAInterface{Interface: iface, MessageAuthentication: true, AuthKey: "secret"}, updated); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := c.FAInterface(ctx, iface); !reflect.DeepEqual(*got, updated) {
+			t.Errorf("%s: got %+v, want %+v", iface, *got, updated)
+		}
+		cfg := srv.RunningConfig()
+		if !strings.Contains(cfg, "no fa message-authentication\n") || strings.Contains(cfg, "fa authentication-key") {
+			t.Errorf("%s: unexpected running configuration:\n%s", iface, cfg)
+		}
+
+		if err := c.DeleteFAInterface(ctx, iface); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := c.FAInterface(ctx, iface); err != nil || got != nil {
+			t.Errorf("%s: got %+v, %v after disabling Fabric Attach", iface, got, err)
+		}
+	}
+
+	// Auto-sense ports manage Fabric Attach themselves.
+	if err := c.ApplyPortConfig(ctx, []string{"1/2"}, DefaultPortConfig, PortConfig{
+		AutoNegotiation: true, DefaultVLAN: 1, SpanningTreeLearning: true, AutoSense: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ApplyFAInterface(ctx, nil, FAInterface{Interface: Interface{Port: "1/2"}, MessageAuthentication: true}); err == nil {
+		t.Error("no error enabling Fabric Attach on an auto-sense port")
+	}
+}
+
+func TestFAInterfaceRejectedKey(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	srv.Reject("fa authentication-key", "Error: Invalid key s3cr3t-key")
+	err := c.ApplyFAInterface(ctx, nil, FAInterface{
+		Interface:             Interface{Port: "1/1"},
+		MessageAuthentication: true,
+		AuthKey:               "s3cr3t-key",
+	})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("got %v, want *CommandError", err)
+	}
+	for _, text := range []string{err.Error(), cmdErr.Command, cmdErr.Output, cmdErr.Message} {
+		if strings.Contains(text, "s3cr3t-key") {
+			t.Errorf("key leaked in %q", text)
+		}
+	}
+	if want := "fa authentication-key ****"; cmdErr.Command != want {
+		t.Errorf("got command %q, want %q", cmdErr.Command, want)
+	}
+}
+
+func TestFADiscovery(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	if d, err := c.FADiscovery(ctx); err != nil || len(d.Elements) != 0 || len(d.Assignments) != 0 {
+		t.Fatalf("got %+v, %v without Fabric Attach elements", d, err)
+	}
+
+	srv.Exec("configure terminal", "interface gigabitEthernet 1/5", "fa enable", "exit", "end")
+	err := srv.DiscoverFAElement(mockdevice.FAElement{
+		Port:     "1/5",
+		Type:     "proxy",
+		VLAN:     4048,
+		SystemID: "f4:ce:46:aa:bb:cc:00:00:00:01",
+		Trusted:  true,
+		Assignments: []mockdevice.FAAssignment{
+			{ISID: 10100, VLAN: 100, State: "active"},
+			{ISID: 10200, VLAN: 200, State: "rejected"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.FADiscovery(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FADiscovery{
+		Elements: []FAElement{{Port: "1/5", Type: "proxy", VLAN: 4048, SystemID: "f4:ce:46:aa:bb:cc:00:00:00:01", State: "trusted"}},
+		Assignments: []FAAssignment{
+			{Port: "1/5", ISID: 10100, VLAN: 100, State: "active"},
+			{Port: "1/5", ISID: 10200, VLAN: 200, State: "rejected"},
+		},
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	// The elements are gone once Fabric Attach is disabled on their port.
+	if err := c.DeleteFAInterface(ctx, Interface{Port: "1/5"}); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := c.FADiscovery(ctx); err != nil || len(d.Elements) != 0 || len(d.Assignments) != 0 {
+		t.Errorf("got %+v, %v after disabling Fabric Attach", d, err)
+	}
+}
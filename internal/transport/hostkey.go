@@ -0,0 +1,186 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// HostKeyConfig describes how the host key presented by a device is verified.
+type HostKeyConfig struct {
+	// KnownHostsFile is the path of an OpenSSH known_hosts file.
+	KnownHostsFile string
+	// PinnedKey is either a public key in authorized_keys format or a
+	// "SHA256:" fingerprint as printed by ssh-keygen -l.
+	PinnedKey string
+	// TrustOnFirstUse records the key of hosts missing from KnownHostsFile
+	// instead of rejecting them.
+	TrustOnFirstUse bool
+}
+
+// Insecure reports whether c disables host key verification.
+func (c HostKeyConfig) Insecure() bool {
+	return c.KnownHostsFile == "" && c.PinnedKey == ""
+}
+
+// HostKeyError is returned when the key presented by a device does not match
+// the expected one.
+type HostKeyError struct {
+	// Host is the address that was dialed.
+	Host string
+	// Fingerprint is the SHA256 fingerprint of the key the device presented.
+	Fingerprint string
+	// Reason explains why the key was rejected.
+	Reason string
+}
+
+func (e *HostKeyError) Error() string {
+	return fmt.Sprintf("host key verification failed for %s (%s): %s", e.Host, e.Fingerprint, e.Reason)
+}
+
+// Callback returns the ssh.HostKeyCallback implementing c. Both the pinned
+// key and the known_hosts file must accept the key when both are set.
+func (c HostKeyConfig) Callback() (ssh.HostKeyCallback, error) {
+	if c.Insecure() {
+		if c.TrustOnFirstUse {
+			return nil, errors.New("trust on first use requires a known_hosts file")
+		}
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	var callbacks []ssh.HostKeyCallback
+	if c.PinnedKey != "" {
+		cb, err := pinnedKeyCallback(c.PinnedKey)
+		if err != nil {
+			return nil, err
+		}
+		callbacks = append(callbacks, cb)
+	}
+	if c.KnownHostsFile != "" {
+		cb, err := knownHostsCallback(c.KnownHostsFile, c.TrustOnFirstUse)
+		if err != nil {
+			return nil, err
+		}
+		callbacks = append(callbacks, cb)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		for _, cb := range callbacks {
+			if err := cb(hostname, remote, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func pinnedKeyCallback(pinned string) (ssh.HostKeyCallback, error) {
+	pinned = strings.TrimSpace(pinned)
+
+	if strings.HasPrefix(pinned, "SHA256:") {
+		return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+			if fp := ssh.FingerprintSHA256(key); fp != pinned {
+				return &HostKeyError{Host: hostname, Fingerprint: fp, Reason: "expected fingerprint " + pinned}
+			}
+			return nil
+		}, nil
+	}
+
+	want, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pinned))
+	if err != nil {
+		return nil, fmt.Errorf("parsing pinned host key: %w", err)
+	}
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		if !bytes.Equal(key.Marshal(), want.Marshal()) {
+			return &HostKeyError{
+				Host:        hostname,
+				Fingerprint: ssh.FingerprintSHA256(key),
+				Reason:      "expected pinned key " + ssh.FingerprintSHA256(want),
+			}
+		}
+		return nil
+	}, nil
+}
+
+func knownHostsCallback(path string, tofu bool) (ssh.HostKeyCallback, error) {
+	if tofu {
+		// Make sure the file exists so knownhosts.New can open it.
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening known_hosts file: %w", err)
+		}
+		f.Close()
+	}
+
+	check, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading known_hosts file: %w", err)
+	}
+
+	t := &tofuStore{path: path, learned: map[string][]byte{}}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := check(hostname, remote, key)
+
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			fp := ssh.FingerprintSHA256(key)
+			if len(keyErr.Want) > 0 {
+				return &HostKeyError{
+					Host:        hostname,
+					Fingerprint: fp,
+					Reason:      fmt.Sprintf("key does not match %s:%d", path, keyErr.Want[0].Line),
+				}
+			}
+			if !tofu {
+				return &HostKeyError{Host: hostname, Fingerprint: fp, Reason: "host not found in " + path}
+			}
+			return t.trust(hostname, key)
+		}
+		return err
+	}, nil
+}
+
+// tofuStore appends keys of previously unknown hosts to a known_hosts file.
+// Keys learned during this run are kept in memory because the known_hosts
+// callback only reads the file once.
+type tofuStore struct {
+	mu      sync.Mutex
+	path    string
+	learned map[string][]byte
+}
+
+func (t *tofuStore) trust(hostname string, key ssh.PublicKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	host := knownhosts.Normalize(hostname)
+	if known, ok := t.learned[host]; ok {
+		if !bytes.Equal(known, key.Marshal()) {
+			return &HostKeyError{
+				Host:        hostname,
+				Fingerprint: ssh.FingerprintSHA256(key),
+				Reason:      "key changed since it was trusted earlier in this run",
+			}
+		}
+		return nil
+	}
+
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("recording host key: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{host}, key)); err != nil {
+		return fmt.Errorf("recording host key: %w", err)
+	}
+	t.learned[host] = key.Marshal()
+	return nil
+}
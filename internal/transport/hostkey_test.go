@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newTestKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+var testAddr = &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}
+
+func TestHostKeyInsecure(t *testing.T) {
+	cfg := HostKeyConfig{}
+	if !cfg.Insecure() {
+		t.Fatal("empty config should be insecure")
+	}
+	if _, err := cfg.Callback(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.TrustOnFirstUse = true
+	if _, err := cfg.Callback(); err == nil {
+		t.Fatal("trust on first use without known_hosts file should fail")
+	}
+}
+
+func TestHostKeyPinned(t *testing.T) {
+	key, other := newTestKey(t), newTestKey(t)
+
+	for _, pinned := range []string{
+		ssh.FingerprintSHA256(key),
+		string(ssh.MarshalAuthorizedKey(key)),
+	} {
+		cb, err := HostKeyConfig{PinnedKey: pinned}.Callback()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cb("192.0.2.1:22", testAddr, key); err != nil {
+			t.Errorf("%q: pinned key rejected: %v", pinned, err)
+		}
+
+		var keyErr *HostKeyError
+		if err := cb("192.0.2.1:22", testAddr, other); !errors.As(err, &keyErr) {
+			t.Errorf("%q: got %v, want *HostKeyError", pinned, err)
+		} else if keyErr.Fingerprint != ssh.FingerprintSHA256(other) {
+			t.Errorf("%q: unexpected fingerprint %q", pinned, keyErr.Fingerprint)
+		}
+	}
+
+	if _, err := (HostKeyConfig{PinnedKey: "not a key"}).Callback(); err == nil {
+		t.Error("invalid pinned key should fail")
+	}
+}
+
+func TestHostKeyKnownHosts(t *testing.T) {
+	key, other := newTestKey(t), newTestKey(t)
+
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize("192.0.2.1:22")}, key)
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cb, err := HostKeyConfig{KnownHostsFile: path}.Callback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cb("192.0.2.1:22", testAddr, key); err != nil {
+		t.Errorf("known key rejected: %v", err)
+	}
+
+	var keyErr *HostKeyError
+	if err := cb("192.0.2.1:22", testAddr, other); !errors.As(err, &keyErr) {
+		t.Errorf("mismatch: got %v, want *HostKeyError", err)
+	}
+	if err := cb("192.0.2.2:22", testAddr, key); !errors.As(err, &keyErr) {
+		t.Errorf("unknown host: got %v, want *HostKeyError", err)
+	}
+}
+
+func TestHostKeyTrustOnFirstUse(t *testing.T) {
+	key, other := newTestKey(t), newTestKey(t)
+	path := filepath.Join(t.TempDir(), "known_hosts")
+
+	cb, err := HostKeyConfig{KnownHostsFile: path, TrustOnFirstUse: true}.Callback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cb("192.0.2.1:22", testAddr, key); err != nil {
+		t.Fatalf("first use rejected: %v", err)
+	}
+	if err := cb("192.0.2.1:22", testAddr, key); err != nil {
+		t.Errorf("second use rejected: %v", err)
+	}
+	if err := cb("192.0.2.1:22", testAddr, other); err == nil {
+		t.Error("changed key accepted")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 1 {
+		t.Errorf("known_hosts has %d entries, want 1:\n%s", n, data)
+	}
+
+	// A new run reads the recorded key from the file.
+	cb, err = HostKeyConfig{KnownHostsFile: path, TrustOnFirstUse: true}.Callback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cb("192.0.2.1:22", testAddr, other); err == nil {
+		t.Error("changed key accepted after reload")
+	}
+}
@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Interface is a port or an MLT. Exactly one of Port and MLT is set.
+type Interface struct {
+	Port string
+	MLT  int32
+}
+
+// command returns the command entering the interface configuration mode.
+func (i Interface) command() string {
+	if i.Port != "" {
+		return "interface gigabitEthernet " + i.Port
+	}
+	return fmt.Sprintf("interface mlt %d", i.MLT)
+}
+
+// ifIndexName returns the name of the interface in the show commands listing
+// ports and MLTs together, e.g. "Port1/1" or "Mlt2".
+func (i Interface) ifIndexName() string {
+	if i.Port != "" {
+		return "Port" + i.Port
+	}
+	return fmt.Sprintf("Mlt%d", i.MLT)
+}
+
+func (i Interface) String() string {
+	if i.Port != "" {
+		return "port " + i.Port
+	}
+	return fmt.Sprintf("MLT %d", i.MLT)
+}
+
+// parseIfIndexName parses the name of a port or an MLT as returned by
+// ifIndexName.
+func parseIfIndexName(name string) (Interface, bool) {
+	if port, ok := strings.CutPrefix(name, "Port"); ok && ValidPort(port) {
+		return Interface{Port: port}, true
+	}
+	if id, ok := strings.CutPrefix(name, "Mlt"); ok {
+		if n, err := strconv.Atoi(id); err == nil {
+			return Interface{MLT: int32(n)}, true
+		}
+	}
+	return Interface{}, false
+}
@@ -0,0 +1,261 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GlobalRouter is the name of the VRF of the global routing table in the
+// show commands.
+const GlobalRouter = "GlobalRouter"
+
+// IPInterface is the IP configuration of a VLAN, a brouter port or a loopback
+// (CLIP) interface. Exactly one of VLAN, Port and Loopback is set.
+type IPInterface struct {
+	VLAN     int32
+	Port     string
+	Loopback int32
+	// VRF is the VRF of the interface, empty for the global router.
+	VRF string
+	// Address is the primary IPv4 address with its prefix length, e.g.
+	// "10.0.0.1/24", or empty. On a brouter port it is the subnet of
+	// "brouter port".
+	Address string
+	// Secondary are the secondary IPv4 addresses of a VLAN.
+	Secondary []string
+	IPv6      []string
+	// BrouterVLAN is the VLAN ID of a brouter port.
+	BrouterVLAN int32
+	// SPBMulticast reports whether "ip spb-multicast enable" is set on a
+	// loopback.
+	SPBMulticast bool
+}
+
+// command returns the command entering the interface configuration mode.
+func (i IPInterface) command() string {
+	switch {
+	case i.Port != "":
+		return "interface gigabitEthernet " + i.Port
+	case i.Loopback != 0:
+		return fmt.Sprintf("interface loopback %d", i.Loopback)
+	default:
+		return fmt.Sprintf("interface vlan %d", i.VLAN)
+	}
+}
+
+// names returns the name of the interface in "show ip interface", e.g.
+// "Vlan10", and its ID in "show ipv6 address interface", e.g. "V-10".
+func (i IPInterface) names() (string, string) {
+	switch {
+	case i.Port != "":
+		return "Port" + i.Port, "P-" + i.Port
+	case i.Loopback != 0:
+		return fmt.Sprintf("Clip%d", i.Loopback), fmt.Sprintf("C-%d", i.Loopback)
+	default:
+		return fmt.Sprintf("Vlan%d", i.VLAN), fmt.Sprintf("V-%d", i.VLAN)
+	}
+}
+
+func (i IPInterface) String() string {
+	switch {
+	case i.Port != "":
+		return "brouter port " + i.Port
+	case i.Loopback != 0:
+		return fmt.Sprintf("loopback %d", i.Loopback)
+	default:
+		return fmt.Sprintf("VLAN %d", i.VLAN)
+	}
+}
+
+var (
+	// ipInterfaceRe matches a row of "show ip interface vrf".
+	ipInterfaceRe = regexp.MustCompile(`(?m)^(\S+)[ \t]+(\d+\.\d+\.\d+\.\d+)[ \t]+(\d+\.\d+\.\d+\.\d+)[ \t]+(primary|secondary)[ \t]+(\S+)[ \t]+(true|false)[ \t]+(\S+)[ \t]*$`)
+	// ipv6AddressRe matches a row of "show ipv6 address interface vrf".
+	ipv6AddressRe = regexp.MustCompile(`(?m)^([0-9a-fA-F:.]+/\d+)[ \t]+(\S+)[ \t]`)
+)
+
+// IPInterface reads the IP configuration of the interface identified by
+// iface and its VRF from "show ip interface vrf" and
+// "show ipv6 address interface vrf". It returns nil if the interface has no
+// address in the VRF, or the VRF does not exist.
+func (c *Client) IPInterface(ctx context.Context, iface IPInterface) (*IPInterface, error) {
+	vrf := iface.VRF
+	if vrf == "" {
+		vrf = GlobalRouter
+	}
+	outputs, err := c.Run(ctx, "show ip interface vrf "+vrf, "show ipv6 address interface vrf "+vrf)
+	if vrfNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name, id := iface.names()
+	i := &IPInterface{VLAN: iface.VLAN, Port: iface.Port, Loopback: iface.Loopback, VRF: iface.VRF}
+	for _, m := range ipInterfaceRe.FindAllStringSubmatch(outputs[0], -1) {
+		if m[1] != name {
+			continue
+		}
+		prefix, err := addressPrefix(m[2], m[3])
+		if err != nil {
+			return nil, fmt.Errorf("parsing the addresses of %s: %w", iface, err)
+		}
+		if m[4] == "secondary" {
+			i.Secondary = append(i.Secondary, prefix)
+			continue
+		}
+		i.Address = prefix
+		if m[6] == "true" {
+			vlan, _ := strconv.Atoi(m[5])
+			i.BrouterVLAN = int32(vlan)
+		}
+		i.SPBMulticast = m[7] == "enable"
+	}
+	for _, m := range ipv6AddressRe.FindAllStringSubmatch(outputs[1], -1) {
+		if m[2] == id {
+			i.IPv6 = append(i.IPv6, m[1])
+		}
+	}
+	if i.Address == "" && len(i.IPv6) == 0 {
+		return nil, nil
+	}
+	sort.Strings(i.Secondary)
+	sort.Strings(i.IPv6)
+	return i, nil
+}
+
+// ApplyIPInterface changes the IP configuration of the interface from old to
+// i. A nil old configures the interface from scratch, starting with its VRF.
+// The VRF of a configured interface is not changed.
+func (c *Client) ApplyIPInterface(ctx context.Context, old *IPInterface, i IPInterface) error {
+	var cmds []string
+	if old == nil {
+		old = &IPInterface{VLAN: i.VLAN, Port: i.Port, Loopback: i.Loopback}
+		if i.VRF != "" {
+			cmds = append(cmds, "vrf "+i.VRF)
+		}
+	}
+	cmds = append(cmds, ipInterfaceCommands(*old, i)...)
+	if len(cmds) == 0 {
+		return nil
+	}
+	cmds = append([]string{i.command()}, cmds...)
+	return c.Configure(ctx, append(cmds, "exit")...)
+}
+
+// DeleteIPInterface removes the addresses of the interface, then moves it
+// back to the global router.
+func (c *Client) DeleteIPInterface(ctx context.Context, i IPInterface) error {
+	cmds := []string{i.command()}
+	cmds = append(cmds, ipInterfaceCommands(i, IPInterface{VLAN: i.VLAN, Port: i.Port, Loopback: i.Loopback})...)
+	if i.VRF != "" {
+		cmds = append(cmds, "no vrf")
+	}
+	return c.Configure(ctx, append(cmds, "exit")...)
+}
+
+// ipInterfaceCommands returns the commands changing the addresses of the
+// interface from old to i, in its configuration mode. Secondary addresses and
+// SPB multicast depend on the primary address, so they are removed before it
+// changes and added back after.
+func ipInterfaceCommands(old, i IPInterface) []string {
+	var cmds []string
+	primaryChanged := old.Address != i.Address || old.BrouterVLAN != i.BrouterVLAN
+	if old.SPBMulticast && (!i.SPBMulticast || primaryChanged) {
+		cmds = append(cmds, "no ip spb-multicast enable")
+	}
+
+	keep := map[string]bool{}
+	if !primaryChanged {
+		keep = stringSet(i.Secondary)
+	}
+	for _, p := range old.Secondary {
+		if !keep[p] {
+			cmds = append(cmds, "no ip address "+addressOf(p))
+		}
+	}
+	if primaryChanged {
+		switch {
+		case old.Address == "":
+		case old.Port != "":
+			cmds = append(cmds, "no brouter port "+old.Port)
+		default:
+			cmds = append(cmds, "no ip address "+addressOf(old.Address))
+		}
+		switch {
+		case i.Address == "":
+		case i.Port != "":
+			cmds = append(cmds, fmt.Sprintf("brouter port %s vlan %d subnet %s", i.Port, i.BrouterVLAN, i.Address))
+		default:
+			cmds = append(cmds, "ip address "+i.Address)
+		}
+	}
+	existing := map[string]bool{}
+	if !primaryChanged {
+		existing = stringSet(old.Secondary)
+	}
+	for _, p := range i.Secondary {
+		if !existing[p] {
+			cmds = append(cmds, "ip address "+p+" secondary")
+		}
+	}
+
+	oldIPv6, newIPv6 := stringSet(old.IPv6), stringSet(i.IPv6)
+	for _, p := range old.IPv6 {
+		if !newIPv6[p] {
+			cmds = append(cmds, "no ipv6 interface address "+p)
+		}
+	}
+	for _, p := range i.IPv6 {
+		if !oldIPv6[p] {
+			cmds = append(cmds, "ipv6 interface address "+p)
+		}
+	}
+
+	if i.SPBMulticast && (!old.SPBMulticast || primaryChanged) {
+		cmds = append(cmds, "ip spb-multicast enable")
+	}
+	return cmds
+}
+
+// addressPrefix returns an address and its dotted network mask as an address
+// with a prefix length, e.g. "10.0.0.1/24".
+func addressPrefix(address, mask string) (string, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return "", err
+	}
+	m, err := netip.ParseAddr(mask)
+	if err != nil || !m.Is4() {
+		return "", fmt.Errorf("invalid network mask %q", mask)
+	}
+	b := m.As4()
+	bits := 0
+	for _, octet := range b {
+		for ; octet&0x80 != 0; octet <<= 1 {
+			bits++
+		}
+	}
+	return netip.PrefixFrom(addr, bits).String(), nil
+}
+
+// addressOf returns the address of an address with a prefix length, as
+// given to "no ip address".
+func addressOf(prefix string) string {
+	addr, _, _ := strings.Cut(prefix, "/")
+	return addr
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,123 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestIPInterface(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	srv.Exec("configure terminal", "vlan create 10 type port-mstprstp 0", "ip vrf red vrfid 1", "end")
+
+	vlan := IPInterface{VLAN: 10, VRF: "red"}
+	if i, err := c.IPInterface(ctx, vlan); err != nil || i != nil {
+		t.Fatalf("got %v, %v for an interface without addresses", i, err)
+	}
+
+	want := IPInterface{
+		VLAN:      10,
+		VRF:       "red",
+		Address:   "10.0.10.1/24",
+		Secondary: []string{"10.0.11.1/24", "10.0.12.1/24"},
+		IPv6:      []string{"2001:db8:10::1/64"},
+	}
+	if err := c.ApplyIPInterface(ctx, nil, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.IPInterface(ctx, vlan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	// Changing the primary address removes the secondary addresses first.
+	updated := want
+	updated.Address = "10.0.20.1/24"
+	updated.Secondary = []string{"10.0.12.1/24"}
+	updated.IPv6 = nil
+	if err := c.ApplyIPInterface(ctx, got, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.IPInterface(ctx, vlan); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+
+	if err := c.DeleteIPInterface(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	if i, _ := c.IPInterface(ctx, vlan); i != nil {
+		t.Errorf("interface still configured after delete: %+v", i)
+	}
+	if err := c.DeleteVRF(ctx, "red"); err != nil {
+		t.Errorf("VRF still has interfaces after delete: %v", err)
+	}
+}
+
+func TestIPInterfaceBrouterAndLoopback(t *testing.T) {
+	_, c := newMockClient(t)
+	ctx := context.Background()
+
+	for _, want := range []IPInterface{
+		{Port: "1/5", Address: "192.0.2.1/30", BrouterVLAN: 2001, IPv6: []string{"2001:db8:1::1/64"}},
+		{Loopback: 1, Address: "10.255.0.1/32", SPBMulticast: true},
+	} {
+		if err := c.ApplyIPInterface(ctx, nil, want); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.IPInterface(ctx, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("got %+v, want %+v", *got, want)
+		}
+
+		updated := want
+		updated.Address = "192.0.2.5/30"
+		if want.Loopback != 0 {
+			updated.Address = "10.255.0.2/32"
+		}
+		if err := c.ApplyIPInterface(ctx, got, updated); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := c.IPInterface(ctx, want); !reflect.DeepEqual(*got, updated) {
+			t.Errorf("got %+v, want %+v", *got, updated)
+		}
+		if err := c.DeleteIPInterface(ctx, updated); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIPInterfaceNotFound(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	vlan := IPInterface{VLAN: 10, VRF: "red"}
+	if i, err := c.IPInterface(ctx, vlan); err != nil || i != nil {
+		t.Fatalf("got %v, %v in a VRF that does not exist", i, err)
+	}
+
+	// Only a missing VRF means the interface does not exist.
+	srv.Reject("show ip interface", "% Permission denied")
+	if i, err := c.IPInterface(ctx, vlan); err == nil {
+		t.Errorf("got %v for a rejected command, want an error", i)
+	}
+}
+
+func TestAddressPrefix(t *testing.T) {
+	for _, tc := range []struct{ address, mask, want string }{
+		{"10.0.0.1", "255.255.255.0", "10.0.0.1/24"},
+		{"10.255.0.1", "255.255.255.255", "10.255.0.1/32"},
+		{"192.0.2.1", "255.255.255.252", "192.0.2.1/30"},
+	} {
+		if got, err := addressPrefix(tc.address, tc.mask); err != nil || got != tc.want {
+			t.Errorf("addressPrefix(%q, %q) = %q, %v, want %q", tc.address, tc.mask, got, err, tc.want)
+		}
+	}
+}
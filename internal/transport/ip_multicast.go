@@ -0,0 +1,208 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// DefaultIGMPVersion is the IGMP version of an interface without
+// "ip igmp version".
+const DefaultIGMPVersion = 2
+
+// IGMPInterface is the multicast configuration of a VLAN interface.
+type IGMPInterface struct {
+	VLAN int32
+	// SPBMulticast reports whether "ip spb-multicast enable" is set.
+	SPBMulticast bool
+	// Snooping reports whether "ip igmp snooping" is set.
+	Snooping bool
+	// SnoopQuerier reports whether "ip igmp snoop-querier" is set.
+	SnoopQuerier bool
+	// SnoopQuerierAddress is set with "ip igmp snoop-querier-addr", empty
+	// for none.
+	SnoopQuerierAddress string
+	// Version is the IGMP version, from 1 to 3.
+	Version int32
+}
+
+// IPMulticast is the IP multicast over Fabric Connect configuration of the
+// global router or of a VRF.
+type IPMulticast struct {
+	// VRF is empty for the global router.
+	VRF string
+	// Interfaces are the VLAN interfaces of the VRF with multicast settings,
+	// sorted by VLAN.
+	Interfaces []IGMPInterface
+}
+
+var (
+	// spbmMulticastRe matches the row of SPBM instance 1 in
+	// "show isis spbm multicast".
+	spbmMulticastRe = regexp.MustCompile(`(?m)^1[ \t]+(enable|disable)[ \t]+`)
+	// vrfMVPNRe matches a row of "show ip vrf mvpn".
+	vrfMVPNRe = regexp.MustCompile(`(?m)^(\S+)[ \t]+\d+[ \t]+(enable|disable)[ \t]+`)
+	// igmpInterfaceRe matches a row of "show ip igmp interface".
+	igmpInterfaceRe = regexp.MustCompile(`(?m)^Vlan(\d+)[ \t]+\d+[ \t]+\S+[ \t]+(\d)[ \t]+\d[ \t]+(\d+\.\d+\.\d+\.\d+)[ \t]+(true|false)[ \t]+(true|false)[ \t]+(enable|disable)[ \t]*$`)
+)
+
+// IPMulticast reads the multicast configuration of the VRF, empty for the
+// global router, from "show isis spbm multicast" or "show ip vrf mvpn", and
+// "show ip igmp interface". It returns nil if multicast is not enabled in the
+// VRF.
+func (c *Client) IPMulticast(ctx context.Context, vrf string) (*IPMulticast, error) {
+	enabled, err := c.multicastEnabled(ctx, vrf)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	cmd := "show ip igmp interface"
+	if vrf != "" {
+		cmd += " vrf " + vrf
+	}
+	outputs, err := c.Run(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	m := &IPMulticast{VRF: vrf}
+	for _, row := range igmpInterfaceRe.FindAllStringSubmatch(outputs[0], -1) {
+		vlan, _ := strconv.Atoi(row[1])
+		version, _ := strconv.Atoi(row[2])
+		i := IGMPInterface{
+			VLAN:         int32(vlan),
+			Version:      int32(version),
+			Snooping:     row[4] == "true",
+			SnoopQuerier: row[5] == "true",
+			SPBMulticast: row[6] == "enable",
+		}
+		if row[3] != "0.0.0.0" {
+			i.SnoopQuerierAddress = row[3]
+		}
+		m.Interfaces = append(m.Interfaces, i)
+	}
+	sort.Slice(m.Interfaces, func(i, j int) bool { return m.Interfaces[i].VLAN < m.Interfaces[j].VLAN })
+	return m, nil
+}
+
+// multicastEnabled reports whether SPB multicast is enabled in the global
+// router, or MVPN in the VRF.
+func (c *Client) multicastEnabled(ctx context.Context, vrf string) (bool, error) {
+	if vrf == "" {
+		outputs, err := c.Run(ctx, "show isis spbm multicast")
+		if err != nil {
+			return false, err
+		}
+		m := spbmMulticastRe.FindStringSubmatch(outputs[0])
+		return m != nil && m[1] == "enable", nil
+	}
+
+	outputs, err := c.Run(ctx, "show ip vrf mvpn")
+	if err != nil {
+		return false, err
+	}
+	for _, m := range vrfMVPNRe.FindAllStringSubmatch(outputs[0], -1) {
+		if m[1] == vrf {
+			return m[2] == "enable", nil
+		}
+	}
+	return false, nil
+}
+
+// ApplyIPMulticast changes the multicast configuration from old to m. A nil
+// old enables multicast in the VRF first.
+func (c *Client) ApplyIPMulticast(ctx context.Context, old *IPMulticast, m IPMulticast) error {
+	var cmds []string
+	if old == nil {
+		old = &IPMulticast{VRF: m.VRF}
+		cmds = append(cmds, multicastScopeCommands(m.VRF, true)...)
+	}
+	cmds = append(cmds, igmpInterfaceCommands(old.Interfaces, m.Interfaces)...)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// DeleteIPMulticast restores the default multicast settings of the VLAN
+// interfaces, then disables multicast in the VRF.
+func (c *Client) DeleteIPMulticast(ctx context.Context, m IPMulticast) error {
+	cmds := igmpInterfaceCommands(m.Interfaces, nil)
+	return c.Configure(ctx, append(cmds, multicastScopeCommands(m.VRF, false)...)...)
+}
+
+// multicastScopeCommands returns the commands enabling or disabling
+// multicast in the VRF, empty for the global router.
+func multicastScopeCommands(vrf string, enable bool) []string {
+	if vrf == "" {
+		return []string{"router isis", negate(!enable, "spbm 1 multicast enable"), "exit"}
+	}
+	return []string{"router vrf " + vrf, negate(!enable, "mvpn enable"), "exit"}
+}
+
+// igmpInterfaceCommands returns the commands changing the multicast settings
+// of the VLAN interfaces from old to ifs. The interfaces missing from ifs get
+// the default settings back.
+func igmpInterfaceCommands(old, ifs []IGMPInterface) []string {
+	existing := map[int32]IGMPInterface{}
+	for _, i := range old {
+		existing[i.VLAN] = i
+	}
+	wanted := map[int32]IGMPInterface{}
+	var vlans []int32
+	for _, i := range ifs {
+		wanted[i.VLAN] = i
+		vlans = append(vlans, i.VLAN)
+	}
+	for _, i := range old {
+		if _, ok := wanted[i.VLAN]; !ok {
+			vlans = append(vlans, i.VLAN)
+		}
+	}
+	sort.Slice(vlans, func(i, j int) bool { return vlans[i] < vlans[j] })
+
+	defaults := IGMPInterface{Version: DefaultIGMPVersion}
+	var cmds []string
+	for _, vlan := range vlans {
+		o, ok := existing[vlan]
+		if !ok {
+			o = defaults
+		}
+		n, ok := wanted[vlan]
+		if !ok {
+			n = defaults
+		}
+
+		var sub []string
+		if o.SPBMulticast && !n.SPBMulticast {
+			sub = append(sub, "no ip spb-multicast enable")
+		}
+		if o.SnoopQuerier && !n.SnoopQuerier {
+			sub = append(sub, "no ip igmp snoop-querier")
+		}
+		if o.SnoopQuerierAddress != n.SnoopQuerierAddress {
+			if n.SnoopQuerierAddress == "" {
+				sub = append(sub, "no ip igmp snoop-querier-addr")
+			} else {
+				sub = append(sub, "ip igmp snoop-querier-addr "+n.SnoopQuerierAddress)
+			}
+		}
+		if o.Version != n.Version {
+			sub = append(sub, fmt.Sprintf("ip igmp version %d", n.Version))
+		}
+		if o.Snooping != n.Snooping {
+			sub = append(sub, negate(!n.Snooping, "ip igmp snooping"))
+		}
+		if !o.SnoopQuerier && n.SnoopQuerier {
+			sub = append(sub, "ip igmp snoop-querier")
+		}
+		if !o.SPBMulticast && n.SPBMulticast {
+			sub = append(sub, "ip spb-multicast enable")
+		}
+		if len(sub) > 0 {
+			cmds = append(append(append(cmds, fmt.Sprintf("interface vlan %d", vlan)), sub...), "exit")
+		}
+	}
+	return cmds
+}
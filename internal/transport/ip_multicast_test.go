@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIPMulticast(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+	srv.Exec("configure terminal", "spbm", "router isis", "spbm 1", "exit",
+		"vlan create 10 type port-mstprstp 0", "interface vlan 10", "ip address 10.0.10.1/24", "exit",
+		"vlan create 20 type port-mstprstp 0", "interface vlan 20", "ip address 10.0.20.1/24", "exit",
+		"ip vrf red vrfid 1",
+		"vlan create 30 type port-mstprstp 0", "interface vlan 30", "vrf red", "ip address 10.0.30.1/24", "exit",
+		"end")
+
+	if m, err := c.IPMulticast(ctx, ""); err != nil || m != nil {
+		t.Fatalf("got %v, %v without SPB multicast", m, err)
+	}
+
+	grt := IPMulticast{Interfaces: []IGMPInterface{
+		{VLAN: 10, SPBMulticast: true, Snooping: true, Version: 3},
+		{VLAN: 20, SnoopQuerier: true, SnoopQuerierAddress: "10.0.20.254", Version: DefaultIGMPVersion},
+	}}
+	if err := c.ApplyIPMulticast(ctx, nil, grt); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.IPMulticast(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, grt) {
+		t.Errorf("got %+v, want %+v", *got, grt)
+	}
+
+	red := IPMulticast{VRF: "red", Interfaces: []IGMPInterface{{VLAN: 30, SPBMulticast: true, Version: DefaultIGMPVersion}}}
+	if err := c.ApplyIPMulticast(ctx, nil, red); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.IPMulticast(ctx, "red"); !reflect.DeepEqual(*got, red) {
+		t.Errorf("got %+v, want %+v", *got, red)
+	}
+	cfg := srv.RunningConfig()
+	for _, line := range []string{"spbm 1 multicast enable\n", "mvpn enable\n", "ip igmp version 3\n", "ip igmp snoop-querier-addr 10.0.20.254\n"} {
+		if !strings.Contains(cfg, line) {
+			t.Errorf("missing %q in:\n%s", line, cfg)
+		}
+	}
+
+	// Only the interface that changed is reconfigured.
+	updated := grt
+	updated.Interfaces = []IGMPInterface{{VLAN: 10, SPBMulticast: true, Version: DefaultIGMPVersion}, grt.Interfaces[1]}
+	sent := len(srv.Commands())
+	if err := c.ApplyIPMulticast(ctx, &grt, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.IPMulticast(ctx, ""); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+	for _, cmd := range srv.Commands()[sent:] {
+		if cmd == "interface vlan 20" {
+			t.Errorf("unchanged interface reconfigured: %q", cmd)
+		}
+	}
+
+	if err := c.DeleteIPMulticast(ctx, red); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteIPMulticast(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	for _, vrf := range []string{"", "red"} {
+		if m, err := c.IPMulticast(ctx, vrf); err != nil || m != nil {
+			t.Errorf("got %v, %v after disabling multicast in %q", m, err, vrf)
+		}
+	}
+	if cfg := srv.RunningConfig(); strings.Contains(cfg, "multicast") || strings.Contains(cfg, "igmp") {
+		t.Errorf("multicast configuration left:\n%s", cfg)
+	}
+}
@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Hello authentication types accepted by "isis hello-auth type".
+const (
+	HelloAuthSimple     = "simple"
+	HelloAuthHMACMD5    = "hmac-md5"
+	HelloAuthHMACSHA256 = "hmac-sha-256"
+)
+
+// DefaultISISMetric is the SPBM L1 metric of an interface without
+// "isis spbm 1 l1-metric".
+const DefaultISISMetric = 10
+
+// ISISInterface is the IS-IS configuration of an NNI port or MLT.
+type ISISInterface struct {
+	Interface
+	// Metric is the SPBM L1 metric of the link.
+	Metric int32
+	// Enabled reports whether "isis enable" is set.
+	Enabled bool
+	// AuthType is the hello authentication type, empty for none.
+	AuthType string
+	// AuthKey is the hello authentication key. The device does not show it,
+	// so it is never read back.
+	AuthKey   string
+	AuthKeyID int32
+}
+
+var (
+	// isisInterfaceRe matches a row of "show isis interface".
+	isisInterfaceRe = regexp.MustCompile(`(?m)^(\S+)[ \t]+\S+[ \t]+Level[ \t]+\S+[ \t]+(\S+)[ \t]+(\S+)[ \t]+\d+[ \t]+\d+[ \t]+(\S+)[ \t]*$`)
+	// isisIntAuthRe matches a row of "show isis int-auth".
+	isisIntAuthRe = regexp.MustCompile(`(?m)^(\S+)[ \t]+(none|simple|hmac-md5|hmac-sha-256)[ \t]+(\d+)`)
+)
+
+// ISISInterface reads the IS-IS configuration of iface from
+// "show isis interface" and "show isis int-auth". It returns nil if IS-IS
+// SPBM instance 1 is not configured on the interface.
+func (c *Client) ISISInterface(ctx context.Context, iface Interface) (*ISISInterface, error) {
+	outputs, err := c.Run(ctx, "show isis interface", "show isis int-auth")
+	if err != nil {
+		return nil, err
+	}
+
+	name := iface.ifIndexName()
+	var i *ISISInterface
+	for _, m := range isisInterfaceRe.FindAllStringSubmatch(outputs[0], -1) {
+		if m[1] != name || m[4] == "--" {
+			continue
+		}
+		metric, err := strconv.Atoi(m[4])
+		if err != nil {
+			return nil, fmt.Errorf("parsing the metric of %s: %w", iface, err)
+		}
+		i = &ISISInterface{Interface: iface, Metric: int32(metric), Enabled: m[3] == "UP"}
+	}
+	if i == nil {
+		return nil, nil
+	}
+
+	for _, m := range isisIntAuthRe.FindAllStringSubmatch(outputs[1], -1) {
+		if m[1] == name && m[2] != "none" {
+			keyID, _ := strconv.Atoi(m[3])
+			i.AuthType, i.AuthKeyID = m[2], int32(keyID)
+		}
+	}
+	return i, nil
+}
+
+// ApplyISISInterface changes the IS-IS configuration of the interface from
+// old to i. A nil old configures IS-IS on the interface from scratch.
+func (c *Client) ApplyISISInterface(ctx context.Context, old *ISISInterface, i ISISInterface) error {
+	var cmds []string
+	if old == nil {
+		old = &ISISInterface{Interface: i.Interface, Metric: DefaultISISMetric}
+		cmds = append(cmds, "isis", "isis spbm 1")
+	}
+	if i.Metric != old.Metric {
+		cmds = append(cmds, fmt.Sprintf("isis spbm 1 l1-metric %d", i.Metric))
+	}
+	if i.AuthType != old.AuthType || i.AuthKey != old.AuthKey || i.AuthKeyID != old.AuthKeyID {
+		switch {
+		case i.AuthType == "":
+			cmds = append(cmds, "no isis hello-auth")
+		case i.AuthKeyID != 0:
+			cmds = append(cmds, fmt.Sprintf("isis hello-auth type %s key %s key-id %d", i.AuthType, i.AuthKey, i.AuthKeyID))
+		default:
+			cmds = append(cmds, fmt.Sprintf("isis hello-auth type %s key %s", i.AuthType, i.AuthKey))
+		}
+	}
+	if i.Enabled != old.Enabled {
+		if i.Enabled {
+			cmds = append(cmds, "isis enable")
+		} else {
+			cmds = append(cmds, "no isis enable")
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	cmds = append([]string{i.command()}, cmds...)
+	return c.Configure(ctx, append(cmds, "exit")...)
+}
+
+// DeleteISISInterface removes the IS-IS configuration of the interface.
+func (c *Client) DeleteISISInterface(ctx context.Context, iface Interface) error {
+	return c.Configure(ctx, iface.command(), "no isis enable", "no isis spbm 1", "no isis", "exit")
+}
@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestISISInterface(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	if err := c.CreateVLAN(ctx, VLAN{ID: 4051, Type: VLANTypeBVLAN}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ApplySPBM(ctx, SPBM{}, SPBM{ManualArea: "49.0001", NickName: "0.00.01", BVLANs: []int32{4051}, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Configure(ctx, "mlt 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, iface := range []Interface{{Port: "1/1"}, {MLT: 2}} {
+		if got, err := c.ISISInterface(ctx, iface); err != nil || got != nil {
+			t.Fatalf("%s: got %+v, %v before configuring IS-IS", iface, got, err)
+		}
+
+		want := ISISInterface{
+			Interface: iface,
+			Metric:    DefaultISISMetric,
+			Enabled:   true,
+			AuthType:  HelloAuthHMACSHA256,
+			AuthKey:   "secret",
+			AuthKeyID: 3,
+		}
+		if err := c.ApplyISISInterface(ctx, nil, want); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.ISISInterface(ctx, iface)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The key is never shown by the device.
+		want.AuthKey = ""
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("%s: got %+v, want %+v", iface, *got, want)
+		}
+
+		updated := ISISInterface{Interface: iface, Metric: 100}
+		if err := c.ApplyISISInterface(ctx, got, updated); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := c.ISISInterface(ctx, iface); !reflect.DeepEqual(*got, updated) {
+			t.Errorf("%s: got %+v, want %+v", iface, *got, updated)
+		}
+		if !strings.Contains(srv.RunningConfig(), "isis spbm 1 l1-metric 100") {
+			t.Errorf("%s: metric missing from the running configuration:\n%s", iface, srv.RunningConfig())
+		}
+
+		if err := c.DeleteISISInterface(ctx, iface); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := c.ISISInterface(ctx, iface); err != nil || got != nil {
+			t.Errorf("%s: got %+v, %v after deletion", iface, got, err)
+		}
+	}
+
+	// Deleting the MLT only works once IS-IS is removed from it.
+	if err := c.Configure(ctx, "no mlt 2"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestISISInterfaceRejectedKey(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	srv.Reject("isis hello-auth", "Error: Invalid key s3cr3t-key")
+	old := ISISInterface{Interface: Interface{Port: "1/1"}, Metric: DefaultISISMetric}
+	i := old
+	i.AuthType = HelloAuthHMACSHA256
+	i.AuthKey = "s3cr3t-key"
+	err := c.ApplyISISInterface(ctx, &old, i)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("got %v, want *CommandError", err)
+	}
+	for _, text := range []string{err.Error(), cmdErr.Command, cmdErr.Output, cmdErr.Message} {
+		if strings.Contains(text, "s3cr3t-key") {
+			t.Errorf("key leaked in %q", text)
+		}
+	}
+	if want := "isis hello-auth type hmac-sha-256 key ****"; cmdErr.Command != want {
+		t.Errorf("got command %q, want %q", cmdErr.Command, want)
+	}
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Endpoint is a Flex UNI endpoint of an ELAN I-SID: the traffic of a port or
+// an MLT tagged with a C-VID, or its untagged traffic when CVID is 0.
+type Endpoint struct {
+	Interface
+	CVID int32
+}
+
+// command returns the ELAN sub-mode command adding e, without "no".
+func (e Endpoint) command() string {
+	cmd := "untagged-traffic"
+	if e.CVID != 0 {
+		cmd = fmt.Sprintf("c-vid %d", e.CVID)
+	}
+	if e.Port != "" {
+		return cmd + " port " + e.Port
+	}
+	return fmt.Sprintf("%s mlt %d", cmd, e.MLT)
+}
+
+// SortEndpoints sorts endpoints by port, then MLT, then C-VID.
+func SortEndpoints(endpoints []Endpoint) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		a, b := endpoints[i], endpoints[j]
+		switch {
+		case (a.Port == "") != (b.Port == ""):
+			return a.Port != ""
+		case a.Port != b.Port:
+			return portLess(a.Port, b.Port)
+		case a.MLT != b.MLT:
+			return a.MLT < b.MLT
+		default:
+			return a.CVID < b.CVID
+		}
+	})
+}
+
+// L2VSN is an ELAN I-SID created with "i-sid <isid> elan" and its Flex UNI
+// endpoints.
+type L2VSN struct {
+	ISID int32
+	// Name is the I-SID name, "ISID-<isid>" unless set with "i-sid name".
+	Name      string
+	Endpoints []Endpoint
+}
+
+var (
+	// isidRe matches a row of "show i-sid" for an I-SID not mapped to a
+	// VLAN. Names may contain spaces.
+	isidRe = regexp.MustCompile(`(?m)^(\d+)[ \t]+ELAN[ \t]+N/A[ \t]+\S+[ \t]+(.*?)[ \t]*$`)
+	// isidElanRe matches a row of "show i-sid elan".
+	isidElanRe = regexp.MustCompile(`(?m)^(\d+)[ \t]+(\d+|untag)[ \t]+(\S+)[ \t]*$`)
+)
+
+// L2VSN reads the ELAN I-SID from "show i-sid" and "show i-sid elan". It
+// returns nil if the I-SID does not exist or is mapped to a VLAN.
+func (c *Client) L2VSN(ctx context.Context, isid int32) (*L2VSN, error) {
+	outputs, err := c.Run(ctx, "show i-sid", "show i-sid elan")
+	if err != nil {
+		return nil, err
+	}
+
+	key := strconv.Itoa(int(isid))
+	var v *L2VSN
+	for _, m := range isidRe.FindAllStringSubmatch(outputs[0], -1) {
+		if m[1] == key {
+			v = &L2VSN{ISID: isid, Name: m[2]}
+		}
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	for _, m := range isidElanRe.FindAllStringSubmatch(outputs[1], -1) {
+		if m[1] != key {
+			continue
+		}
+		iface, ok := parseIfIndexName(m[3])
+		if !ok {
+			return nil, fmt.Errorf("parsing the endpoints of I-SID %d: unknown interface %q", isid, m[3])
+		}
+		e := Endpoint{Interface: iface}
+		if m[2] != "untag" {
+			cvid, _ := strconv.Atoi(m[2])
+			e.CVID = int32(cvid)
+		}
+		v.Endpoints = append(v.Endpoints, e)
+	}
+	SortEndpoints(v.Endpoints)
+	return v, nil
+}
+
+// CreateL2VSN creates the ELAN I-SID with its endpoints and name.
+func (c *Client) CreateL2VSN(ctx context.Context, v L2VSN) error {
+	return c.Configure(ctx, l2vsnCommands(L2VSN{ISID: v.ISID}, v, true)...)
+}
+
+// UpdateL2VSN changes the name and endpoints of the ELAN I-SID from old to v.
+// Only the endpoints added or removed are configured.
+func (c *Client) UpdateL2VSN(ctx context.Context, old, v L2VSN) error {
+	cmds := l2vsnCommands(old, v, false)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// DeleteL2VSN deletes the ELAN I-SID, which also removes its endpoints.
+func (c *Client) DeleteL2VSN(ctx context.Context, isid int32) error {
+	return c.Configure(ctx, fmt.Sprintf("no i-sid %d", isid))
+}
+
+// l2vsnCommands returns the commands changing the ELAN I-SID from old to v.
+// The I-SID is only entered when endpoints change, unless create is set.
+func l2vsnCommands(old, v L2VSN, create bool) []string {
+	oldSet := make(map[Endpoint]bool, len(old.Endpoints))
+	for _, e := range old.Endpoints {
+		oldSet[e] = true
+	}
+	newSet := make(map[Endpoint]bool, len(v.Endpoints))
+	for _, e := range v.Endpoints {
+		newSet[e] = true
+	}
+
+	var removed, added []Endpoint
+	for _, e := range old.Endpoints {
+		if !newSet[e] {
+			removed = append(removed, e)
+		}
+	}
+	for _, e := range v.Endpoints {
+		if !oldSet[e] {
+			added = append(added, e)
+		}
+	}
+	SortEndpoints(removed)
+	SortEndpoints(added)
+
+	var cmds []string
+	if create || len(removed) > 0 || len(added) > 0 {
+		cmds = append(cmds, fmt.Sprintf("i-sid %d elan", v.ISID))
+		for _, e := range removed {
+			cmds = append(cmds, "no "+e.command())
+		}
+		for _, e := range added {
+			cmds = append(cmds, e.command())
+		}
+		cmds = append(cmds, "exit")
+	}
+	if v.Name != old.Name && v.Name != "" {
+		cmds = append(cmds, fmt.Sprintf("i-sid name %d %q", v.ISID, v.Name))
+	}
+	return cmds
+}
@@ -0,0 +1,102 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestL2VSN(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	// Flex UNI endpoints must be out of every VLAN and have Flex UNI enabled.
+	err := c.Configure(ctx, "vlan members remove 1 1/1-1/4", "mlt 2",
+		"interface gigabitEthernet 1/1-1/4", "flex-uni enable", "exit",
+		"interface mlt 2", "flex-uni enable", "exit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := c.L2VSN(ctx, 20000); err != nil || v != nil {
+		t.Fatalf("got %v, %v for a missing I-SID", v, err)
+	}
+
+	want := L2VSN{
+		ISID: 20000,
+		Name: "Campus",
+		Endpoints: []Endpoint{
+			{Interface: Interface{Port: "1/1"}, CVID: 100},
+			{Interface: Interface{Port: "1/2"}},
+			{Interface: Interface{MLT: 2}, CVID: 100},
+		},
+	}
+	if err := c.CreateL2VSN(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.L2VSN(ctx, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	updated := L2VSN{
+		ISID: 20000,
+		Name: "Campus",
+		Endpoints: []Endpoint{
+			{Interface: Interface{Port: "1/1"}, CVID: 100},
+			{Interface: Interface{Port: "1/3"}, CVID: 200},
+			{Interface: Interface{MLT: 2}, CVID: 100},
+		},
+	}
+	before := len(srv.Commands())
+	if err := c.UpdateL2VSN(ctx, want, updated); err != nil {
+		t.Fatal(err)
+	}
+	// Unchanged endpoints are not touched.
+	for _, cmd := range srv.Commands()[before:] {
+		if cmd == "c-vid 100 port 1/1" || cmd == "c-vid 100 mlt 2" {
+			t.Errorf("unchanged endpoint configured again: %q", cmd)
+		}
+	}
+	if got, _ := c.L2VSN(ctx, 20000); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+
+	if err := c.DeleteL2VSN(ctx, 20000); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.L2VSN(ctx, 20000); v != nil {
+		t.Errorf("I-SID still exists after delete: %+v", v)
+	}
+}
+
+func TestL2VSNCommands(t *testing.T) {
+	old := L2VSN{ISID: 20000, Name: "ISID-20000", Endpoints: []Endpoint{
+		{Interface: Interface{Port: "1/1"}, CVID: 100},
+		{Interface: Interface{Port: "1/2"}},
+	}}
+	v := L2VSN{ISID: 20000, Name: "Campus", Endpoints: []Endpoint{
+		{Interface: Interface{Port: "1/1"}, CVID: 100},
+		{Interface: Interface{MLT: 2}},
+	}}
+	want := []string{
+		"i-sid 20000 elan",
+		"no untagged-traffic port 1/2",
+		"untagged-traffic mlt 2",
+		"exit",
+		`i-sid name 20000 "Campus"`,
+	}
+	if got := l2vsnCommands(old, v, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Renaming alone does not enter the I-SID.
+	v.Endpoints = old.Endpoints
+	want = []string{`i-sid name 20000 "Campus"`}
+	if got := l2vsnCommands(old, v, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// MLT is a multi-link trunk and its member ports.
+type MLT struct {
+	ID   int32
+	Name string
+	// Tagged reports whether "mlt <id> encapsulation dot1q" is set.
+	Tagged bool
+	// Members are the member ports: the static members, or with LACP the
+	// ports aggregated with its key.
+	Members []string
+	// LACPKey is the LACP key of the MLT, 0 for a static MLT.
+	LACPKey int32
+}
+
+var (
+	// mltRe matches a row of "show mlt". Names may contain spaces, so the
+	// name is delimited by the type column.
+	mltRe = regexp.MustCompile(`(?m)^(\d+)[ \t]+\d+[ \t]+(.*?)[ \t]+(access|trunk)[ \t]+\S+[ \t]+\S+[ \t]+(\S+)[ \t]+\S+[ \t]*$`)
+	// mltLACPRe matches a row of "show lacp interface mlt".
+	mltLACPRe = regexp.MustCompile(`(?m)^(\d+)[ \t]+(\d+)[ \t]+(enable|disable)[ \t]*$`)
+)
+
+// MLT reads the MLT with the given ID from "show mlt" and
+// "show lacp interface mlt". It returns nil if the MLT does not exist.
+func (c *Client) MLT(ctx context.Context, id int32) (*MLT, error) {
+	outputs, err := c.Run(ctx, "show mlt", "show lacp interface mlt")
+	if err != nil {
+		return nil, err
+	}
+
+	key := strconv.Itoa(int(id))
+	var m *MLT
+	for _, row := range mltRe.FindAllStringSubmatch(outputs[0], -1) {
+		if row[1] != key {
+			continue
+		}
+		m = &MLT{ID: id, Name: row[2], Tagged: row[3] == "trunk"}
+		if row[4] != "null" {
+			if m.Members, err = ParsePorts(row[4]); err != nil {
+				return nil, fmt.Errorf("parsing members of MLT %d: %w", id, err)
+			}
+		}
+	}
+	if m == nil {
+		return nil, nil
+	}
+
+	for _, row := range mltLACPRe.FindAllStringSubmatch(outputs[1], -1) {
+		if row[1] == key && row[3] == "enable" {
+			lacpKey, _ := strconv.Atoi(row[2])
+			m.LACPKey = int32(lacpKey)
+		}
+	}
+	return m, nil
+}
+
+// CreateMLT creates the MLT and adds its member ports.
+func (c *Client) CreateMLT(ctx context.Context, m MLT) error {
+	cmds := []string{fmt.Sprintf("mlt %d", m.ID)}
+	cmds = append(cmds, mltCommands(MLT{ID: m.ID}, m)...)
+	return c.Configure(ctx, cmds...)
+}
+
+// UpdateMLT changes the MLT from old to m. Only the member ports that
+// changed are added or removed, so the other members keep forwarding.
+// Changing the LACP key moves every member to the new key.
+func (c *Client) UpdateMLT(ctx context.Context, old, m MLT) error {
+	cmds := mltCommands(old, m)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// DeleteMLT removes the LACP configuration of the member ports, then deletes
+// the MLT.
+func (c *Client) DeleteMLT(ctx context.Context, m MLT) error {
+	var cmds []string
+	if m.LACPKey != 0 && len(m.Members) > 0 {
+		cmds = append(cmds, lacpPortCommands(m.Members, false, 0)...)
+	}
+	return c.Configure(ctx, append(cmds, fmt.Sprintf("no mlt %d", m.ID))...)
+}
+
+// mltCommands returns the commands changing the MLT from old to m. The
+// members leave the MLT before its LACP configuration changes and join it
+// after.
+func mltCommands(old, m MLT) []string {
+	var cmds []string
+	if m.Name != old.Name && m.Name != "" {
+		cmds = append(cmds, fmt.Sprintf("mlt %d name %q", m.ID, m.Name))
+	}
+	if m.Tagged != old.Tagged {
+		cmds = append(cmds, negate(!m.Tagged, fmt.Sprintf("mlt %d encapsulation dot1q", m.ID)))
+	}
+
+	keep := map[string]bool{}
+	if m.LACPKey == old.LACPKey {
+		keep = portSet(m.Members)
+	}
+	var removed []string
+	for _, port := range old.Members {
+		if !keep[port] {
+			removed = append(removed, port)
+		}
+	}
+	if len(removed) > 0 {
+		if old.LACPKey != 0 {
+			cmds = append(cmds, lacpPortCommands(removed, false, 0)...)
+		} else {
+			cmds = append(cmds, fmt.Sprintf("no mlt %d member %s", m.ID, FormatPorts(removed)))
+		}
+	}
+
+	if m.LACPKey != old.LACPKey {
+		mode := fmt.Sprintf("interface mlt %d", m.ID)
+		if old.LACPKey != 0 {
+			cmds = append(cmds, mode, "no lacp enable", "no lacp key", "exit")
+		}
+		if m.LACPKey != 0 {
+			cmds = append(cmds, mode, fmt.Sprintf("lacp key %d", m.LACPKey), "lacp enable", "exit")
+		}
+	}
+
+	existing := map[string]bool{}
+	if m.LACPKey == old.LACPKey {
+		existing = portSet(old.Members)
+	}
+	var added []string
+	for _, port := range m.Members {
+		if !existing[port] {
+			added = append(added, port)
+		}
+	}
+	if len(added) > 0 {
+		if m.LACPKey != 0 {
+			cmds = append(cmds, lacpPortCommands(added, true, m.LACPKey)...)
+		} else {
+			cmds = append(cmds, fmt.Sprintf("mlt %d member %s", m.ID, FormatPorts(added)))
+		}
+	}
+	return cmds
+}
+
+// lacpPortCommands returns the commands enabling LACP with the given key on
+// ports, or removing their LACP configuration.
+func lacpPortCommands(ports []string, enable bool, key int32) []string {
+	cmds := []string{"interface gigabitEthernet " + FormatPorts(ports)}
+	if enable {
+		cmds = append(cmds, fmt.Sprintf("lacp key %d", key), "lacp aggregation enable", "lacp enable")
+	} else {
+		cmds = append(cmds, "no lacp enable", "no lacp aggregation enable", "no lacp key")
+	}
+	return append(cmds, "exit")
+}
@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMLT(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	if m, err := c.MLT(ctx, 10); err != nil || m != nil {
+		t.Fatalf("got %v, %v for a missing MLT", m, err)
+	}
+
+	want := MLT{ID: 10, Name: "uplink core", Tagged: true, Members: []string{"1/1", "1/2"}}
+	if err := c.CreateMLT(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.MLT(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	// Only the member that changed is added or removed.
+	updated := MLT{ID: 10, Name: "uplink core", Tagged: true, Members: []string{"1/2", "1/3"}}
+	sent := len(srv.Commands())
+	if err := c.UpdateMLT(ctx, want, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.MLT(ctx, 10); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+	for _, cmd := range srv.Commands()[sent:] {
+		if strings.Contains(cmd, "1/2") || cmd == "no mlt 10" {
+			t.Errorf("unchanged member or MLT reconfigured: %q", cmd)
+		}
+	}
+
+	if err := c.DeleteMLT(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := c.MLT(ctx, 10); err != nil || m != nil {
+		t.Errorf("got %v, %v after deleting the MLT", m, err)
+	}
+}
+
+func TestMLTLACP(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	static := MLT{ID: 20, Name: "MLT-20", Members: []string{"2/1", "2/2"}}
+	if err := c.CreateMLT(ctx, static); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving to LACP replaces the static members with LACP members.
+	want := MLT{ID: 20, Name: "MLT-20", Members: []string{"2/1", "2/2"}, LACPKey: 20}
+	if err := c.UpdateMLT(ctx, static, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.MLT(ctx, 20); !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+	cfg := srv.RunningConfig()
+	for _, line := range []string{"interface mlt 20\nlacp key 20\nlacp enable\n", "lacp key 20\nlacp aggregation enable\nlacp enable\n"} {
+		if !strings.Contains(cfg, line) {
+			t.Errorf("missing %q in:\n%s", line, cfg)
+		}
+	}
+	if strings.Contains(cfg, "mlt 20 member") {
+		t.Errorf("static members left:\n%s", cfg)
+	}
+
+	added := MLT{ID: 20, Name: "MLT-20", Members: []string{"2/1", "2/2", "2/3"}, LACPKey: 20}
+	if err := c.UpdateMLT(ctx, want, added); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.MLT(ctx, 20); !reflect.DeepEqual(*got, added) {
+		t.Errorf("got %+v, want %+v", *got, added)
+	}
+
+	if err := c.DeleteMLT(ctx, added); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := srv.RunningConfig(); strings.Contains(cfg, "lacp") {
+		t.Errorf("LACP configuration left after deleting the MLT:\n%s", cfg)
+	}
+}
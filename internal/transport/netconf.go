@@ -0,0 +1,424 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultNETCONFPort is the port of the NETCONF SSH subsystem.
+const DefaultNETCONFPort = 830
+
+const (
+	netconfBase10 = "urn:ietf:params:netconf:base:1.0"
+	netconfBase11 = "urn:ietf:params:netconf:base:1.1"
+	netconfEOM    = "]]>]]>"
+
+	openconfigSystemNS = "http://openconfig.net/yang/system"
+)
+
+// RPCError is an <rpc-error> returned by the device.
+type RPCError struct {
+	Type     string `xml:"error-type"`
+	Tag      string `xml:"error-tag"`
+	Severity string `xml:"error-severity"`
+	Path     string `xml:"error-path"`
+	Message  string `xml:"error-message"`
+}
+
+func (e *RPCError) Error() string {
+	msg := strings.TrimSpace(e.Message)
+	if msg == "" {
+		msg = e.Tag
+	}
+	if e.Path != "" {
+		return fmt.Sprintf("%s (%s): %s", e.Operation(), strings.TrimSpace(e.Path), msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Operation(), msg)
+}
+
+// Operation returns the error-type and error-tag of e, e.g.
+// "application/invalid-value".
+func (e *RPCError) Operation() string {
+	return e.Type + "/" + e.Tag
+}
+
+// NETCONFClient manages the configuration of a device through the NETCONF
+// SSH subsystem. Changes are staged in the candidate datastore and committed
+// as a whole, or discarded if any step fails. It is safe for concurrent use;
+// operations are serialized on a single session.
+type NETCONFClient struct {
+	config Config
+
+	mu      sync.Mutex
+	session *netconfSession
+	closed  bool
+}
+
+var _ System = &NETCONFClient{}
+
+// NewNETCONFClient returns a client for the device described by config. The
+// session is opened on the first operation.
+func NewNETCONFClient(config Config) *NETCONFClient {
+	return &NETCONFClient{config: config}
+}
+
+// Close closes the NETCONF session.
+func (c *NETCONFClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.session == nil {
+		return nil
+	}
+	err := c.session.close()
+	c.session = nil
+	return err
+}
+
+// GetConfig returns the content of the <data> element for the source
+// datastore, restricted by the subtree filter when it is not empty.
+func (c *NETCONFClient) GetConfig(ctx context.Context, source, filter string) (string, error) {
+	var data string
+	err := c.do(ctx, func(s *netconfSession) error {
+		var err error
+		data, err = s.getConfig(ctx, source, filter)
+		return err
+	})
+	return data, err
+}
+
+// Apply stages config in the candidate datastore, validates it and commits
+// it. The candidate is locked for the duration of the operation and its
+// changes are discarded when any step fails.
+func (c *NETCONFClient) Apply(ctx context.Context, config string) error {
+	return c.do(ctx, func(s *netconfSession) error {
+		return s.apply(ctx, config)
+	})
+}
+
+// Hostname reads the system name from the running datastore.
+func (c *NETCONFClient) Hostname(ctx context.Context) (string, error) {
+	filter := `<system xmlns="` + openconfigSystemNS + `"><config><hostname/></config></system>`
+	data, err := c.GetConfig(ctx, "running", filter)
+	if err != nil {
+		return "", err
+	}
+
+	var doc struct {
+		Hostname string `xml:"system>config>hostname"`
+	}
+	if err := xml.Unmarshal([]byte("<data>"+data+"</data>"), &doc); err != nil {
+		return "", fmt.Errorf("decoding system configuration: %w", err)
+	}
+	if doc.Hostname == "" {
+		return "", errors.New("no hostname in the running configuration")
+	}
+	return doc.Hostname, nil
+}
+
+// SetHostname changes the system name through the candidate datastore.
+func (c *NETCONFClient) SetHostname(ctx context.Context, name string) error {
+	var escaped bytes.Buffer
+	if err := xml.EscapeText(&escaped, []byte(name)); err != nil {
+		return err
+	}
+	return c.Apply(ctx, `<system xmlns="`+openconfigSystemNS+`"><config><hostname>`+
+		escaped.String()+`</hostname></config></system>`)
+}
+
+// ResetHostname removes the configured system name so the device falls back
+// to its default.
+func (c *NETCONFClient) ResetHostname(ctx context.Context) error {
+	return c.Apply(ctx, `<system xmlns="`+openconfigSystemNS+`"><config>`+
+		`<hostname xmlns:nc="`+netconfBase10+`" nc:operation="remove"/></config></system>`)
+}
+
+// do runs fn on the shared session, opening it on first use. A session that
+// fails for any reason other than an <rpc-error> is discarded; if it had been
+// reused, fn is retried once on a fresh session.
+func (c *NETCONFClient) do(ctx context.Context, fn func(*netconfSession) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		if c.closed {
+			return ErrClientClosed
+		}
+
+		reused := c.session != nil
+		if !reused {
+			s, err := openNETCONF(ctx, c.config)
+			if err != nil {
+				return err
+			}
+			c.session = s
+		}
+
+		err := fn(c.session)
+		var rpcErr *RPCError
+		if err == nil || errors.As(err, &rpcErr) {
+			return err
+		}
+
+		c.session.close()
+		c.session = nil
+		if !reused || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// netconfSession is an established NETCONF session.
+type netconfSession struct {
+	conn    *ssh.Client
+	session *ssh.Session
+	w       io.WriteCloser
+	r       *bufio.Reader
+	chunked bool
+	nextID  int
+}
+
+func openNETCONF(ctx context.Context, config Config) (*netconfSession, error) {
+	auth, release, err := config.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ssh.ClientConfig{
+		User:            config.Username,
+		Auth:            auth,
+		HostKeyCallback: config.hostKeyCallback(),
+		Timeout:         config.timeout(),
+	}
+
+	conn, err := dial(ctx, config.Address(), cfg)
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", config.Address(), err)
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("creating SSH session: %w", err)
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("getting stdin pipe: %w", err)
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("getting stdout pipe: %w", err)
+	}
+	if err := session.RequestSubsystem("netconf"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("starting netconf subsystem: %w", err)
+	}
+
+	s := &netconfSession{conn: conn, session: session, w: w, r: bufio.NewReader(r)}
+	if err := s.hello(ctx); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *netconfSession) close() error {
+	s.session.Close()
+	return s.conn.Close()
+}
+
+// hello exchanges capabilities and switches to chunked framing when both
+// sides support base:1.1.
+func (s *netconfSession) hello(ctx context.Context) error {
+	msg, err := s.read(ctx)
+	if err != nil {
+		return fmt.Errorf("reading server hello: %w", err)
+	}
+	var hello struct {
+		Capabilities []string `xml:"capabilities>capability"`
+	}
+	if err := xml.Unmarshal(msg, &hello); err != nil {
+		return fmt.Errorf("decoding server hello: %w", err)
+	}
+
+	ours := `<hello xmlns="` + netconfBase10 + `"><capabilities>` +
+		`<capability>` + netconfBase10 + `</capability>` +
+		`<capability>` + netconfBase11 + `</capability>` +
+		`</capabilities></hello>`
+	if err := s.write([]byte(ours)); err != nil {
+		return fmt.Errorf("sending client hello: %w", err)
+	}
+
+	for _, capability := range hello.Capabilities {
+		if strings.TrimSpace(capability) == netconfBase11 {
+			s.chunked = true
+		}
+	}
+	return nil
+}
+
+// rpcReply is the subset of <rpc-reply> the client understands.
+type rpcReply struct {
+	Errors []RPCError `xml:"rpc-error"`
+	Data   struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"data"`
+}
+
+// rpc sends op wrapped in an <rpc> element and returns the reply. Errors of
+// severity "warning" are ignored.
+func (s *netconfSession) rpc(ctx context.Context, op string) (*rpcReply, error) {
+	s.nextID++
+	msg := `<rpc message-id="` + strconv.Itoa(s.nextID) + `" xmlns="` + netconfBase10 + `">` + op + `</rpc>`
+	if err := s.write([]byte(msg)); err != nil {
+		return nil, err
+	}
+
+	data, err := s.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reply rpcReply
+	if err := xml.Unmarshal(data, &reply); err != nil {
+		return nil, fmt.Errorf("decoding rpc-reply: %w", err)
+	}
+	for i := range reply.Errors {
+		if reply.Errors[i].Severity != "warning" {
+			return &reply, &reply.Errors[i]
+		}
+	}
+	return &reply, nil
+}
+
+func (s *netconfSession) getConfig(ctx context.Context, source, filter string) (string, error) {
+	op := `<get-config><source><` + source + `/></source>`
+	if filter != "" {
+		op += `<filter type="subtree">` + filter + `</filter>`
+	}
+	op += `</get-config>`
+
+	reply, err := s.rpc(ctx, op)
+	if err != nil {
+		return "", err
+	}
+	return reply.Data.Inner, nil
+}
+
+func (s *netconfSession) apply(ctx context.Context, config string) error {
+	if _, err := s.rpc(ctx, `<lock><target><candidate/></target></lock>`); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = s.rpc(ctx, `<unlock><target><candidate/></target></unlock>`)
+	}()
+
+	steps := []string{
+		`<edit-config><target><candidate/></target><config>` + config + `</config></edit-config>`,
+		`<validate><source><candidate/></source></validate>`,
+		`<commit/>`,
+	}
+	for _, step := range steps {
+		if _, err := s.rpc(ctx, step); err != nil {
+			if _, discardErr := s.rpc(ctx, `<discard-changes/>`); discardErr != nil {
+				return errors.Join(err, fmt.Errorf("discarding candidate changes: %w", discardErr))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// write sends msg using the framing negotiated during hello.
+func (s *netconfSession) write(msg []byte) error {
+	var buf bytes.Buffer
+	if s.chunked {
+		fmt.Fprintf(&buf, "\n#%d\n", len(msg))
+		buf.Write(msg)
+		buf.WriteString("\n##\n")
+	} else {
+		buf.Write(msg)
+		buf.WriteString(netconfEOM)
+	}
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// read returns the next message. The session is closed when ctx is done so
+// that the blocked read returns.
+func (s *netconfSession) read(ctx context.Context) ([]byte, error) {
+	stop := context.AfterFunc(ctx, func() { s.conn.Close() })
+	defer stop()
+
+	var msg []byte
+	var err error
+	if s.chunked {
+		msg, err = readChunked(s.r)
+	} else {
+		msg, err = readEOM(s.r)
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return msg, err
+}
+
+// readEOM reads a message terminated by the base:1.0 end-of-message marker.
+func readEOM(r *bufio.Reader) ([]byte, error) {
+	var msg []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, b)
+		if bytes.HasSuffix(msg, []byte(netconfEOM)) {
+			return bytes.TrimSpace(msg[:len(msg)-len(netconfEOM)]), nil
+		}
+	}
+}
+
+// readChunked reads a message using the base:1.1 chunked framing.
+func readChunked(r *bufio.Reader) ([]byte, error) {
+	var msg []byte
+	for {
+		header, err := r.ReadString('#')
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(strings.TrimSuffix(header, "#")) != "" {
+			return nil, fmt.Errorf("invalid chunk header %q", header)
+		}
+
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "#" {
+			return msg, nil
+		}
+
+		size, err := strconv.Atoi(line)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid chunk size %q", line)
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, err
+		}
+		msg = append(msg, chunk...)
+	}
+}
@@ -0,0 +1,181 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// netconf serves the netconf subsystem of the test server. It keeps the
+// hostname in a running and a candidate datastore and refuses to validate
+// the hostname "invalid".
+func (s *testServer) netconf(ch ssh.Channel) {
+	defer ch.Close()
+
+	caps := "<capability>" + netconfBase10 + "</capability>"
+	if !s.netconf10Only {
+		caps += "<capability>" + netconfBase11 + "</capability>"
+	}
+	fmt.Fprintf(ch, `<hello xmlns="%s"><capabilities>%s</capabilities><session-id>1</session-id></hello>%s`,
+		netconfBase10, caps, netconfEOM)
+
+	r := bufio.NewReader(ch)
+	if _, err := readEOM(r); err != nil {
+		return
+	}
+	session := &netconfSession{w: nopCloser{ch}, chunked: !s.netconf10Only}
+
+	s.mu.Lock()
+	candidate := s.hostname
+	s.mu.Unlock()
+	for {
+		var msg []byte
+		var err error
+		if session.chunked {
+			msg, err = readChunked(r)
+		} else {
+			msg, err = readEOM(r)
+		}
+		if err != nil {
+			return
+		}
+
+		var rpc struct {
+			MessageID string `xml:"message-id,attr"`
+			Op        struct {
+				XMLName  xml.Name
+				Hostname string `xml:"config>system>config>hostname"`
+			} `xml:",any"`
+		}
+		if err := xml.Unmarshal(msg, &rpc); err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.netconfOps = append(s.netconfOps, rpc.Op.XMLName.Local)
+		body := "<ok/>"
+		switch rpc.Op.XMLName.Local {
+		case "get-config":
+			body = `<data><system xmlns="` + openconfigSystemNS + `"><config><hostname>` +
+				s.hostname + `</hostname></config></system></data>`
+		case "edit-config":
+			candidate = rpc.Op.Hostname
+		case "validate":
+			if candidate == "invalid" {
+				body = `<rpc-error><error-type>application</error-type><error-tag>invalid-value</error-tag>` +
+					`<error-severity>error</error-severity><error-message>invalid hostname</error-message></rpc-error>`
+			}
+		case "commit":
+			s.hostname = candidate
+		case "discard-changes":
+			candidate = s.hostname
+		}
+		s.mu.Unlock()
+
+		reply := fmt.Sprintf(`<rpc-reply message-id="%s" xmlns="%s">%s</rpc-reply>`, rpc.MessageID, netconfBase10, body)
+		if err := session.write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestNETCONFHostname(t *testing.T) {
+	for _, base10 := range []bool{false, true} {
+		srv := newTestServer(t)
+		srv.netconf10Only = base10
+		c := NewNETCONFClient(srv.config())
+
+		ctx := context.Background()
+		name, err := c.Hostname(ctx)
+		if err != nil {
+			t.Fatalf("base:1.0 only %v: %v", base10, err)
+		}
+		if name != "LAB" {
+			t.Errorf("got hostname %q, want %q", name, "LAB")
+		}
+
+		if err := c.SetHostname(ctx, "LAB-VOSS02"); err != nil {
+			t.Fatalf("base:1.0 only %v: %v", base10, err)
+		}
+		if name, _ := c.Hostname(ctx); name != "LAB-VOSS02" {
+			t.Errorf("got hostname %q after update", name)
+		}
+		c.Close()
+
+		want := "get-config lock edit-config validate commit unlock get-config"
+		if got := strings.Join(srv.netconfOps, " "); got != want {
+			t.Errorf("got operations %q, want %q", got, want)
+		}
+		if n := srv.dials.Load(); n != 1 {
+			t.Errorf("client dialed %d times, want 1", n)
+		}
+	}
+}
+
+func TestNETCONFDiscardsOnError(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewNETCONFClient(srv.config())
+	defer c.Close()
+
+	ctx := context.Background()
+	err := c.SetHostname(ctx, "invalid")
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("got %v, want *RPCError", err)
+	}
+	if rpcErr.Tag != "invalid-value" || !strings.Contains(err.Error(), "invalid hostname") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	want := "lock edit-config validate discard-changes unlock"
+	if got := strings.Join(srv.netconfOps, " "); got != want {
+		t.Errorf("got operations %q, want %q", got, want)
+	}
+	if name, _ := c.Hostname(ctx); name != "LAB" {
+		t.Errorf("hostname changed to %q despite the error", name)
+	}
+}
+
+func TestNETCONFFraming(t *testing.T) {
+	var buf bytes.Buffer
+	s := &netconfSession{w: nopCloser{&buf}, chunked: true}
+	if err := s.write([]byte("<rpc/>")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "\n#6\n<rpc/>\n##\n" {
+		t.Errorf("unexpected chunked frame %q", buf.String())
+	}
+
+	multi := "\n#4\n<rpc\n#2\n/>\n##\n"
+	msg, err := readChunked(bufio.NewReader(strings.NewReader(multi)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg) != "<rpc/>" {
+		t.Errorf("got %q", msg)
+	}
+
+	if _, err := readChunked(bufio.NewReader(strings.NewReader("\n#x\n"))); err == nil {
+		t.Error("invalid chunk size accepted")
+	}
+
+	msg, err = readEOM(bufio.NewReader(strings.NewReader("<hello/>\n" + netconfEOM)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg) != "<hello/>" {
+		t.Errorf("got %q", msg)
+	}
+}
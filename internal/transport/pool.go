@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClientClosed is returned when a command is run on a closed client.
+var ErrClientClosed = errors.New("client closed")
+
+// sessionPool hands out at most size sessions at a time and keeps released
+// sessions open for reuse.
+type sessionPool struct {
+	slots chan struct{}
+
+	mu     sync.Mutex
+	idle   []*Session
+	closed bool
+}
+
+func newSessionPool(size int) *sessionPool {
+	return &sessionPool{slots: make(chan struct{}, size)}
+}
+
+// acquire returns an idle session, or one created with open when none is
+// available. reused reports whether the session had been used before.
+func (p *sessionPool) acquire(
+	ctx context.Context, open func(context.Context) (*Session, error)) (s *Session, reused bool, err error) {
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.slots
+		return nil, false, ErrClientClosed
+	}
+	if n := len(p.idle); n > 0 {
+		s = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return s, true, nil
+	}
+	p.mu.Unlock()
+
+	s, err = open(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, false, err
+	}
+	return s, false, nil
+}
+
+// release returns s to the pool, or closes it if it is broken or the pool
+// has been closed.
+func (p *sessionPool) release(s *Session, broken bool) {
+	p.mu.Lock()
+	if broken || p.closed {
+		p.mu.Unlock()
+		s.Close()
+	} else {
+		p.idle = append(p.idle, s)
+		p.mu.Unlock()
+	}
+	<-p.slots
+}
+
+// close closes all idle sessions and makes further acquire calls fail.
+func (p *sessionPool) close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	var errs []error
+	for _, s := range idle {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
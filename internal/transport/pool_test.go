@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestClientReusesSession(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient(srv.config())
+	defer c.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		out, err := c.Run(ctx, "echo hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out[0] != "hello" {
+			t.Errorf("got %q, want %q", out[0], "hello")
+		}
+	}
+	if err := c.Configure(ctx, "sys name LAB"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := srv.dials.Load(); n != 1 {
+		t.Errorf("client dialed %d times, want 1", n)
+	}
+}
+
+func TestClientMaxSessions(t *testing.T) {
+	srv := newTestServer(t)
+	cfg := srv.config()
+	cfg.MaxSessions = 2
+	c := NewClient(cfg)
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Run(context.Background(), "echo hello"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := srv.dials.Load(); n > 2 {
+		t.Errorf("client dialed %d times, want at most 2", n)
+	}
+}
+
+func TestClientReconnects(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient(srv.config())
+	defer c.Close()
+
+	ctx := context.Background()
+	if _, err := c.Run(ctx, "echo hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.dropAll()
+
+	if _, err := c.Run(ctx, "echo again"); err != nil {
+		t.Fatalf("run after drop: %v", err)
+	}
+	if n := srv.dials.Load(); n != 2 {
+		t.Errorf("client dialed %d times, want 2", n)
+	}
+}
+
+func TestClientDoesNotResendConfiguration(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient(srv.config())
+	defer c.Close()
+
+	ctx := context.Background()
+	if _, err := c.Run(ctx, "echo hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reused session dies after the first command reached the device:
+	// the batch must fail rather than run again on a new connection.
+	if err := c.Configure(ctx, "sys name LAB", "drop"); err == nil {
+		t.Fatal("configure succeeded on a dropped session")
+	}
+	if n := srv.dials.Load(); n != 1 {
+		t.Errorf("client dialed %d times, want 1", n)
+	}
+}
+
+func TestClientKeepsSessionOnRejectedCommand(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient(srv.config())
+	defer c.Close()
+
+	ctx := context.Background()
+	var cmdErr *CommandError
+	if err := c.Configure(ctx, "bad command"); !errors.As(err, &cmdErr) {
+		t.Fatalf("got %v, want *CommandError", err)
+	}
+	if _, err := c.Run(ctx, "echo hello"); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.dials.Load(); n != 1 {
+		t.Errorf("client dialed %d times, want 1", n)
+	}
+}
+
+func TestClientClosed(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient(srv.config())
+
+	if _, err := c.Run(context.Background(), "echo hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Run(context.Background(), "echo hello"); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("got %v, want ErrClientClosed", err)
+	}
+}
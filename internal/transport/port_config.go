@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// DefaultPortConfig is the configuration of a port with factory default
+// settings.
+var DefaultPortConfig = PortConfig{AutoNegotiation: true, DefaultVLAN: 1, SpanningTreeLearning: true}
+
+// PortConfig is the configuration of a port under
+// "interface gigabitEthernet".
+type PortConfig struct {
+	// Name is set with "name", empty if none.
+	Name     string
+	Shutdown bool
+	// AutoNegotiation reports whether "auto-negotiate enable" is set. Speed,
+	// in Mb/s, and Duplex, "full" or "half", only apply while it is disabled.
+	AutoNegotiation bool
+	Speed           int32
+	Duplex          string
+	// DefaultVLAN is set with "default-vlan-id".
+	DefaultVLAN int32
+	// Tagged reports whether "encapsulation dot1q" is set.
+	Tagged bool
+	// UntagDefaultVLAN reports whether "untag-port-default-vlan enable" is
+	// set.
+	UntagDefaultVLAN bool
+	FlexUNI          bool
+	AutoSense        bool
+	// SpanningTreeLearning is cleared with "spanning-tree mstp learning
+	// disable".
+	SpanningTreeLearning bool
+}
+
+var (
+	// portConfigRe matches a row of "show interfaces gigabitEthernet config".
+	portConfigRe = regexp.MustCompile(`(?m)^(\d+/\d+(?:/\d+)?)[ \t]+\d+[ \t]+(up|down)[ \t]+(enable|disable)[ \t]+(\d+)[ \t]+(full|half)[ \t]+(\d+)` +
+		`[ \t]+(enable|disable)[ \t]+(enable|disable)[ \t]+(enable|disable)[ \t]+(enable|disable)[ \t]+(enable|disable)[ \t]*$`)
+	// portNameRe matches a row of "show interfaces gigabitEthernet name".
+	// Names may contain spaces, so the name is delimited by the description
+	// column.
+	portNameRe = regexp.MustCompile(`(?m)^(\d+/\d+(?:/\d+)?)[ \t]+(.*?)[ \t]+\S+[ \t]+(?:up|down)[ \t]+(?:full|half)[ \t]+\d+[ \t]+\S+[ \t]*$`)
+)
+
+// PortConfigs reads the configuration of ports from
+// "show interfaces gigabitEthernet config" and
+// "show interfaces gigabitEthernet name". Ports that do not exist are
+// missing from the result.
+func (c *Client) PortConfigs(ctx context.Context, ports []string) (map[string]PortConfig, error) {
+	outputs, err := c.Run(ctx, "show interfaces gigabitEthernet config", "show interfaces gigabitEthernet name")
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := portSet(ports)
+	configs := map[string]PortConfig{}
+	for _, m := range portConfigRe.FindAllStringSubmatch(outputs[0], -1) {
+		if !wanted[m[1]] {
+			continue
+		}
+		speed, _ := strconv.Atoi(m[4])
+		vlan, _ := strconv.Atoi(m[6])
+		configs[m[1]] = PortConfig{
+			Shutdown:             m[2] == "down",
+			AutoNegotiation:      m[3] == "enable",
+			Speed:                int32(speed),
+			Duplex:               m[5],
+			DefaultVLAN:          int32(vlan),
+			Tagged:               m[7] == "enable",
+			UntagDefaultVLAN:     m[8] == "enable",
+			FlexUNI:              m[9] == "enable",
+			AutoSense:            m[10] == "enable",
+			SpanningTreeLearning: m[11] == "enable",
+		}
+	}
+	for _, m := range portNameRe.FindAllStringSubmatch(outputs[1], -1) {
+		if p, ok := configs[m[1]]; ok {
+			p.Name = m[2]
+			configs[m[1]] = p
+		}
+	}
+	return configs, nil
+}
+
+// ApplyPortConfig changes the configuration of ports from old to p. Only the
+// settings that changed are configured. Speed and duplex are configured
+// whenever auto-negotiation is disabled and one of them changed.
+func (c *Client) ApplyPortConfig(ctx context.Context, ports []string, old, p PortConfig) error {
+	var cmds []string
+	if old.UntagDefaultVLAN && !p.UntagDefaultVLAN {
+		cmds = append(cmds, "no untag-port-default-vlan enable")
+	}
+	// Flex UNI and auto-sense exclude each other, so the one being disabled
+	// goes first.
+	if old.FlexUNI && !p.FlexUNI {
+		cmds = append(cmds, "no flex-uni enable")
+	}
+	if old.AutoSense && !p.AutoSense {
+		cmds = append(cmds, "no auto-sense enable")
+	}
+
+	if p.Name != old.Name {
+		if p.Name == "" {
+			cmds = append(cmds, "no name")
+		} else {
+			cmds = append(cmds, fmt.Sprintf("name %q", p.Name))
+		}
+	}
+	switch {
+	case p.AutoNegotiation && !old.AutoNegotiation:
+		cmds = append(cmds, "auto-negotiate enable")
+	case !p.AutoNegotiation && (old.AutoNegotiation || p.Speed != old.Speed || p.Duplex != old.Duplex):
+		cmds = append(cmds, "no auto-negotiate enable",
+			fmt.Sprintf("speed %d", p.Speed), "duplex "+p.Duplex)
+	}
+	if p.Tagged != old.Tagged {
+		cmds = append(cmds, negate(!p.Tagged, "encapsulation dot1q"))
+	}
+	if p.DefaultVLAN != old.DefaultVLAN {
+		cmds = append(cmds, fmt.Sprintf("default-vlan-id %d", p.DefaultVLAN))
+	}
+	if p.UntagDefaultVLAN && !old.UntagDefaultVLAN {
+		cmds = append(cmds, "untag-port-default-vlan enable")
+	}
+	if p.SpanningTreeLearning != old.SpanningTreeLearning {
+		if p.SpanningTreeLearning {
+			cmds = append(cmds, "spanning-tree mstp learning enable")
+		} else {
+			cmds = append(cmds, "spanning-tree mstp learning disable")
+		}
+	}
+	if p.FlexUNI && !old.FlexUNI {
+		cmds = append(cmds, "flex-uni enable")
+	}
+	if p.AutoSense && !old.AutoSense {
+		cmds = append(cmds, "auto-sense enable")
+	}
+	if p.Shutdown != old.Shutdown {
+		cmds = append(cmds, negate(!p.Shutdown, "shutdown"))
+	}
+
+	if len(cmds) == 0 {
+		return nil
+	}
+	cmds = append([]string{"interface gigabitEthernet " + FormatPorts(ports)}, cmds...)
+	return c.Configure(ctx, append(cmds, "exit")...)
+}
+
+// SummarizePortConfigs returns the configuration of several ports as one:
+// each setting is the one of want when every port has it, or else the value
+// of the first port that differs. Applying want over the summary then
+// reconfigures every setting that is not already right on all the ports.
+func SummarizePortConfigs(configs []PortConfig, want PortConfig) PortConfig {
+	return PortConfig{
+		Name:                 summarize(configs, want.Name, func(p PortConfig) string { return p.Name }),
+		Shutdown:             summarize(configs, want.Shutdown, func(p PortConfig) bool { return p.Shutdown }),
+		AutoNegotiation:      summarize(configs, want.AutoNegotiation, func(p PortConfig) bool { return p.AutoNegotiation }),
+		Speed:                summarize(configs, want.Speed, func(p PortConfig) int32 { return p.Speed }),
+		Duplex:               summarize(configs, want.Duplex, func(p PortConfig) string { return p.Duplex }),
+		DefaultVLAN:          summarize(configs, want.DefaultVLAN, func(p PortConfig) int32 { return p.DefaultVLAN }),
+		Tagged:               summarize(configs, want.Tagged, func(p PortConfig) bool { return p.Tagged }),
+		UntagDefaultVLAN:     summarize(configs, want.UntagDefaultVLAN, func(p PortConfig) bool { return p.UntagDefaultVLAN }),
+		FlexUNI:              summarize(configs, want.FlexUNI, func(p PortConfig) bool { return p.FlexUNI }),
+		AutoSense:            summarize(configs, want.AutoSense, func(p PortConfig) bool { return p.AutoSense }),
+		SpanningTreeLearning: summarize(configs, want.SpanningTreeLearning, func(p PortConfig) bool { return p.SpanningTreeLearning }),
+	}
+}
+
+// summarize returns the first value of a setting that differs from want, or
+// want.
+func summarize[T comparable](configs []PortConfig, want T, setting func(PortConfig) T) T {
+	for _, p := range configs {
+		if v := setting(p); v != want {
+			return v
+		}
+	}
+	return want
+}
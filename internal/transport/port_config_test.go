@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPortConfig(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+	srv.Exec("configure terminal", "vlan create 10 type port-mstprstp 0", "vlan members remove 1 1/1-1/3", "end")
+
+	ports := []string{"1/1", "1/2", "1/3"}
+	configs, err := c.PortConfigs(ctx, append([]string{"9/1"}, ports...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != len(ports) {
+		t.Fatalf("got %d ports, want %d: %+v", len(configs), len(ports), configs)
+	}
+	def := DefaultPortConfig
+	def.Speed, def.Duplex = 10000, "full"
+	if configs["1/1"] != def {
+		t.Errorf("got %+v, want %+v", configs["1/1"], def)
+	}
+
+	want := PortConfig{
+		Name:                 "access ports",
+		Speed:                1000,
+		Duplex:               "full",
+		DefaultVLAN:          10,
+		Tagged:               true,
+		UntagDefaultVLAN:     true,
+		SpanningTreeLearning: false,
+	}
+	if err := c.ApplyPortConfig(ctx, ports, def, want); err != nil {
+		t.Fatal(err)
+	}
+	configs, err = c.PortConfigs(ctx, ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, port := range ports {
+		if configs[port] != want {
+			t.Errorf("port %s: got %+v, want %+v", port, configs[port], want)
+		}
+	}
+	if cfg := srv.RunningConfig(); !strings.Contains(cfg, "interface gigabitEthernet 1/2\nname \"access ports\"\nno auto-negotiate enable\nspeed 1000\nduplex full\n") {
+		t.Errorf("port configuration missing:\n%s", cfg)
+	}
+
+	// Only the settings that changed are configured.
+	updated := want
+	updated.Tagged, updated.UntagDefaultVLAN, updated.FlexUNI, updated.Shutdown = false, false, true, true
+	sent := len(srv.Commands())
+	if err := c.ApplyPortConfig(ctx, ports, want, updated); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range srv.Commands()[sent:] {
+		if strings.HasPrefix(cmd, "name") || strings.HasPrefix(cmd, "speed") {
+			t.Errorf("unchanged setting configured again: %q", cmd)
+		}
+	}
+	if got, _ := c.PortConfigs(ctx, ports[:1]); got["1/1"] != updated {
+		t.Errorf("got %+v, want %+v", got["1/1"], updated)
+	}
+}
+
+func TestSummarizePortConfigs(t *testing.T) {
+	want := PortConfig{Name: "a", DefaultVLAN: 10}
+	configs := []PortConfig{
+		{Name: "a", DefaultVLAN: 10},
+		{Name: "b", DefaultVLAN: 10},
+		{Name: "a", DefaultVLAN: 20, Shutdown: true},
+	}
+	got := SummarizePortConfigs(configs, want)
+	if exp := (PortConfig{Name: "b", DefaultVLAN: 20, Shutdown: true}); got != exp {
+		t.Errorf("got %+v, want %+v", got, exp)
+	}
+	if got := SummarizePortConfigs(configs[:1], want); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package transport
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// portRe matches a port number, "slot/port" or "slot/port/sub-port" for the
+// lanes of a channelized port.
+var portRe = regexp.MustCompile(`^(\d+)/(\d+)(?:/(\d+))?$`)
+
+// ValidPort reports whether name is a port number such as "1/1" or "1/49/2".
+func ValidPort(name string) bool {
+	return portRe.MatchString(name)
+}
+
+// portNumbers returns the components of a valid port number.
+func portNumbers(name string) [3]int {
+	var n [3]int
+	for i, s := range portRe.FindStringSubmatch(name)[1:] {
+		n[i], _ = strconv.Atoi(s)
+	}
+	return n
+}
+
+// consecutive reports whether b directly follows a, so both belong to the
+// same range.
+func consecutive(a, b [3]int) bool {
+	if a[2] == 0 && b[2] == 0 {
+		return a[0] == b[0] && a[1]+1 == b[1]
+	}
+	return a[0] == b[0] && a[1] == b[1] && a[2] != 0 && a[2]+1 == b[2]
+}
+
+// SortPorts sorts port numbers by slot, port and sub-port.
+func SortPorts(ports []string) {
+	sort.Slice(ports, func(i, j int) bool { return portLess(ports[i], ports[j]) })
+}
+
+// portLess reports whether port a sorts before port b.
+func portLess(a, b string) bool {
+	na, nb := portNumbers(a), portNumbers(b)
+	for k := range na {
+		if na[k] != nb[k] {
+			return na[k] < nb[k]
+		}
+	}
+	return false
+}
+
+// ParsePorts expands a port list as printed by the device, such as
+// "1/1-1/4,2/1", into individual ports.
+func ParsePorts(list string) ([]string, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil, nil
+	}
+
+	var ports []string
+	for _, item := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		if !ValidPort(first) || !ValidPort(last) {
+			return nil, fmt.Errorf("invalid port list %q", list)
+		}
+
+		from, to := portNumbers(first), portNumbers(last)
+		if from[0] != to[0] || (from[2] == 0) != (to[2] == 0) ||
+			(from[2] != 0 && from[1] != to[1]) || portIndex(from) > portIndex(to) {
+			return nil, fmt.Errorf("invalid port range %q", item)
+		}
+		for cur := from; ; {
+			ports = append(ports, formatPort(cur))
+			if cur == to {
+				break
+			}
+			if cur[2] == 0 {
+				cur[1]++
+			} else {
+				cur[2]++
+			}
+		}
+	}
+	return ports, nil
+}
+
+// portIndex orders the ports of a range.
+func portIndex(n [3]int) int {
+	return n[1]*1000 + n[2]
+}
+
+func formatPort(n [3]int) string {
+	if n[2] == 0 {
+		return fmt.Sprintf("%d/%d", n[0], n[1])
+	}
+	return fmt.Sprintf("%d/%d/%d", n[0], n[1], n[2])
+}
+
+// FormatPorts returns ports as a sorted port list, collapsing consecutive
+// ports into ranges.
+func FormatPorts(ports []string) string {
+	ports = append([]string(nil), ports...)
+	SortPorts(ports)
+
+	var items []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && consecutive(portNumbers(ports[j]), portNumbers(ports[j+1])) {
+			j++
+		}
+		if i == j {
+			items = append(items, ports[i])
+		} else {
+			items = append(items, ports[i]+"-"+ports[j])
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{"", nil},
+		{"1/1", []string{"1/1"}},
+		{"1/1-1/3,2/5", []string{"1/1", "1/2", "1/3", "2/5"}},
+		{"1/49/1-1/49/3", []string{"1/49/1", "1/49/2", "1/49/3"}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePorts(tt.list)
+		if err != nil {
+			t.Errorf("%q: %v", tt.list, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.list, got, tt.want)
+		}
+	}
+
+	for _, list := range []string{"1", "1/1-2/1", "1/3-1/1", "1/1,", "1/49/1-1/50/1"} {
+		if _, err := ParsePorts(list); err == nil {
+			t.Errorf("%q: invalid list accepted", list)
+		}
+	}
+}
+
+func TestFormatPorts(t *testing.T) {
+	got := FormatPorts([]string{"2/1", "1/3", "1/10", "1/1", "1/2", "1/49/2", "1/49/1"})
+	if want := "1/1-1/3,1/10,1/49/1-1/49/2,2/1"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := FormatPorts(nil); got != "" {
+		t.Errorf("got %q for no ports", got)
+	}
+}
@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultRESTPort is the HTTPS port of the Fabric Engine REST API.
+const DefaultRESTPort = 443
+
+const (
+	restTokenPath  = "/auth/token/"
+	restTokenField = "X-Auth-Token"
+	restSystemPath = "/rest/restconf/data/openconfig-system:system/config"
+)
+
+// RESTConfig holds the parameters needed to reach the REST API of a device.
+type RESTConfig struct {
+	Host     string
+	Port     int32
+	Username string
+	Password string
+
+	// CABundle is a PEM encoded set of certificates used to verify the
+	// device. The system roots are used when it is empty.
+	CABundle []byte
+	// Insecure disables TLS certificate verification.
+	Insecure bool
+}
+
+// BaseURL returns the URL every API path is relative to.
+func (c RESTConfig) BaseURL() string {
+	port := c.Port
+	if port == 0 {
+		port = DefaultRESTPort
+	}
+	return "https://" + net.JoinHostPort(c.Host, strconv.Itoa(int(port)))
+}
+
+// HTTPError is returned when the REST API answers with a non-2xx status.
+type HTTPError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RESTClient talks to the REST API of a single device. It logs in lazily and
+// logs in again when its token expires. It is safe for concurrent use.
+type RESTClient struct {
+	config  RESTConfig
+	baseURL string
+	http    *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+var _ System = &RESTClient{}
+
+// NewRESTClient returns a client for the device described by config.
+func NewRESTClient(config RESTConfig) (*RESTClient, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.Insecure,
+	}
+	if len(config.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CABundle) {
+			return nil, errors.New("CA bundle does not contain any PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &RESTClient{
+		config:  config,
+		baseURL: config.BaseURL(),
+		http: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// Close releases the idle connections of c.
+func (c *RESTClient) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+// Get decodes the JSON document at path into v.
+func (c *RESTClient) Get(ctx context.Context, path string, v any) error {
+	return c.do(ctx, http.MethodGet, path, nil, v)
+}
+
+// Patch merges body into the resource at path.
+func (c *RESTClient) Patch(ctx context.Context, path string, body any) error {
+	return c.do(ctx, http.MethodPatch, path, body, nil)
+}
+
+// Delete removes the resource at path.
+func (c *RESTClient) Delete(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// Hostname reads the system name from the OpenConfig system model.
+func (c *RESTClient) Hostname(ctx context.Context) (string, error) {
+	var doc struct {
+		Config struct {
+			Hostname string `json:"hostname"`
+		} `json:"openconfig-system:config"`
+	}
+	if err := c.Get(ctx, restSystemPath, &doc); err != nil {
+		return "", err
+	}
+	if doc.Config.Hostname == "" {
+		return "", fmt.Errorf("no hostname in the response to GET %s", restSystemPath)
+	}
+	return doc.Config.Hostname, nil
+}
+
+// SetHostname changes the system name through the OpenConfig system model.
+func (c *RESTClient) SetHostname(ctx context.Context, name string) error {
+	body := map[string]any{
+		"openconfig-system:config": map[string]string{"hostname": name},
+	}
+	return c.Patch(ctx, restSystemPath, body)
+}
+
+// ResetHostname removes the configured system name so the device falls back
+// to its default.
+func (c *RESTClient) ResetHostname(ctx context.Context) error {
+	return c.Delete(ctx, restSystemPath+"/hostname")
+}
+
+// do sends an authenticated request, logging in again once if the token was
+// rejected.
+func (c *RESTClient) do(ctx context.Context, method, path string, body, v any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.login(ctx, attempt > 0)
+		if err != nil {
+			return err
+		}
+
+		err = c.send(ctx, method, path, token, payload, v)
+		var httpErr *HTTPError
+		if attempt == 0 && errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized {
+			continue
+		}
+		return err
+	}
+}
+
+// login returns the current token, requesting a new one if there is none or
+// if renew is set.
+func (c *RESTClient) login(ctx context.Context, renew bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && !renew {
+		return c.token, nil
+	}
+
+	creds, err := json.Marshal(map[string]string{
+		"username": c.config.Username,
+		"password": c.config.Password,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := c.send(ctx, http.MethodPost, restTokenPath, "", creds, &resp); err != nil {
+		return "", fmt.Errorf("logging in to %s: %w", c.baseURL, err)
+	}
+	if resp.Token == "" {
+		return "", fmt.Errorf("logging in to %s: no token in response", c.baseURL)
+	}
+	c.token = resp.Token
+	return c.token, nil
+}
+
+func (c *RESTClient) send(ctx context.Context, method, path, token string, payload []byte, v any) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set(restTokenField, token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &HTTPError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(data)),
+		}
+	}
+
+	if v == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding response to %s %s: %w", method, path, err)
+	}
+	return nil
+}
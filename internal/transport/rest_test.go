@@ -0,0 +1,190 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// restStandIn emulates the token login and the OpenConfig system model of
+// the Fabric Engine REST API.
+type restStandIn struct {
+	mu       sync.Mutex
+	hostname string
+	tokens   map[string]bool
+	logins   int
+}
+
+func (s *restStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == restTokenPath && r.Method == http.MethodPost {
+		var creds map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil || creds["username"] != "rwa" || creds["password"] != "rwa" {
+			http.Error(w, `{"error":"bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		s.logins++
+		token := "token-" + strconv.Itoa(s.logins)
+		s.tokens[token] = true
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+		return
+	}
+
+	if !s.tokens[r.Header.Get(restTokenField)] {
+		http.Error(w, `{"error":"invalid token"}`, http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path != restSystemPath {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"openconfig-system:config": map[string]string{"hostname": s.hostname},
+		})
+	case http.MethodPatch:
+		var doc struct {
+			Config struct {
+				Hostname string `json:"hostname"`
+			} `json:"openconfig-system:config"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil || doc.Config.Hostname == "" {
+			http.Error(w, `{"error":"invalid hostname"}`, http.StatusBadRequest)
+			return
+		}
+		s.hostname = doc.Config.Hostname
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newRESTStandIn(t *testing.T) (*restStandIn, *httptest.Server, RESTConfig) {
+	t.Helper()
+	standIn := &restStandIn{hostname: "LAB-VOSS01", tokens: map[string]bool{}}
+	srv := httptest.NewTLSServer(standIn)
+	t.Cleanup(srv.Close)
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	return standIn, srv, RESTConfig{Host: host, Port: int32(p), Username: "rwa", Password: "rwa", CABundle: ca}
+}
+
+func TestRESTClientHostname(t *testing.T) {
+	standIn, _, cfg := newRESTStandIn(t)
+	c, err := NewRESTClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	name, err := c.Hostname(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "LAB-VOSS01" {
+		t.Errorf("got hostname %q", name)
+	}
+
+	if err := c.SetHostname(ctx, "LAB-VOSS02"); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := c.Hostname(ctx); name != "LAB-VOSS02" {
+		t.Errorf("got hostname %q after update", name)
+	}
+	if standIn.logins != 1 {
+		t.Errorf("logged in %d times, want 1", standIn.logins)
+	}
+}
+
+func TestRESTClientRenewsToken(t *testing.T) {
+	standIn, _, cfg := newRESTStandIn(t)
+	c, err := NewRESTClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := c.Hostname(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	standIn.mu.Lock()
+	standIn.tokens = map[string]bool{}
+	standIn.mu.Unlock()
+
+	if _, err := c.Hostname(ctx); err != nil {
+		t.Fatalf("expired token not renewed: %v", err)
+	}
+	if standIn.logins != 2 {
+		t.Errorf("logged in %d times, want 2", standIn.logins)
+	}
+}
+
+func TestRESTClientErrors(t *testing.T) {
+	_, _, cfg := newRESTStandIn(t)
+	ctx := context.Background()
+
+	c, err := NewRESTClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var httpErr *HTTPError
+	if err := c.SetHostname(ctx, ""); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("got %v, want 400 *HTTPError", err)
+	}
+
+	bad := cfg
+	bad.Password = "wrong"
+	c, err = NewRESTClient(bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Hostname(ctx); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %v, want 401 *HTTPError", err)
+	}
+}
+
+func TestRESTClientTLS(t *testing.T) {
+	_, _, cfg := newRESTStandIn(t)
+	ctx := context.Background()
+
+	untrusted := cfg
+	untrusted.CABundle = nil
+	c, err := NewRESTClient(untrusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Hostname(ctx); err == nil {
+		t.Error("self-signed certificate accepted without CA bundle")
+	}
+
+	untrusted.Insecure = true
+	c, err = NewRESTClient(untrusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Hostname(ctx); err != nil {
+		t.Errorf("insecure client: %v", err)
+	}
+
+	bad := cfg
+	bad.CABundle = []byte("not a certificate")
+	if _, err := NewRESTClient(bad); err == nil {
+		t.Error("invalid CA bundle accepted")
+	}
+}
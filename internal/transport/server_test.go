@@ -0,0 +1,196 @@
+package transport
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/mockdevice"
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is a minimal SSH server printing a VOSS style prompt after every
+// line it receives. "drop" closes the connection, lines starting with "bad"
+// are rejected and "sys name" changes the name shown by "show sys-info". The
+// hostname is also exposed through the netconf subsystem.
+type testServer struct {
+	addr   string
+	key    ssh.PublicKey
+	dials  atomic.Int32
+	mu     sync.Mutex
+	conns  []net.Conn
+	listen net.Listener
+
+	hostname      string
+	netconf10Only bool
+	netconfOps    []string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "rwa" && string(pass) == "rwa" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("access denied")
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{addr: l.Addr().String(), key: signer.PublicKey(), listen: l, hostname: "LAB"}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, c)
+			s.mu.Unlock()
+			go s.serve(c, cfg)
+		}
+	}()
+	return s
+}
+
+// config returns a client configuration for s.
+func (s *testServer) config() Config {
+	host, port, _ := net.SplitHostPort(s.addr)
+	p, _ := strconv.Atoi(port)
+	return Config{Host: host, Port: int32(p), Username: "rwa", Password: "rwa"}
+}
+
+// dropAll closes every connection accepted so far.
+func (s *testServer) dropAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *testServer) serve(c net.Conn, cfg *ssh.ServerConfig) {
+	conn, chans, reqs, err := ssh.NewServerConn(c, cfg)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	s.dials.Add(1)
+	go ssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			_ = nc.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range reqs {
+				switch {
+				case req.Type == "pty-req":
+					_ = req.Reply(true, nil)
+				case req.Type == "shell":
+					_ = req.Reply(true, nil)
+					go s.shell(ch, conn)
+				case req.Type == "subsystem" && string(req.Payload[4:]) == "netconf":
+					_ = req.Reply(true, nil)
+					go s.netconf(ch)
+				default:
+					_ = req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+func (s *testServer) shell(ch ssh.Channel, conn *ssh.ServerConn) {
+	defer ch.Close()
+
+	prompt := "LAB:1>"
+	fmt.Fprintf(ch, "\r\nWelcome\r\n%s", prompt)
+
+	r := bufio.NewReader(ch)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+
+		var out string
+		switch {
+		case line == "drop":
+			conn.Close()
+			return
+		case line == "enable", line == "end":
+			prompt = "LAB:1#"
+		case line == "configure terminal":
+			prompt = "LAB:1(config)#"
+		case strings.HasPrefix(line, "bad"):
+			out = "% Invalid input detected at '^' marker.\r\n"
+		case strings.HasPrefix(line, "sys name "):
+			s.mu.Lock()
+			s.hostname = strings.TrimPrefix(line, "sys name ")
+			s.mu.Unlock()
+		case line == "default sys name":
+			s.mu.Lock()
+			s.hostname = "VSP-8284XSQ"
+			s.mu.Unlock()
+		case line == "show sys-info | include SysName":
+			s.mu.Lock()
+			out = "SysName          : " + s.hostname + "\r\n"
+			s.mu.Unlock()
+		case strings.HasPrefix(line, "echo "):
+			out = strings.TrimPrefix(line, "echo ") + "\r\n"
+		}
+		fmt.Fprintf(ch, "%s\r\n%s%s", line, out, prompt)
+	}
+}
+
+// newMockClient returns a client of an emulated Fabric Engine switch, for
+// the tests of the features the minimal test server does not implement.
+func newMockClient(t *testing.T) (*mockdevice.Server, *Client) {
+	t.Helper()
+
+	srv, err := mockdevice.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	c := NewClient(Config{
+		Host:     srv.Host(),
+		Port:     int32(srv.Port()),
+		Username: mockdevice.Username,
+		Password: mockdevice.Password,
+	})
+	t.Cleanup(func() { c.Close() })
+	return srv, c
+}
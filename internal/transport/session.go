@@ -0,0 +1,265 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// promptRe matches a VOSS CLI prompt at the end of the received output, e.g.
+// "VSP-8284XSQ:1>", "VSP-8284XSQ:1#" or "VSP-8284XSQ:1(config-if)#".
+var promptRe = regexp.MustCompile(`(?:^|\n)([\w.\-]+(?::\d+)?(?:\([\w.\-/ ]*\))?[>#]) ?$`)
+
+// ErrSessionClosed is returned when the device closes the shell while a
+// command is waiting for its prompt.
+var ErrSessionClosed = errors.New("session closed by device")
+
+// Session is an interactive CLI shell on a device. A session is not safe for
+// concurrent use.
+type Session struct {
+	conn    *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	out     *shellBuffer
+	timeout time.Duration
+	prompt  string
+	// sent counts the commands written to the device.
+	sent int
+}
+
+func newSession(ctx context.Context, conn *ssh.Client, timeout time.Duration) (*Session, error) {
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("creating SSH session: %w", err)
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          0,
+		ssh.TTY_OP_ISPEED: 38400,
+		ssh.TTY_OP_OSPEED: 38400,
+	}
+	if err := session.RequestPty("vt100", 0, 511, modes); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("requesting pty: %w", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("getting stdin pipe: %w", err)
+	}
+
+	out := newShellBuffer()
+	session.Stdout = out
+	session.Stderr = out
+
+	if err := session.Shell(); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("starting remote shell: %w", err)
+	}
+	go func() {
+		err := session.Wait()
+		var missing *ssh.ExitMissingError
+		if errors.As(err, &missing) {
+			// VOSS closes the channel without sending an exit status.
+			err = nil
+		}
+		out.close(err)
+	}()
+
+	s := &Session{
+		conn:    conn,
+		session: session,
+		stdin:   stdin,
+		out:     out,
+		timeout: timeout,
+	}
+	if _, err := s.waitPrompt(ctx); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("waiting for initial prompt: %w", err)
+	}
+	return s, nil
+}
+
+// init moves the session to privileged mode and disables paging.
+func (s *Session) init(ctx context.Context) error {
+	if strings.HasSuffix(s.prompt, ">") {
+		if _, err := s.Exec(ctx, "enable"); err != nil {
+			return err
+		}
+	}
+	_, err := s.Exec(ctx, "terminal more disable")
+	return err
+}
+
+// Prompt returns the last prompt received from the device.
+func (s *Session) Prompt() string {
+	return s.prompt
+}
+
+// Exec sends cmd and waits for the next prompt. It returns the text printed by
+// the device between the command and the prompt, or a *CommandError if the
+// device rejected the command.
+func (s *Session) Exec(ctx context.Context, cmd string) (string, error) {
+	if _, err := fmt.Fprintf(s.stdin, "%s\n", cmd); err != nil {
+		return "", fmt.Errorf("sending %q: %w", redact(cmd, cmd), err)
+	}
+	s.sent++
+
+	raw, err := s.waitPrompt(ctx)
+	if err != nil {
+		return raw, fmt.Errorf("waiting for %q: %w", redact(cmd, cmd), err)
+	}
+
+	output := cleanOutput(raw, cmd)
+	return output, checkOutput(cmd, output)
+}
+
+// Run executes cmds in order and returns the output of each one. It stops at
+// the first command that fails.
+func (s *Session) Run(ctx context.Context, cmds ...string) ([]string, error) {
+	outputs := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		out, err := s.Exec(ctx, cmd)
+		if err != nil {
+			return outputs, err
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+// Configure enters configuration mode, executes cmds, returns to privileged
+// mode and saves the configuration. The configuration is not saved if one of
+// the commands fails.
+func (s *Session) Configure(ctx context.Context, cmds ...string) error {
+	return s.configure(ctx, true, cmds...)
+}
+
+// configure enters configuration mode, executes cmds and returns to
+// privileged mode. The configuration is saved only if save is set and every
+// command succeeded.
+func (s *Session) configure(ctx context.Context, save bool, cmds ...string) error {
+	if _, err := s.Exec(ctx, "configure terminal"); err != nil {
+		return err
+	}
+	if _, err := s.Run(ctx, cmds...); err != nil {
+		// Leave configuration mode so the session stays usable.
+		_, _ = s.Exec(ctx, "end")
+		return err
+	}
+	if _, err := s.Exec(ctx, "end"); err != nil || !save {
+		return err
+	}
+	_, err := s.Exec(ctx, "save config")
+	return err
+}
+
+// Close terminates the shell and the underlying SSH connection.
+func (s *Session) Close() error {
+	s.session.Close()
+	return s.conn.Close()
+}
+
+// waitPrompt blocks until the received output ends with a prompt and returns
+// everything received up to and including it.
+func (s *Session) waitPrompt(ctx context.Context) (string, error) {
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	for {
+		data, closed, err := s.out.snapshot()
+		text := strings.ReplaceAll(string(data), "\r", "")
+		if m := promptRe.FindStringSubmatch(text); m != nil {
+			s.out.consume(len(data))
+			s.prompt = m[1]
+			return text, nil
+		}
+		if closed {
+			if err == nil {
+				err = ErrSessionClosed
+			}
+			return text, err
+		}
+
+		select {
+		case <-s.out.notify:
+		case <-ctx.Done():
+			return text, ctx.Err()
+		case <-timer.C:
+			return text, fmt.Errorf("timed out after %s waiting for prompt", s.timeout)
+		}
+	}
+}
+
+// cleanOutput strips the echoed command and the trailing prompt from raw.
+func cleanOutput(raw, cmd string) string {
+	if loc := promptRe.FindStringIndex(raw); loc != nil {
+		raw = raw[:loc[0]]
+	}
+
+	lines := strings.Split(raw, "\n")
+	if len(lines) > 0 && strings.HasSuffix(strings.TrimSpace(lines[0]), cmd) {
+		lines = lines[1:]
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// shellBuffer collects the output of a shell. Writes come from the SSH
+// library's copy goroutines while reads come from the session owner.
+type shellBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+	err    error
+	notify chan struct{}
+}
+
+func newShellBuffer() *shellBuffer {
+	return &shellBuffer{notify: make(chan struct{}, 1)}
+}
+
+func (b *shellBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	n, err := b.buf.Write(p)
+	b.mu.Unlock()
+	b.signal()
+	return n, err
+}
+
+func (b *shellBuffer) close(err error) {
+	b.mu.Lock()
+	b.closed = true
+	b.err = err
+	b.mu.Unlock()
+	b.signal()
+}
+
+func (b *shellBuffer) signal() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+// snapshot returns a copy of the unread output.
+func (b *shellBuffer) snapshot() ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes()), b.closed, b.err
+}
+
+// consume discards the first n bytes of unread output.
+func (b *shellBuffer) consume(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Next(n)
+}
@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromptRe(t *testing.T) {
+	cases := map[string]string{
+		"VSP-8284XSQ:1>":                 "VSP-8284XSQ:1>",
+		"VSP-8284XSQ:1#":                 "VSP-8284XSQ:1#",
+		"banner\nVSP-8284XSQ:1(config)#": "VSP-8284XSQ:1(config)#",
+		"out\nLAB-VOSS01:1(config-if)# ": "LAB-VOSS01:1(config-if)#",
+		"LAB.VOSS_01#":                   "LAB.VOSS_01#",
+	}
+	for in, want := range cases {
+		m := promptRe.FindStringSubmatch(in)
+		if m == nil {
+			t.Errorf("%q: no prompt found", in)
+			continue
+		}
+		if m[1] != want {
+			t.Errorf("%q: got prompt %q, want %q", in, m[1], want)
+		}
+	}
+
+	for _, in := range []string{
+		"",
+		"SysName : LAB-VOSS01",
+		"VSP-8284XSQ:1# show sys-info\n",
+	} {
+		if promptRe.MatchString(in) {
+			t.Errorf("%q: unexpected prompt match", in)
+		}
+	}
+}
+
+func TestCleanOutput(t *testing.T) {
+	raw := "show sys-info | include SysName\n" +
+		"SysName          : LAB-VOSS01\n" +
+		"\n" +
+		"LAB-VOSS01:1#"
+
+	got := cleanOutput(raw, "show sys-info | include SysName")
+	want := "SysName          : LAB-VOSS01"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got := cleanOutput("LAB-VOSS01:1(config)#", "configure terminal"); got != "" {
+		t.Errorf("got %q, want empty output", got)
+	}
+}
+
+func TestWaitPrompt(t *testing.T) {
+	out := newShellBuffer()
+	s := &Session{out: out, timeout: time.Second}
+
+	go func() {
+		_, _ = out.Write([]byte("SysName : LAB\r\n"))
+		_, _ = out.Write([]byte("LAB:1#"))
+	}()
+
+	raw, err := s.waitPrompt(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw != "SysName : LAB\nLAB:1#" {
+		t.Errorf("unexpected output %q", raw)
+	}
+	if s.Prompt() != "LAB:1#" {
+		t.Errorf("unexpected prompt %q", s.Prompt())
+	}
+}
+
+func TestWaitPromptClosed(t *testing.T) {
+	out := newShellBuffer()
+	s := &Session{out: out, timeout: time.Second}
+
+	_, _ = out.Write([]byte("logout\n"))
+	out.close(nil)
+
+	if _, err := s.waitPrompt(context.Background()); !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("got %v, want ErrSessionClosed", err)
+	}
+}
+
+func TestWaitPromptContext(t *testing.T) {
+	s := &Session{out: newShellBuffer(), timeout: time.Minute}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.waitPrompt(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestExecRedactsSecrets(t *testing.T) {
+	out := newShellBuffer()
+	s := &Session{out: out, stdin: &scriptedWriter{out: out, replies: []string{""}}, timeout: time.Minute}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.Exec(ctx, "isis hello-auth type simple key s3cr3t-key")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if strings.Contains(err.Error(), "s3cr3t-key") {
+		t.Errorf("key leaked in %q", err)
+	}
+}
@@ -0,0 +1,202 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SPBM is the global IS-IS and SPBM configuration of the device.
+type SPBM struct {
+	// SystemID is the IS-IS system ID, e.g. "0200.0000.0001". An empty
+	// SystemID restores the default derived from the base MAC address.
+	SystemID string
+	// ManualArea is the IS-IS area, e.g. "49.0001".
+	ManualArea string
+	// NickName is the SPBM nick-name of the device, e.g. "0.00.01".
+	NickName string
+	// BVLANs are the B-VIDs of SPBM instance 1, the primary one first.
+	BVLANs []int32
+	// Enabled reports whether IS-IS is enabled with "router isis enable".
+	Enabled bool
+}
+
+// instance reports whether s needs SPBM instance 1.
+func (s SPBM) instance() bool {
+	return s.NickName != "" || len(s.BVLANs) > 0
+}
+
+var (
+	isisAdminStateRe = regexp.MustCompile(`(?m)^\s*AdminState\s*:\s*(\S+)`)
+	isisSystemIDRe   = regexp.MustCompile(`(?m)^\s*System ID\s*:\s*(\S+)`)
+	// isisManualAreaRe matches the rows of "show isis manual-area".
+	isisManualAreaRe = regexp.MustCompile(`(?m)^([0-9a-fA-F]{2}(?:\.[0-9a-fA-F]{4})*)[ \t]*$`)
+	// isisSPBMRe matches the row of SPBM instance 1 in "show isis spbm".
+	isisSPBMRe = regexp.MustCompile(`(?m)^1[ \t]+(\S+)[ \t]+(\S+)[ \t]+(\S+)`)
+)
+
+// SPBM reads the global IS-IS and SPBM configuration from "show isis",
+// "show isis manual-area" and "show isis spbm".
+func (c *Client) SPBM(ctx context.Context) (*SPBM, error) {
+	outputs, err := c.Run(ctx, "show isis", "show isis manual-area", "show isis spbm")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SPBM{}
+	if m := isisAdminStateRe.FindStringSubmatch(outputs[0]); m != nil {
+		s.Enabled = m[1] == "enabled"
+	}
+	m := isisSystemIDRe.FindStringSubmatch(outputs[0])
+	if m == nil {
+		return nil, fmt.Errorf("could not find the system ID in the output of \"show isis\":\n%s", outputs[0])
+	}
+	s.SystemID = m[1]
+
+	if m := isisManualAreaRe.FindStringSubmatch(outputs[1]); m != nil {
+		s.ManualArea = m[1]
+	}
+
+	if m := isisSPBMRe.FindStringSubmatch(outputs[2]); m != nil {
+		if m[3] != "--" {
+			s.NickName = m[3]
+		}
+		if m[1] != "--" {
+			bvids, err := parseVLANList(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("parsing the B-VIDs of SPBM instance 1: %w", err)
+			}
+			primary, _ := strconv.Atoi(m[2])
+			s.BVLANs = []int32{int32(primary)}
+			for _, id := range bvids {
+				if id != int32(primary) {
+					s.BVLANs = append(s.BVLANs, id)
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+// ApplySPBM changes the global IS-IS and SPBM configuration from old to s.
+// The system ID, manual area, nick-name and B-VIDs can only be changed while
+// IS-IS is disabled, so IS-IS is disabled first and enabled again once they
+// are set. If one of the changes is rejected, IS-IS is enabled again so that
+// a failed apply does not leave the device out of the fabric.
+func (c *Client) ApplySPBM(ctx context.Context, old, s SPBM) error {
+	var isis []string
+	if s.SystemID != old.SystemID {
+		if s.SystemID == "" {
+			isis = append(isis, "default system-id")
+		} else {
+			isis = append(isis, "system-id "+s.SystemID)
+		}
+	}
+	if s.ManualArea != old.ManualArea {
+		if old.ManualArea != "" {
+			isis = append(isis, "no manual-area "+old.ManualArea)
+		}
+		if s.ManualArea != "" {
+			isis = append(isis, "manual-area "+s.ManualArea)
+		}
+	}
+
+	var pre []string
+	switch {
+	case s.instance() && !old.instance():
+		pre = append(pre, "spbm")
+		isis = append(isis, "spbm 1")
+	case !s.instance() && old.instance():
+		isis = append(isis, "no spbm 1")
+	}
+	if s.instance() {
+		if s.NickName != old.NickName {
+			if s.NickName == "" {
+				isis = append(isis, "no spbm 1 nick-name")
+			} else {
+				isis = append(isis, "spbm 1 nick-name "+s.NickName)
+			}
+		}
+		if !equalVLANs(s.BVLANs, old.BVLANs) {
+			if len(old.BVLANs) > 0 && old.instance() {
+				isis = append(isis, "no spbm 1 b-vid "+formatVLANList(old.BVLANs))
+			}
+			if len(s.BVLANs) > 0 {
+				isis = append(isis, fmt.Sprintf("spbm 1 b-vid %s primary %d", formatVLANList(s.BVLANs), s.BVLANs[0]))
+			}
+		}
+	}
+
+	var cmds []string
+	disabled := !old.Enabled
+	restore := len(isis) > 0 && old.Enabled
+	if restore {
+		cmds = append(cmds, "no router isis enable")
+		disabled = true
+	}
+	cmds = append(cmds, pre...)
+	if len(isis) > 0 {
+		cmds = append(cmds, "router isis")
+		cmds = append(cmds, isis...)
+		cmds = append(cmds, "exit")
+	}
+	switch {
+	case s.Enabled && disabled:
+		cmds = append(cmds, "router isis enable")
+	case !s.Enabled && !disabled:
+		cmds = append(cmds, "no router isis enable")
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	err := c.Configure(ctx, cmds...)
+	if err != nil && restore {
+		// Best effort: the original error is the one worth reporting. The
+		// configuration is left unsaved, as after any failed change.
+		_ = c.configureUnsaved(ctx, "router isis enable")
+	}
+	return err
+}
+
+// parseVLANList parses a list of VLAN IDs such as "4051-4052,4060".
+func parseVLANList(list string) ([]int32, error) {
+	var ids []int32
+	for _, item := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		from, err1 := strconv.Atoi(first)
+		to, err2 := strconv.Atoi(last)
+		if err1 != nil || err2 != nil || from > to {
+			return nil, fmt.Errorf("invalid VLAN list %q", list)
+		}
+		for id := from; id <= to; id++ {
+			ids = append(ids, int32(id))
+		}
+	}
+	return ids, nil
+}
+
+// formatVLANList returns ids as a comma separated list.
+func formatVLANList(ids []int32) string {
+	items := make([]string, len(ids))
+	for i, id := range ids {
+		items[i] = strconv.Itoa(int(id))
+	}
+	return strings.Join(items, ",")
+}
+
+func equalVLANs(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
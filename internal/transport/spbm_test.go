@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/mockdevice"
+)
+
+func TestSPBM(t *testing.T) {
+	_, c := newMockClient(t)
+	ctx := context.Background()
+
+	initial, err := c.SPBM(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SPBM{SystemID: mockdevice.DefaultSystemID}); !reflect.DeepEqual(*initial, want) {
+		t.Errorf("got %+v, want %+v", *initial, want)
+	}
+
+	for _, id := range []int32{4051, 4052} {
+		if err := c.CreateVLAN(ctx, VLAN{ID: id, Type: VLANTypeBVLAN}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := SPBM{
+		SystemID:   "0200.0000.0001",
+		ManualArea: "49.0001",
+		NickName:   "0.00.01",
+		BVLANs:     []int32{4052, 4051},
+		Enabled:    true,
+	}
+	if err := c.ApplySPBM(ctx, *initial, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.SPBM(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	// Changing the system ID requires IS-IS to be disabled first.
+	updated := want
+	updated.SystemID = "0200.0000.0002"
+	updated.NickName = "0.00.02"
+	if err := c.ApplySPBM(ctx, *got, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.SPBM(ctx); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+
+	if err := c.ApplySPBM(ctx, updated, SPBM{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.SPBM(ctx); !reflect.DeepEqual(*got, *initial) {
+		t.Errorf("got %+v after reset, want %+v", *got, *initial)
+	}
+}
+
+func TestApplySPBMOrder(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	old := SPBM{SystemID: "0200.0000.0001", ManualArea: "49.0001", Enabled: true}
+	if err := c.ApplySPBM(ctx, SPBM{SystemID: mockdevice.DefaultSystemID}, old); err != nil {
+		t.Fatal(err)
+	}
+
+	before := len(srv.Commands())
+	s := old
+	s.SystemID = "0200.0000.0009"
+	if err := c.ApplySPBM(ctx, old, s); err != nil {
+		t.Fatal(err)
+	}
+	got := srv.Commands()[before:]
+	want := []string{
+		"configure terminal", "no router isis enable", "router isis", "system-id 0200.0000.0009", "exit",
+		"router isis enable", "end", "save config",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got commands %q, want %q", got, want)
+	}
+}
+
+func TestApplySPBMRestoresISIS(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	old := SPBM{SystemID: "0200.0000.0001", ManualArea: "49.0001", NickName: "0.00.01", Enabled: true}
+	if err := c.ApplySPBM(ctx, SPBM{SystemID: mockdevice.DefaultSystemID}, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// The system ID is changed before the nick-name is rejected.
+	startup := srv.StartupConfig()
+	srv.Reject("spbm 1 nick-name 0.00.09", "Error: Nick-name already in use")
+	s := old
+	s.SystemID, s.NickName = "0200.0000.0009", "0.00.09"
+	if err := c.ApplySPBM(ctx, old, s); err == nil {
+		t.Fatal("apply succeeded with a rejected nick-name")
+	}
+	got, err := c.SPBM(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Enabled {
+		t.Error("IS-IS left disabled after a failed apply")
+	}
+	if cfg := srv.StartupConfig(); cfg != startup {
+		t.Errorf("failed apply saved the configuration:\n%s", cfg)
+	}
+}
@@ -0,0 +1,209 @@
+// Package transport provides the connections used by the provider to talk to
+// Fabric Engine devices.
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultTimeout is used for dialing and for waiting on a prompt when the
+// caller does not set one.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMaxSessions is the number of shells a client keeps open on a device
+// when the caller does not set a limit.
+const DefaultMaxSessions = 2
+
+// Config holds the parameters needed to reach a device over SSH.
+type Config struct {
+	Host     string
+	Port     int32
+	Username string
+	Password string
+	Timeout  time.Duration
+
+	// Signers are used for public key authentication.
+	Signers []ssh.Signer
+	// UseAgent adds the keys held by the agent listening on SSH_AUTH_SOCK.
+	UseAgent bool
+	// KeyboardInteractive answers keyboard-interactive prompts with Password
+	// for devices that do not accept plain password authentication.
+	KeyboardInteractive bool
+
+	// MaxSessions limits the number of shells opened on the device. It
+	// defaults to DefaultMaxSessions.
+	MaxSessions int
+
+	// HostKeyCallback verifies the key presented by the device. Host key
+	// verification is disabled when it is nil.
+	HostKeyCallback ssh.HostKeyCallback
+}
+
+// Address returns the host:port string used to dial the device.
+func (c Config) Address() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(int(c.Port)))
+}
+
+func (c Config) hostKeyCallback() ssh.HostKeyCallback {
+	if c.HostKeyCallback == nil {
+		return ssh.InsecureIgnoreHostKey()
+	}
+	return c.HostKeyCallback
+}
+
+func (c Config) maxSessions() int {
+	if c.MaxSessions <= 0 {
+		return DefaultMaxSessions
+	}
+	return c.MaxSessions
+}
+
+func (c Config) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+// Client runs CLI commands against a single device. It keeps up to
+// Config.MaxSessions shells open and shares them between callers, so it is
+// safe for concurrent use.
+type Client struct {
+	config Config
+	pool   *sessionPool
+}
+
+// NewClient returns a client for the device described by config. No
+// connection is made until the first command is run.
+func NewClient(config Config) *Client {
+	return &Client{
+		config: config,
+		pool:   newSessionPool(config.maxSessions()),
+	}
+}
+
+// Close closes the idle sessions of c. Sessions in use are closed when they
+// are released.
+func (c *Client) Close() error {
+	return c.pool.close()
+}
+
+// Config returns the configuration the client was created with.
+func (c *Client) Config() Config {
+	return c.config
+}
+
+// Open dials the device, starts an interactive shell and waits for the first
+// prompt. The returned session is in privileged mode with paging disabled.
+func (c *Client) Open(ctx context.Context) (*Session, error) {
+	auth, release, err := c.config.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ssh.ClientConfig{
+		User:            c.config.Username,
+		Auth:            auth,
+		HostKeyCallback: c.config.hostKeyCallback(),
+		Timeout:         c.config.timeout(),
+	}
+
+	conn, err := dial(ctx, c.config.Address(), cfg)
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", c.config.Address(), err)
+	}
+
+	s, err := newSession(ctx, conn, c.config.timeout())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := s.init(ctx); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run executes cmds in privileged mode on a shared session and returns the
+// output of each command in order.
+func (c *Client) Run(ctx context.Context, cmds ...string) ([]string, error) {
+	var outputs []string
+	err := c.do(ctx, true, func(s *Session) error {
+		var err error
+		outputs, err = s.Run(ctx, cmds...)
+		return err
+	})
+	return outputs, err
+}
+
+// Configure executes cmds in configuration mode on a shared session and saves
+// the configuration.
+func (c *Client) Configure(ctx context.Context, cmds ...string) error {
+	return c.do(ctx, false, func(s *Session) error {
+		return s.Configure(ctx, cmds...)
+	})
+}
+
+// configureUnsaved executes cmds in configuration mode on a shared session
+// without saving the configuration, for corrections made after a failed
+// Configure that must not persist the half-applied change.
+func (c *Client) configureUnsaved(ctx context.Context, cmds ...string) error {
+	return c.do(ctx, false, func(s *Session) error {
+		return s.configure(ctx, false, cmds...)
+	})
+}
+
+// do runs fn on a pooled session. A session that fails for any reason other
+// than a rejected command is discarded; if it had been reused, fn is retried
+// once on a fresh connection since the device may have dropped it while idle.
+// Unless fn is read-only, it is only retried if none of its commands reached
+// the device, so that a configuration change is never sent twice.
+func (c *Client) do(ctx context.Context, readOnly bool, fn func(*Session) error) error {
+	for {
+		s, reused, err := c.pool.acquire(ctx, c.Open)
+		if err != nil {
+			return err
+		}
+
+		sent := s.sent
+		err = fn(s)
+		var cmdErr *CommandError
+		broken := err != nil && !errors.As(err, &cmdErr)
+		c.pool.release(s, broken)
+
+		if !broken || !reused || ctx.Err() != nil || (!readOnly && s.sent > sent) {
+			return err
+		}
+	}
+}
+
+// dial establishes the SSH connection, honouring ctx cancellation while the
+// TCP connection and handshake are in progress.
+func dial(ctx context.Context, address string, cfg *ssh.ClientConfig) (*ssh.Client, error) {
+	d := net.Dialer{Timeout: cfg.Timeout}
+	tcp, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = tcp.SetDeadline(deadline)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(tcp, address, cfg)
+	if err != nil {
+		tcp.Close()
+		return nil, err
+	}
+	_ = tcp.SetDeadline(time.Time{})
+
+	return ssh.NewClient(c, chans, reqs), nil
+}
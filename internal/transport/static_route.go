@@ -0,0 +1,185 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// StaticRoute is the set of static routes ("ip route") to a destination in a
+// VRF, one per next hop.
+type StaticRoute struct {
+	// VRF is the VRF of the route, empty for the global router.
+	VRF string
+	// Destination is the destination network with its prefix length, e.g.
+	// "10.0.0.0/24".
+	Destination string
+	NextHops    []NextHop
+}
+
+// NextHop is a next hop of a static route.
+type NextHop struct {
+	Address string
+	// Weight is the cost of the route through the next hop.
+	Weight     int32
+	Preference int32
+	Enabled    bool
+	// LocalNextHop reports whether the next hop must be on a directly
+	// connected network for the route to be active.
+	LocalNextHop bool
+}
+
+// DefaultRoutePreference is the preference of a static route created
+// without one.
+const DefaultRoutePreference = 5
+
+// staticRouteRe matches a row of "show ip route static".
+var staticRouteRe = regexp.MustCompile(`(?m)^(\d+\.\d+\.\d+\.\d+)[ \t]+(\d+\.\d+\.\d+\.\d+)[ \t]+(\d+\.\d+\.\d+\.\d+)[ \t]+(\d+)[ \t]+(\d+)[ \t]+(TRUE|FALSE)[ \t]+\S+[ \t]+(TRUE|FALSE)[ \t]*$`)
+
+// StaticRoute reads the next hops of the static routes to destination in the
+// VRF, empty for the global router, from "show ip route static". It returns
+// nil if there is none, or the VRF does not exist.
+func (c *Client) StaticRoute(ctx context.Context, vrf, destination string) (*StaticRoute, error) {
+	cmd := "show ip route static"
+	if vrf != "" {
+		cmd += " vrf " + vrf
+	}
+	out, err := c.Run(ctx, cmd)
+	if vrfNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &StaticRoute{VRF: vrf, Destination: destination}
+	for _, m := range staticRouteRe.FindAllStringSubmatch(out[0], -1) {
+		dest, err := addressPrefix(m[1], m[2])
+		if err != nil {
+			return nil, fmt.Errorf("parsing the static routes: %w", err)
+		}
+		if dest != destination {
+			continue
+		}
+		weight, _ := strconv.Atoi(m[4])
+		pref, _ := strconv.Atoi(m[5])
+		r.NextHops = append(r.NextHops, NextHop{
+			Address:      m[3],
+			Weight:       int32(weight),
+			Preference:   int32(pref),
+			LocalNextHop: m[6] == "TRUE",
+			Enabled:      m[7] == "TRUE",
+		})
+	}
+	if len(r.NextHops) == 0 {
+		return nil, nil
+	}
+	sortNextHops(r.NextHops)
+	return r, nil
+}
+
+// ApplyStaticRoute changes the next hops of the route from old to r. A nil
+// old creates every next hop of r.
+func (c *Client) ApplyStaticRoute(ctx context.Context, old *StaticRoute, r StaticRoute) error {
+	if old == nil {
+		old = &StaticRoute{VRF: r.VRF, Destination: r.Destination}
+	}
+	cmds, err := staticRouteCommands(*old, r)
+	if err != nil || len(cmds) == 0 {
+		return err
+	}
+	return c.Configure(ctx, routeContext(r.VRF, cmds)...)
+}
+
+// DeleteStaticRoute deletes every next hop of the route.
+func (c *Client) DeleteStaticRoute(ctx context.Context, r StaticRoute) error {
+	cmds, err := staticRouteCommands(r, StaticRoute{VRF: r.VRF, Destination: r.Destination})
+	if err != nil || len(cmds) == 0 {
+		return err
+	}
+	return c.Configure(ctx, routeContext(r.VRF, cmds)...)
+}
+
+// routeContext wraps cmds in the "router vrf" context of the VRF, if any.
+func routeContext(vrf string, cmds []string) []string {
+	if vrf == "" {
+		return cmds
+	}
+	return append(append([]string{"router vrf " + vrf}, cmds...), "exit")
+}
+
+// staticRouteCommands returns the commands changing the next hops of the
+// route from old to r. The removed next hops are deleted first so that the
+// route never has more next hops than either configuration.
+func staticRouteCommands(old, r StaticRoute) ([]string, error) {
+	prefix, err := netip.ParsePrefix(r.Destination)
+	if err != nil || !prefix.Addr().Is4() {
+		return nil, fmt.Errorf("invalid destination %q", r.Destination)
+	}
+	dest := fmt.Sprintf("%s %s", prefix.Addr(), maskOf(prefix.Bits()))
+
+	current := map[string]NextHop{}
+	for _, nh := range old.NextHops {
+		current[nh.Address] = nh
+	}
+	wanted := map[string]bool{}
+	for _, nh := range r.NextHops {
+		wanted[nh.Address] = true
+	}
+
+	var cmds []string
+	for _, nh := range old.NextHops {
+		if !wanted[nh.Address] {
+			cmds = append(cmds, fmt.Sprintf("no ip route %s %s", dest, nh.Address))
+		}
+	}
+	for _, nh := range r.NextHops {
+		route := dest + " " + nh.Address
+		cur, ok := current[nh.Address]
+		if !ok {
+			cur = NextHop{Address: nh.Address, Preference: DefaultRoutePreference, Enabled: true, LocalNextHop: true}
+		}
+		if !ok || cur.Weight != nh.Weight {
+			cmds = append(cmds, fmt.Sprintf("ip route %s weight %d", route, nh.Weight))
+		}
+		if cur.Preference != nh.Preference {
+			cmds = append(cmds, fmt.Sprintf("ip route %s preference %d", route, nh.Preference))
+		}
+		if cur.Enabled != nh.Enabled {
+			cmds = append(cmds, negate(!nh.Enabled, "ip route "+route+" enable"))
+		}
+		if cur.LocalNextHop != nh.LocalNextHop {
+			cmds = append(cmds, negate(!nh.LocalNextHop, "ip route "+route+" local-next-hop enable"))
+		}
+	}
+	return cmds, nil
+}
+
+// negate prefixes cmd with "no" if no is set.
+func negate(no bool, cmd string) string {
+	if no {
+		return "no " + cmd
+	}
+	return cmd
+}
+
+// maskOf returns the dotted network mask of an IPv4 prefix length.
+func maskOf(bits int) string {
+	mask := uint32(0xffffffff) << (32 - bits)
+	if bits == 0 {
+		mask = 0
+	}
+	return netip.AddrFrom4([4]byte{byte(mask >> 24), byte(mask >> 16), byte(mask >> 8), byte(mask)}).String()
+}
+
+// sortNextHops sorts next hops by address.
+func sortNextHops(nextHops []NextHop) {
+	sort.Slice(nextHops, func(i, j int) bool {
+		a, _ := netip.ParseAddr(nextHops[i].Address)
+		b, _ := netip.ParseAddr(nextHops[j].Address)
+		return a.Less(b)
+	})
+}
@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStaticRoute(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	if r, err := c.StaticRoute(ctx, "", "10.1.0.0/16"); err != nil || r != nil {
+		t.Fatalf("got %v, %v for a missing route", r, err)
+	}
+
+	want := StaticRoute{Destination: "10.1.0.0/16", NextHops: []NextHop{
+		{Address: "192.168.1.1", Weight: 1, Preference: DefaultRoutePreference, Enabled: true, LocalNextHop: true},
+		{Address: "192.168.2.1", Weight: 10, Preference: 20, Enabled: false, LocalNextHop: false},
+	}}
+	if err := c.ApplyStaticRoute(ctx, nil, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.StaticRoute(ctx, "", "10.1.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+	if cfg := srv.RunningConfig(); !strings.Contains(cfg, "ip route 10.1.0.0 255.255.0.0 192.168.2.1 preference 20\n") {
+		t.Errorf("preference not configured:\n%s", cfg)
+	}
+
+	// Only the next hops that changed are configured.
+	updated := StaticRoute{Destination: "10.1.0.0/16", NextHops: []NextHop{
+		{Address: "192.168.2.1", Weight: 10, Preference: 20, Enabled: true, LocalNextHop: false},
+		{Address: "192.168.3.1", Weight: 1, Preference: DefaultRoutePreference, Enabled: true, LocalNextHop: true},
+	}}
+	sent := len(srv.Commands())
+	if err := c.ApplyStaticRoute(ctx, &want, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.StaticRoute(ctx, "", "10.1.0.0/16"); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+	for _, cmd := range srv.Commands()[sent:] {
+		if strings.Contains(cmd, "weight 10") {
+			t.Errorf("unchanged weight configured again: %q", cmd)
+		}
+	}
+
+	if err := c.DeleteStaticRoute(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := c.StaticRoute(ctx, "", "10.1.0.0/16"); err != nil || r != nil {
+		t.Errorf("got %v, %v after deleting the route", r, err)
+	}
+}
+
+func TestStaticRouteVRF(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	if r, err := c.StaticRoute(ctx, "red", "0.0.0.0/0"); err != nil || r != nil {
+		t.Fatalf("got %v, %v for a missing VRF", r, err)
+	}
+	srv.Exec("configure terminal", "ip vrf red vrfid 1", "end")
+
+	want := StaticRoute{VRF: "red", Destination: "0.0.0.0/0", NextHops: []NextHop{
+		{Address: "10.0.0.1", Weight: 1, Preference: DefaultRoutePreference, Enabled: true, LocalNextHop: true},
+	}}
+	if err := c.ApplyStaticRoute(ctx, nil, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.StaticRoute(ctx, "red", "0.0.0.0/0"); got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if r, _ := c.StaticRoute(ctx, "", "0.0.0.0/0"); r != nil {
+		t.Errorf("VRF route read from the global router: %+v", r)
+	}
+	if cfg := srv.RunningConfig(); !strings.Contains(cfg, "router vrf red\nip route 0.0.0.0 0.0.0.0 10.0.0.1 weight 1\nexit\n") {
+		t.Errorf("route not configured in the VRF:\n%s", cfg)
+	}
+}
+
+func TestStaticRouteRejected(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	// Only a missing VRF means the route does not exist.
+	srv.Reject("show ip route static", "% Permission denied")
+	if r, err := c.StaticRoute(ctx, "", "0.0.0.0/0"); err == nil {
+		t.Errorf("got %v for a rejected command, want an error", r)
+	}
+}
@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// System is implemented by every transport. It covers the device settings
+// that have a structured representation on all of them, so resources using
+// it do not depend on how the device is reached.
+type System interface {
+	// Hostname returns the system name of the device.
+	Hostname(ctx context.Context) (string, error)
+	// SetHostname changes the system name of the device and persists it.
+	SetHostname(ctx context.Context, name string) error
+	// ResetHostname restores the factory default system name.
+	ResetHostname(ctx context.Context) error
+}
+
+var _ System = &Client{}
+
+var sysNameRe = regexp.MustCompile(`SysName\s+:\s+(\S+)`)
+
+// Hostname reads the system name from "show sys-info".
+func (c *Client) Hostname(ctx context.Context) (string, error) {
+	const cmd = "show sys-info | include SysName"
+	outputs, err := c.Run(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	m := sysNameRe.FindStringSubmatch(outputs[0])
+	if m == nil {
+		return "", fmt.Errorf("could not find SysName in the output of %q:\n%s", cmd, outputs[0])
+	}
+	return m[1], nil
+}
+
+// SetHostname sets the system name with "sys name".
+func (c *Client) SetHostname(ctx context.Context, name string) error {
+	return c.Configure(ctx, fmt.Sprintf("sys name %s", name))
+}
+
+// ResetHostname restores the default system name with "default sys name".
+func (c *Client) ResetHostname(ctx context.Context) error {
+	return c.Configure(ctx, "default sys name")
+}
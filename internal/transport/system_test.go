@@ -0,0 +1,35 @@
+package transport
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClientHostname(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient(srv.config())
+	defer c.Close()
+
+	ctx := context.Background()
+	name, err := c.Hostname(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "LAB" {
+		t.Errorf("got hostname %q, want %q", name, "LAB")
+	}
+
+	if err := c.SetHostname(ctx, "LAB-VOSS02"); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := c.Hostname(ctx); name != "LAB-VOSS02" {
+		t.Errorf("got hostname %q after update", name)
+	}
+
+	if err := c.ResetHostname(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := c.Hostname(ctx); name != "VSP-8284XSQ" {
+		t.Errorf("got hostname %q after reset", name)
+	}
+}
@@ -0,0 +1,148 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// VIST is the virtual IST of an SMLT cluster and the SPBM settings shared by
+// the two switches of the cluster.
+type VIST struct {
+	// PeerIP is the address of the peer switch on the vIST VLAN.
+	PeerIP string
+	// VLAN is the VLAN carrying the vIST.
+	VLAN int32
+	// PeerSystemID is the IS-IS system ID of the peer switch, set with
+	// "spbm 1 smlt-peer-system-id".
+	PeerSystemID string
+	// VirtualBMAC is the virtual B-MAC shared by the cluster, set with
+	// "spbm 1 smlt-virt-bmac".
+	VirtualBMAC string
+	// SMLTs are the IDs of the MLTs configured with "smlt", in ascending
+	// order.
+	SMLTs []int32
+}
+
+var (
+	// vistRe matches the row of "show virtual-ist".
+	vistRe = regexp.MustCompile(`(?m)^(\d+\.\d+\.\d+\.\d+)[ \t]+(\d+)[ \t]+`)
+	// isisSMLTRe matches the row of SPBM instance 1 in the SMLT table of
+	// "show isis spbm".
+	isisSMLTRe = regexp.MustCompile(`(?m)^1[ \t]+(primary|secondary|--)[ \t]+(\S+)[ \t]+(\S+)[ \t]*$`)
+	// smltRe matches the rows of "show mlt" whose admin type is smlt.
+	smltRe = regexp.MustCompile(`(?m)^(\d+)[ \t]+\d+[ \t]+.*?[ \t]+(?:access|trunk)[ \t]+smlt[ \t]+`)
+)
+
+// VIST reads the virtual IST from "show virtual-ist", "show isis spbm" and
+// "show mlt". It returns nil if no virtual IST is configured.
+func (c *Client) VIST(ctx context.Context) (*VIST, error) {
+	outputs, err := c.Run(ctx, "show virtual-ist", "show isis spbm", "show mlt")
+	if err != nil {
+		return nil, err
+	}
+
+	m := vistRe.FindStringSubmatch(outputs[0])
+	if m == nil {
+		return nil, nil
+	}
+	vlan, _ := strconv.Atoi(m[2])
+	v := &VIST{PeerIP: m[1], VLAN: int32(vlan)}
+
+	if m := isisSMLTRe.FindStringSubmatch(outputs[1]); m != nil {
+		if m[2] != "--" {
+			v.VirtualBMAC = m[2]
+		}
+		if m[3] != "--" {
+			v.PeerSystemID = m[3]
+		}
+	}
+	for _, row := range smltRe.FindAllStringSubmatch(outputs[2], -1) {
+		id, _ := strconv.Atoi(row[1])
+		v.SMLTs = append(v.SMLTs, int32(id))
+	}
+	sort.Slice(v.SMLTs, func(i, j int) bool { return v.SMLTs[i] < v.SMLTs[j] })
+	return v, nil
+}
+
+// ApplyVIST changes the virtual IST from old to v, or creates it if old is
+// nil. The peer IP and VLAN cannot be changed in place.
+func (c *Client) ApplyVIST(ctx context.Context, old *VIST, v VIST) error {
+	var cmds []string
+	if old == nil {
+		old = &VIST{}
+		cmds = append(cmds, fmt.Sprintf("virtual-ist peer-ip %s vlan %d", v.PeerIP, v.VLAN))
+	}
+
+	var isis []string
+	if v.PeerSystemID != old.PeerSystemID {
+		isis = append(isis, smltSPBMCommand("smlt-peer-system-id", v.PeerSystemID))
+	}
+	if v.VirtualBMAC != old.VirtualBMAC {
+		isis = append(isis, smltSPBMCommand("smlt-virt-bmac", v.VirtualBMAC))
+	}
+	if len(isis) > 0 {
+		cmds = append(append(append(cmds, "router isis"), isis...), "exit")
+	}
+
+	cmds = append(cmds, smltCommands(old.SMLTs, v.SMLTs)...)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// DeleteVIST removes "smlt" from the MLTs of the cluster, then the virtual
+// IST and the SMLT settings of SPBM instance 1.
+func (c *Client) DeleteVIST(ctx context.Context, v VIST) error {
+	cmds := smltCommands(v.SMLTs, nil)
+	cmds = append(cmds, "no virtual-ist peer-ip")
+	var isis []string
+	if v.PeerSystemID != "" {
+		isis = append(isis, "no spbm 1 smlt-peer-system-id")
+	}
+	if v.VirtualBMAC != "" {
+		isis = append(isis, "no spbm 1 smlt-virt-bmac")
+	}
+	if len(isis) > 0 {
+		cmds = append(append(append(cmds, "router isis"), isis...), "exit")
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// smltSPBMCommand returns the command setting the SMLT setting of SPBM
+// instance 1 to value, or removing it if value is empty.
+func smltSPBMCommand(setting, value string) string {
+	if value == "" {
+		return "no spbm 1 " + setting
+	}
+	return fmt.Sprintf("spbm 1 %s %s", setting, value)
+}
+
+// smltCommands returns the commands removing "smlt" from the MLTs in old
+// but not in ids and adding it to the MLTs in ids but not in old.
+func smltCommands(old, ids []int32) []string {
+	existing := map[int32]bool{}
+	for _, id := range old {
+		existing[id] = true
+	}
+	wanted := map[int32]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var cmds []string
+	for _, id := range old {
+		if !wanted[id] {
+			cmds = append(cmds, fmt.Sprintf("interface mlt %d", id), "no smlt", "exit")
+		}
+	}
+	for _, id := range ids {
+		if !existing[id] {
+			cmds = append(cmds, fmt.Sprintf("interface mlt %d", id), "smlt", "exit")
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,71 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVIST(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+	srv.Exec("configure terminal",
+		"vlan create 4000 type port-mstprstp 0", "interface vlan 4000", "ip address 10.255.0.1/30", "exit",
+		"mlt 1", "mlt 2", "spbm", "router isis", "spbm 1", "exit", "end")
+
+	if v, err := c.VIST(ctx); err != nil || v != nil {
+		t.Fatalf("got %v, %v without a virtual IST", v, err)
+	}
+
+	want := VIST{
+		PeerIP:       "10.255.0.2",
+		VLAN:         4000,
+		PeerSystemID: "0200.0000.0002",
+		VirtualBMAC:  "00:00:be:ef:00:01",
+		SMLTs:        []int32{1, 2},
+	}
+	if err := c.ApplyVIST(ctx, nil, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.VIST(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+	cfg := srv.RunningConfig()
+	for _, line := range []string{"virtual-ist peer-ip 10.255.0.2 vlan 4000\n", "spbm 1 smlt-virt-bmac 00:00:be:ef:00:01\n"} {
+		if !strings.Contains(cfg, line) {
+			t.Errorf("missing %q in:\n%s", line, cfg)
+		}
+	}
+
+	// Only the MLT that changed is reconfigured.
+	updated := want
+	updated.VirtualBMAC = "00:00:be:ef:00:02"
+	updated.SMLTs = []int32{2}
+	sent := len(srv.Commands())
+	if err := c.ApplyVIST(ctx, &want, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.VIST(ctx); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+	for _, cmd := range srv.Commands()[sent:] {
+		if cmd == "interface mlt 2" || strings.HasPrefix(cmd, "virtual-ist") {
+			t.Errorf("unchanged setting reconfigured: %q", cmd)
+		}
+	}
+
+	if err := c.DeleteVIST(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.VIST(ctx); err != nil || v != nil {
+		t.Errorf("got %v, %v after deleting the virtual IST", v, err)
+	}
+	if cfg := srv.RunningConfig(); strings.Contains(cfg, "smlt") {
+		t.Errorf("SMLT configuration left:\n%s", cfg)
+	}
+}
@@ -0,0 +1,306 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// VLAN types accepted by "vlan create".
+const (
+	VLANTypePort  = "port-mstprstp"
+	VLANTypeBVLAN = "spbm-bvlan"
+)
+
+// VLAN is a VLAN as configured on the device.
+type VLAN struct {
+	ID   int32
+	Name string
+	// Type is VLANTypePort or VLANTypeBVLAN.
+	Type string
+	// STG is the spanning tree group of a port-based VLAN.
+	STG int32
+	// ISID is the I-SID the VLAN is mapped to, 0 if none.
+	ISID int32
+	// TaggedPorts and UntaggedPorts are the member ports, split by the
+	// encapsulation configured on each of them.
+	TaggedPorts   []string
+	UntaggedPorts []string
+}
+
+var (
+	// vlanBasicRe matches a row of "show vlan basic". Names may contain
+	// spaces, so the name is delimited by the type column.
+	vlanBasicRe = regexp.MustCompile(`(?m)^(\d+)[ \t]+(.*?)[ \t]+(byPort|spbm-bvlan|\S+)[ \t]+(\d+)(?:[ \t]+\S+){4}[ \t]*$`)
+	// vlanISIDRe matches a row of "show vlan i-sid".
+	vlanISIDRe = regexp.MustCompile(`(?m)^(\d+)[ \t]+(\d+)?[ \t]*$`)
+	// vlanMembersRe matches a row of "show vlan members", capturing the port
+	// member column.
+	vlanMembersRe = regexp.MustCompile(`(?m)^(\d+)(?:[ \t]+(\S+))?.*$`)
+	// portVLANsRe matches a row of "show interfaces gigabitEthernet vlan",
+	// capturing the tagging and VLANIDS columns.
+	portVLANsRe = regexp.MustCompile(`(?m)^(\d+/\d+(?:/\d+)?)[ \t]+(\S+)[ \t]+\S+(?:[ \t]+(\S+))?[ \t]*$`)
+)
+
+// DefaultVLAN is the VLAN every port belongs to until it is removed from it.
+const DefaultVLAN = 1
+
+// quote returns name between double quotes, as the CLI expects names that
+// contain spaces. The CLI has no escape sequence, so name must not contain
+// double quotes.
+func quote(name string) string {
+	return `"` + name + `"`
+}
+
+// portVLANs is the tagging and the VLAN membership of a port.
+type portVLANs struct {
+	// tagged reports whether "encapsulation dot1q" is set.
+	tagged bool
+	vlans  []int32
+}
+
+// other returns the first VLAN of p other than id and the default VLAN, 0 if
+// there is none.
+func (p portVLANs) other(id int32) int32 {
+	for _, v := range p.vlans {
+		if v != id && v != DefaultVLAN {
+			return v
+		}
+	}
+	return 0
+}
+
+// VLAN reads the VLAN with the given ID. It returns nil if the VLAN does not
+// exist.
+func (c *Client) VLAN(ctx context.Context, id int32) (*VLAN, error) {
+	outputs, err := c.Run(ctx, "show vlan basic", "show vlan i-sid", "show vlan members")
+	if err != nil {
+		return nil, err
+	}
+
+	key := strconv.Itoa(int(id))
+	var v *VLAN
+	for _, m := range vlanBasicRe.FindAllStringSubmatch(outputs[0], -1) {
+		if m[1] != key {
+			continue
+		}
+		stg, _ := strconv.Atoi(m[4])
+		v = &VLAN{ID: id, Name: m[2], Type: VLANTypePort, STG: int32(stg)}
+		if m[3] == VLANTypeBVLAN {
+			v.Type, v.STG = VLANTypeBVLAN, 0
+		}
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	for _, m := range vlanISIDRe.FindAllStringSubmatch(outputs[1], -1) {
+		if m[1] == key && m[2] != "" {
+			isid, _ := strconv.Atoi(m[2])
+			v.ISID = int32(isid)
+		}
+	}
+
+	var members []string
+	for _, m := range vlanMembersRe.FindAllStringSubmatch(outputs[2], -1) {
+		if m[1] == key {
+			if members, err = ParsePorts(m[2]); err != nil {
+				return nil, fmt.Errorf("parsing members of VLAN %d: %w", id, err)
+			}
+		}
+	}
+	if len(members) == 0 {
+		return v, nil
+	}
+
+	ports, err := c.portVLANs(ctx, members)
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range members {
+		if ports[port].tagged {
+			v.TaggedPorts = append(v.TaggedPorts, port)
+		} else {
+			v.UntaggedPorts = append(v.UntaggedPorts, port)
+		}
+	}
+	return v, nil
+}
+
+// portVLANs reads the tagging and the VLANs of ports from
+// "show interfaces gigabitEthernet vlan".
+func (c *Client) portVLANs(ctx context.Context, ports []string) (map[string]portVLANs, error) {
+	outputs, err := c.Run(ctx, "show interfaces gigabitEthernet vlan "+FormatPorts(ports))
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]portVLANs{}
+	for _, m := range portVLANsRe.FindAllStringSubmatch(outputs[0], -1) {
+		p := portVLANs{tagged: m[2] == "enable"}
+		if m[3] != "" {
+			if p.vlans, err = parseVLANList(m[3]); err != nil {
+				return nil, fmt.Errorf("parsing the VLANs of port %s: %w", m[1], err)
+			}
+		}
+		result[m[1]] = p
+	}
+	return result, nil
+}
+
+// CreateVLAN creates v and adds its member ports.
+func (c *Client) CreateVLAN(ctx context.Context, v VLAN) error {
+	members, err := c.memberCommands(ctx, VLAN{ID: v.ID}, v)
+	if err != nil {
+		return err
+	}
+
+	create := fmt.Sprintf("vlan create %d", v.ID)
+	if v.Name != "" {
+		create += " name " + quote(v.Name)
+	}
+	if v.Type == VLANTypeBVLAN {
+		create += " type " + VLANTypeBVLAN
+	} else {
+		create += fmt.Sprintf(" type %s %d", VLANTypePort, v.STG)
+	}
+
+	cmds := []string{create}
+	if v.ISID != 0 {
+		cmds = append(cmds, fmt.Sprintf("vlan i-sid %d %d", v.ID, v.ISID))
+	}
+	cmds = append(cmds, members...)
+	return c.Configure(ctx, cmds...)
+}
+
+// UpdateVLAN changes the name, I-SID and member ports of the VLAN from old to
+// v. The type and STG of a VLAN cannot be changed.
+func (c *Client) UpdateVLAN(ctx context.Context, old, v VLAN) error {
+	members, err := c.memberCommands(ctx, old, v)
+	if err != nil {
+		return err
+	}
+
+	var cmds []string
+	if v.Name != old.Name && v.Name != "" {
+		cmds = append(cmds, fmt.Sprintf("vlan name %d %s", v.ID, quote(v.Name)))
+	}
+	if v.ISID != old.ISID {
+		if old.ISID != 0 {
+			cmds = append(cmds, fmt.Sprintf("no vlan i-sid %d", v.ID))
+		}
+		if v.ISID != 0 {
+			cmds = append(cmds, fmt.Sprintf("vlan i-sid %d %d", v.ID, v.ISID))
+		}
+	}
+	cmds = append(cmds, members...)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// DeleteVLAN deletes the VLAN with the given ID. Its member ports are removed
+// first so that the tagging of the ports that were only members of this VLAN
+// is reverted.
+func (c *Client) DeleteVLAN(ctx context.Context, id int32) error {
+	v, err := c.VLAN(ctx, id)
+	if err != nil {
+		return err
+	}
+	var cmds []string
+	if v != nil {
+		if cmds, err = c.memberCommands(ctx, *v, VLAN{ID: id}); err != nil {
+			return err
+		}
+	}
+	return c.Configure(ctx, append(cmds, fmt.Sprintf("vlan delete %d", id))...)
+}
+
+// memberCommands reads the VLANs of the ports whose membership or tagging
+// changes, and returns the commands changing the member ports of the VLAN
+// from those of old to those of v.
+func (c *Client) memberCommands(ctx context.Context, old, v VLAN) ([]string, error) {
+	changed := portSet(old.TaggedPorts, old.UntaggedPorts, v.TaggedPorts, v.UntaggedPorts)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	var names []string
+	for port := range changed {
+		names = append(names, port)
+	}
+	ports, err := c.portVLANs(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	return vlanMemberCommands(old, v, ports)
+}
+
+// vlanMemberCommands returns the commands changing the member ports of the
+// VLAN from those of old to those of v, given the current VLANs of the ports.
+// Tagging is a port setting on Fabric Engine, so it is changed before the
+// ports join the VLAN, and reverted once a tagged port leaves its last VLAN
+// besides the default one. Changing the tagging of a port that is also a
+// member of another VLAN is refused, since it would change that VLAN too.
+func vlanMemberCommands(old, v VLAN, ports map[string]portVLANs) ([]string, error) {
+	oldMembers := portSet(old.TaggedPorts, old.UntaggedPorts)
+	newMembers := portSet(v.TaggedPorts, v.UntaggedPorts)
+	oldTagged := portSet(old.TaggedPorts)
+
+	var removed, added, tag, untag []string
+	for port := range oldMembers {
+		if !newMembers[port] {
+			removed = append(removed, port)
+			if p := ports[port]; p.tagged && p.other(v.ID) == 0 {
+				untag = append(untag, port)
+			}
+		}
+	}
+	for _, port := range v.TaggedPorts {
+		if p := ports[port]; !oldTagged[port] && !p.tagged {
+			if other := p.other(v.ID); other != 0 {
+				return nil, fmt.Errorf("port %s cannot be a tagged member of VLAN %d, it is an untagged member of VLAN %d", port, v.ID, other)
+			}
+			tag = append(tag, port)
+		}
+	}
+	for _, port := range v.UntaggedPorts {
+		if p := ports[port]; !oldMembers[port] || oldTagged[port] || p.tagged {
+			if other := p.other(v.ID); other != 0 && p.tagged {
+				return nil, fmt.Errorf("port %s cannot be an untagged member of VLAN %d, it is a tagged member of VLAN %d", port, v.ID, other)
+			}
+			untag = append(untag, port)
+		}
+	}
+	for port := range newMembers {
+		if !oldMembers[port] {
+			added = append(added, port)
+		}
+	}
+
+	var cmds []string
+	if len(removed) > 0 {
+		cmds = append(cmds, fmt.Sprintf("vlan members remove %d %s", v.ID, FormatPorts(removed)))
+	}
+	if len(tag) > 0 {
+		cmds = append(cmds, "interface gigabitEthernet "+FormatPorts(tag), "encapsulation dot1q", "exit")
+	}
+	if len(untag) > 0 {
+		cmds = append(cmds, "interface gigabitEthernet "+FormatPorts(untag), "no encapsulation dot1q", "exit")
+	}
+	if len(added) > 0 {
+		cmds = append(cmds, fmt.Sprintf("vlan members add %d %s", v.ID, FormatPorts(added)))
+	}
+	return cmds, nil
+}
+
+// portSet returns the union of the given port lists.
+func portSet(lists ...[]string) map[string]bool {
+	set := map[string]bool{}
+	for _, list := range lists {
+		for _, port := range list {
+			set[port] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,154 @@
+package transport
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestVLAN(t *testing.T) {
+	_, c := newMockClient(t)
+	ctx := context.Background()
+
+	if v, err := c.VLAN(ctx, 10); err != nil || v != nil {
+		t.Fatalf("got %v, %v for a missing VLAN", v, err)
+	}
+
+	want := VLAN{
+		ID:            10,
+		Name:          "Data VLAN",
+		Type:          VLANTypePort,
+		STG:           0,
+		ISID:          10010,
+		TaggedPorts:   []string{"1/1", "1/2"},
+		UntaggedPorts: []string{"1/5"},
+	}
+	if err := c.CreateVLAN(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.VLAN(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	updated := VLAN{
+		ID:            10,
+		Name:          "Voice",
+		Type:          VLANTypePort,
+		ISID:          10020,
+		TaggedPorts:   []string{"1/2", "1/5"},
+		UntaggedPorts: []string{"2/1"},
+	}
+	if err := c.UpdateVLAN(ctx, want, updated); err != nil {
+		t.Fatal(err)
+	}
+	got, err = c.VLAN(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+
+	if err := c.DeleteVLAN(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.VLAN(ctx, 10); v != nil {
+		t.Errorf("VLAN still exists after delete: %+v", v)
+	}
+}
+
+func TestBVLAN(t *testing.T) {
+	_, c := newMockClient(t)
+	ctx := context.Background()
+
+	want := VLAN{ID: 4051, Name: "BVLAN-1", Type: VLANTypeBVLAN}
+	if err := c.CreateVLAN(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.VLAN(ctx, 4051)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestVLANMemberCommands(t *testing.T) {
+	old := VLAN{ID: 10, TaggedPorts: []string{"1/1", "1/2"}, UntaggedPorts: []string{"1/3"}}
+	v := VLAN{ID: 10, TaggedPorts: []string{"1/2", "1/3"}, UntaggedPorts: []string{"1/4"}}
+	ports := map[string]portVLANs{
+		"1/1": {tagged: true, vlans: []int32{10}},
+		"1/2": {tagged: true, vlans: []int32{10}},
+		"1/3": {vlans: []int32{10}},
+		"1/4": {vlans: []int32{1}},
+	}
+
+	got, err := vlanMemberCommands(old, v, ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"vlan members remove 10 1/1",
+		"interface gigabitEthernet 1/3", "encapsulation dot1q", "exit",
+		"interface gigabitEthernet 1/1,1/4", "no encapsulation dot1q", "exit",
+		"vlan members add 10 1/4",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// 1/1 stays tagged for VLAN 20.
+	ports["1/1"] = portVLANs{tagged: true, vlans: []int32{10, 20}}
+	got, err = vlanMemberCommands(old, v, ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want[4] = "interface gigabitEthernet 1/4"; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestVLANConflictingTagging(t *testing.T) {
+	_, c := newMockClient(t)
+	ctx := context.Background()
+
+	if err := c.CreateVLAN(ctx, VLAN{ID: 10, Type: VLANTypePort, TaggedPorts: []string{"1/1"}, UntaggedPorts: []string{"1/2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CreateVLAN(ctx, VLAN{ID: 20, Type: VLANTypePort, UntaggedPorts: []string{"1/1"}}); err == nil {
+		t.Error("untagged member of VLAN 20 accepted while tagged for VLAN 10")
+	}
+	if err := c.CreateVLAN(ctx, VLAN{ID: 20, Type: VLANTypePort, TaggedPorts: []string{"1/2"}}); err == nil {
+		t.Error("tagged member of VLAN 20 accepted while untagged for VLAN 10")
+	}
+	if v, _ := c.VLAN(ctx, 20); v != nil {
+		t.Errorf("VLAN 20 created: %+v", v)
+	}
+
+	// A port may be a tagged member of several VLANs, and keeps its tagging
+	// until it leaves the last one.
+	if err := c.CreateVLAN(ctx, VLAN{ID: 20, Type: VLANTypePort, TaggedPorts: []string{"1/1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteVLAN(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.VLAN(ctx, 20); v == nil || !reflect.DeepEqual(v.TaggedPorts, []string{"1/1"}) {
+		t.Errorf("got %+v, want 1/1 tagged", v)
+	}
+	if err := c.DeleteVLAN(ctx, 20); err != nil {
+		t.Fatal(err)
+	}
+	ports, err := c.portVLANs(ctx, []string{"1/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ports["1/1"].tagged {
+		t.Error("1/1 still tagged after its last VLAN was deleted")
+	}
+}
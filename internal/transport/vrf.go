@@ -0,0 +1,164 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// VRF is a VRF created with "ip vrf" and the L3 VSN configured in its
+// "router vrf" context.
+type VRF struct {
+	Name string
+	ID   int32
+	// ISID is the I-SID of the L3 VSN, 0 if IP VPN is not configured.
+	ISID int32
+	// IPVPNEnabled reports whether "ipvpn enable" is set.
+	IPVPNEnabled bool
+	// RedistributeDirect and RedistributeStatic report whether the direct
+	// and static routes of the VRF are redistributed into IS-IS.
+	RedistributeDirect bool
+	RedistributeStatic bool
+	// Interfaces is the number of VLAN, brouter and loopback interfaces
+	// attached to the VRF. It is only read.
+	Interfaces int32
+}
+
+var (
+	// vrfRe matches a row of "show ip vrf".
+	vrfRe = regexp.MustCompile(`(?m)^(\S+)[ \t]+(\d+)[ \t]+\S+[ \t]+(\d+)[ \t]+(\d+)[ \t]+(\d+)[ \t]*$`)
+	// ipvpnRe matches the block of a VRF in "show ip ipvpn".
+	ipvpnRe = regexp.MustCompile(`VRF Name[ \t]*:[ \t]*(\S+)\s+=+\s+Ipv4 Ipvpn-state[ \t]*:[ \t]*(\S+)[\s\S]*?I-sid[ \t]*:[ \t]*(\d+)`)
+	// redistributeRe matches a row of "show ip isis redistribute".
+	redistributeRe = regexp.MustCompile(`(?m)^(DIRECT|STATIC)[ \t]+.*?(TRUE|FALSE)[ \t]*$`)
+	// vrfNotFoundRe matches the error of a "show" command naming a VRF that
+	// does not exist.
+	vrfNotFoundRe = regexp.MustCompile(`^Error: VRF \S+ does not exist`)
+)
+
+// vrfNotFound reports whether err is the device rejecting a command because
+// the VRF it names does not exist.
+func vrfNotFound(err error) bool {
+	var cmdErr *CommandError
+	return errors.As(err, &cmdErr) && vrfNotFoundRe.MatchString(cmdErr.Message)
+}
+
+// VRF reads the VRF from "show ip vrf", "show ip ipvpn" and
+// "show ip isis redistribute vrf". It returns nil if the VRF does not exist.
+func (c *Client) VRF(ctx context.Context, name string) (*VRF, error) {
+	outputs, err := c.Run(ctx, "show ip vrf", "show ip ipvpn")
+	if err != nil {
+		return nil, err
+	}
+
+	var v *VRF
+	for _, m := range vrfRe.FindAllStringSubmatch(outputs[0], -1) {
+		if m[1] != name {
+			continue
+		}
+		id, _ := strconv.Atoi(m[2])
+		var interfaces int32
+		for _, count := range m[3:] {
+			n, _ := strconv.Atoi(count)
+			interfaces += int32(n)
+		}
+		v = &VRF{Name: name, ID: int32(id), Interfaces: interfaces}
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	for _, m := range ipvpnRe.FindAllStringSubmatch(outputs[1], -1) {
+		if m[1] == name {
+			isid, _ := strconv.Atoi(m[3])
+			v.ISID, v.IPVPNEnabled = int32(isid), m[2] == "enabled"
+		}
+	}
+
+	out, err := c.Run(ctx, "show ip isis redistribute vrf "+name)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range redistributeRe.FindAllStringSubmatch(out[0], -1) {
+		switch m[1] {
+		case "DIRECT":
+			v.RedistributeDirect = m[2] == "TRUE"
+		case "STATIC":
+			v.RedistributeStatic = m[2] == "TRUE"
+		}
+	}
+	return v, nil
+}
+
+// CreateVRF creates the VRF and its L3 VSN.
+func (c *Client) CreateVRF(ctx context.Context, v VRF) error {
+	cmds := []string{fmt.Sprintf("ip vrf %s vrfid %d", v.Name, v.ID)}
+	return c.Configure(ctx, append(cmds, vrfCommands(VRF{Name: v.Name, ID: v.ID}, v)...)...)
+}
+
+// UpdateVRF changes the L3 VSN of the VRF from old to v. The name and ID of
+// a VRF cannot be changed.
+func (c *Client) UpdateVRF(ctx context.Context, old, v VRF) error {
+	cmds := vrfCommands(old, v)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return c.Configure(ctx, cmds...)
+}
+
+// DeleteVRF deletes the VRF and its L3 VSN. The device refuses to delete a
+// VRF with interfaces.
+func (c *Client) DeleteVRF(ctx context.Context, name string) error {
+	return c.Configure(ctx, "no ip vrf "+name)
+}
+
+// vrfCommands returns the commands changing the L3 VSN of the VRF from old to
+// v. The I-SID can only be changed while IP VPN is disabled, and changes to
+// the redistribution are applied with "isis apply redistribute".
+func vrfCommands(old, v VRF) []string {
+	var router []string
+	oldEnabled := old.ISID != 0 && old.IPVPNEnabled
+	enabled := v.ISID != 0 && v.IPVPNEnabled
+	if oldEnabled && (!enabled || v.ISID != old.ISID) {
+		router = append(router, "no ipvpn enable")
+		oldEnabled = false
+	}
+	switch {
+	case v.ISID == 0 && old.ISID != 0:
+		router = append(router, "no ipvpn")
+	case v.ISID != 0 && old.ISID == 0:
+		router = append(router, "ipvpn", fmt.Sprintf("i-sid %d", v.ISID))
+	case v.ISID != old.ISID:
+		router = append(router, fmt.Sprintf("i-sid %d", v.ISID))
+	}
+	if enabled && !oldEnabled {
+		router = append(router, "ipvpn enable")
+	}
+
+	var apply []string
+	for _, r := range []struct {
+		source   string
+		old, new bool
+	}{
+		{"direct", old.RedistributeDirect, v.RedistributeDirect},
+		{"static", old.RedistributeStatic, v.RedistributeStatic},
+	} {
+		switch {
+		case r.new && !r.old:
+			router = append(router, "isis redistribute "+r.source, "isis redistribute "+r.source+" enable")
+		case !r.new && r.old:
+			router = append(router, "no isis redistribute "+r.source)
+		default:
+			continue
+		}
+		apply = append(apply, fmt.Sprintf("isis apply redistribute %s vrf %s", r.source, v.Name))
+	}
+
+	if len(router) == 0 {
+		return nil
+	}
+	cmds := append([]string{"router vrf " + v.Name}, router...)
+	return append(append(cmds, "exit"), apply...)
+}
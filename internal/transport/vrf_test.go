@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVRF(t *testing.T) {
+	srv, c := newMockClient(t)
+	ctx := context.Background()
+
+	if v, err := c.VRF(ctx, "red"); err != nil || v != nil {
+		t.Fatalf("got %v, %v for a missing VRF", v, err)
+	}
+
+	want := VRF{Name: "red", ID: 1, ISID: 30001, IPVPNEnabled: true, RedistributeDirect: true}
+	if err := c.CreateVRF(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.VRF(ctx, "red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+	if !strings.Contains(srv.RunningConfig(), "isis redistribute direct enable") {
+		t.Errorf("direct routes are not redistributed:\n%s", srv.RunningConfig())
+	}
+
+	// Changing the I-SID disables IP VPN while it is set.
+	updated := VRF{Name: "red", ID: 1, ISID: 30002, IPVPNEnabled: true, RedistributeStatic: true}
+	if err := c.UpdateVRF(ctx, want, updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.VRF(ctx, "red"); !reflect.DeepEqual(*got, updated) {
+		t.Errorf("got %+v, want %+v", *got, updated)
+	}
+
+	plain := VRF{Name: "red", ID: 1}
+	if err := c.UpdateVRF(ctx, updated, plain); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.VRF(ctx, "red"); !reflect.DeepEqual(*got, plain) {
+		t.Errorf("got %+v after removing the L3 VSN, want %+v", *got, plain)
+	}
+
+	srv.Exec("configure terminal", "vlan create 10 type port-mstprstp 0", "interface vlan 10", "vrf red", "exit")
+	if got, _ := c.VRF(ctx, "red"); got.Interfaces != 1 {
+		t.Errorf("got %d interfaces, want 1", got.Interfaces)
+	}
+	var cmdErr *CommandError
+	if err := c.DeleteVRF(ctx, "red"); !errors.As(err, &cmdErr) {
+		t.Errorf("deleting a VRF with interfaces returned %v", err)
+	}
+
+	srv.Exec("configure terminal", "interface vlan 10", "no vrf", "exit")
+	if err := c.DeleteVRF(ctx, "red"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.VRF(ctx, "red"); v != nil {
+		t.Errorf("VRF still exists after delete: %+v", v)
+	}
+}
+
+func TestVRFCommands(t *testing.T) {
+	old := VRF{Name: "red", ID: 1, ISID: 30001, IPVPNEnabled: true}
+	if cmds := vrfCommands(old, old); cmds != nil {
+		t.Errorf("got %q for an unchanged VRF", cmds)
+	}
+
+	v := old
+	v.RedistributeStatic = true
+	want := []string{"router vrf red", "isis redistribute static", "isis redistribute static enable", "exit",
+		"isis apply redistribute static vrf red"}
+	if cmds := vrfCommands(old, v); !reflect.DeepEqual(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+}
@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tchevalleraud/extrm-fabric-engine/internal/transport"
+)
+
+var _ validator.String = stringOneOfValidator{}
+
+// stringOneOfValidator checks that a string attribute is one of a fixed set
+// of values.
+type stringOneOfValidator struct {
+	values []string
+}
+
+// stringOneOf returns a validator accepting only the given values.
+func stringOneOf(values ...string) validator.String {
+	return stringOneOfValidator{values: values}
+}
+
+func (v stringOneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", v.quoted())
+}
+
+func (v stringOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringOneOfValidator) ValidateString(
+	ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	value := req.ConfigValue.ValueString()
+	for _, allowed := range v.values {
+		if value == allowed {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(req.Path, "Invalid attribute value",
+		fmt.Sprintf("Value must be one of %s, got %q", v.quoted(), value))
+}
+
+func (v stringOneOfValidator) quoted() string {
+	quoted := make([]string, len(v.values))
+	for i, value := range v.values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+var _ validator.Int32 = int32BetweenValidator{}
+
+// int32BetweenValidator checks that an int32 attribute is within a range.
+type int32BetweenValidator struct {
+	min, max int32
+}
+
+// int32Between returns a validator accepting values from min to max
+// inclusive.
+func int32Between(min, max int32) validator.Int32 {
+	return int32BetweenValidator{min: min, max: max}
+}
+
+func (v int32BetweenValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be between %d and %d", v.min, v.max)
+}
+
+func (v int32BetweenValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v int32BetweenValidator) ValidateInt32(
+	ctx context.Context, req validator.Int32Request, resp *validator.Int32Response) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	value := req.ConfigValue.ValueInt32()
+	if value < v.min || value > v.max {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid attribute value",
+			fmt.Sprintf("Value must be between %d and %d, got %d", v.min, v.max, value))
+	}
+}
+
+var _ validator.Set = portSetValidator{}
+
+// portSetValidator checks that every element of a set of strings is a port
+// number.
+type portSetValidator struct{}
+
+func (v portSetValidator) Description(ctx context.Context) string {
+	return `elements must be port numbers such as "1/1" or "1/49/2"`
+}
+
+func (v portSetValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v portSetValidator) ValidateSet(
+	ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	for _, elem := range req.ConfigValue.Elements() {
+		port, ok := elem.(types.String)
+		if !ok || port.IsUnknown() || port.IsNull() {
+			continue
+		}
+		validatePort(req.Path, port.ValueString(), &resp.Diagnostics)
+	}
+}
+
+var _ validator.String = portValidator{}
+
+// portValidator checks that a string attribute is a port number.
+type portValidator struct{}
+
+func (v portValidator) Description(ctx context.Context) string {
+	return `value must be a port number such as "1/1" or "1/49/2"`
+}
+
+func (v portValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v portValidator) ValidateString(
+	ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	validatePort(req.Path, req.ConfigValue.ValueString(), &resp.Diagnostics)
+}
+
+// validatePort adds an error to diags if port is not a port number.
+func validatePort(p path.Path, port string, diags *diag.Diagnostics) {
+	if !transport.ValidPort(port) {
+		diags.AddAttributeError(p, "Invalid port",
+			fmt.Sprintf("%q is not a port number, expected <slot>/<port> or <slot>/<port>/<sub-port>", port))
+	}
+}
+
+var _ validator.String = stringMatchesValidator{}
+
+// stringMatchesValidator checks that a string attribute matches a regular
+// expression.
+type stringMatchesValidator struct {
+	re     *regexp.Regexp
+	format string
+}
+
+// stringMatches returns a validator accepting the values matching re. format
+// describes the expected values in error messages, e.g. "xxxx.xxxx.xxxx".
+func stringMatches(re *regexp.Regexp, format string) validator.String {
+	return stringMatchesValidator{re: re, format: format}
+}
+
+func (v stringMatchesValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be formatted as %s", v.format)
+}
+
+func (v stringMatchesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringMatchesValidator) ValidateString(
+	ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if value := req.ConfigValue.ValueString(); !v.re.MatchString(value) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid attribute value",
+			fmt.Sprintf("Value must be formatted as %s, got %q", v.format, value))
+	}
+}
+
+var _ validator.String = addressValidator{}
+var _ validator.Set = addressValidator{}
+
+// addressValidator checks that a string attribute, or every element of a set
+// of strings, is an interface address with its prefix length such as
+// "10.0.0.1/24" or "2001:db8::1/64", written the way the device shows it.
+// With network set, the value must be a network address such as
+// "10.0.0.0/24" instead.
+type addressValidator struct {
+	ipv6    bool
+	network bool
+}
+
+func (v addressValidator) format() string {
+	switch {
+	case v.network:
+		return `an IPv4 network with its prefix length such as "10.0.0.0/24"`
+	case v.ipv6:
+		return `an IPv6 address with its prefix length such as "2001:db8::1/64"`
+	}
+	return `an IPv4 address with its prefix length such as "10.0.0.1/24"`
+}
+
+func (v addressValidator) Description(ctx context.Context) string {
+	return "value must be " + v.format()
+}
+
+func (v addressValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v addressValidator) ValidateString(
+	ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+
+	v.validate(req.Path, req.ConfigValue, &resp.Diagnostics)
+}
+
+func (v addressValidator) ValidateSet(
+	ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	for _, elem := range req.ConfigValue.Elements() {
+		if address, ok := elem.(types.String); ok {
+			v.validate(req.Path, address, &resp.Diagnostics)
+		}
+	}
+}
+
+func (v addressValidator) validate(p path.Path, value types.String, diags *diag.Diagnostics) {
+	if value.IsNull() || value.IsUnknown() {
+		return
+	}
+	s := value.ValueString()
+	prefix, err := netip.ParsePrefix(s)
+	switch {
+	case err != nil || prefix.Addr().Is6() != v.ipv6 || prefix.Addr().Is4In6():
+		diags.AddAttributeError(p, "Invalid address", fmt.Sprintf("%q is not %s", s, v.format()))
+	case v.network && prefix.Masked() != prefix:
+		diags.AddAttributeError(p, "Invalid address",
+			fmt.Sprintf("%q is not a network address, did you mean %q?", s, prefix.Masked().String()))
+	case prefix.String() != s:
+		diags.AddAttributeError(p, "Invalid address",
+			fmt.Sprintf("%q must be written %q, the way the device shows it", s, prefix.String()))
+	}
+}
@@ -20,6 +20,9 @@ func main() {
 
 	err := providerserver.Serve(context.Background(), provider.New(version), opts)
 
+	// Close the SSH sessions shared by the resources before exiting.
+	provider.Shutdown()
+
 	if err != nil {
 		log.Fatal(err.Error())
 	}